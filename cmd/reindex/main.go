@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	entsql "entgo.io/ent/dialect/sql"
+
+	"github.com/gmhafiz/go8/config"
+	"github.com/gmhafiz/go8/ent/gen"
+	authorRepo "github.com/gmhafiz/go8/internal/domain/author/repository"
+	bookRepo "github.com/gmhafiz/go8/internal/domain/book/repository"
+	db "github.com/gmhafiz/go8/third_party/database"
+	"github.com/gmhafiz/go8/third_party/elasticsearch"
+)
+
+// Writes every book and author to Elasticsearch. Run it once after turning
+// SEARCH_BACKEND=elasticsearch on, and whenever the indices fell behind
+// because Elasticsearch was unavailable.
+func main() {
+	recreate := flag.Bool("recreate", false, "delete the indices first to drop stale documents and apply mapping changes")
+	batchSize := flag.Int("batch", 500, "number of documents written per bulk request")
+	flag.Parse()
+
+	cfg := config.New()
+	store := db.NewSqlx(cfg.Database)
+	client := gen.NewClient(gen.Driver(entsql.OpenDB(cfg.Database.Driver, store.DB)))
+	es := elasticsearch.New(cfg.Elasticsearch)
+
+	ctx := context.Background()
+
+	books, err := bookRepo.Reindex(ctx, store, es, cfg.Search.Language, *batchSize, *recreate)
+	if err != nil {
+		log.Fatalf("reindexing books after %d: %v", books, err)
+	}
+	log.Printf("indexed %d books\n", books)
+
+	authors, err := authorRepo.Reindex(ctx, client, es, *batchSize, *recreate)
+	if err != nil {
+		log.Fatalf("reindexing authors after %d: %v", authors, err)
+	}
+	log.Printf("indexed %d authors\n", authors)
+}
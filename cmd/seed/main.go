@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/gmhafiz/go8/config"
 	"github.com/gmhafiz/go8/database"
+	"github.com/gmhafiz/go8/internal/utility/password"
 	db "github.com/gmhafiz/go8/third_party/database"
 )
 
@@ -11,7 +12,7 @@ func main() {
 	cfg := config.New()
 	store := db.NewSqlx(cfg.Database)
 
-	seeder := database.Seeder(store.DB)
+	seeder := database.Seeder(store.DB, password.Params(cfg.Authentication))
 	seeder.SeedUsers()
 	fmt.Println("seeding completed.")
 }
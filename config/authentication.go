@@ -0,0 +1,83 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Authentication struct {
+	// PasswordMemory (in KiB), PasswordIterations and PasswordParallelism are
+	// argon2id parameters for new password hashes. Existing hashes made with
+	// a lower memory or iteration count are upgraded on next login.
+	PasswordMemory      uint32 `split_words:"true" default:"65536"`
+	PasswordIterations  uint32 `split_words:"true" default:"1"`
+	PasswordParallelism uint8  `split_words:"true" default:"2"`
+
+	// PasswordMinLength and PasswordMaxLength bound new passwords, counted
+	// in characters.
+	PasswordMinLength int `split_words:"true" default:"13"`
+	PasswordMaxLength int `split_words:"true" default:"128"`
+	// PasswordBannedFile lists passwords that are never accepted, one per
+	// line, on top of a built-in list of common passwords.
+	PasswordBannedFile string `split_words:"true"`
+	// PasswordRejectPersonal rejects passwords containing the user's email
+	// address or name.
+	PasswordRejectPersonal bool `split_words:"true" default:"true"`
+	// PasswordBreachedFile is a local copy of Have I Been Pwned SHA-1 hashes
+	// ordered by hash. Passwords found in it are rejected without calling
+	// any outside service. Leave empty to skip this check.
+	PasswordBreachedFile string `split_words:"true"`
+
+	// RequireVerification rejects login from users who have not verified
+	// their email address.
+	RequireVerification bool          `split_words:"true" default:"false"`
+	VerificationExpiry  time.Duration `split_words:"true" default:"24h"`
+	VerificationURL     string        `split_words:"true" default:"http://localhost:3080/api/v1/verify"`
+
+	PasswordResetExpiry time.Duration `split_words:"true" default:"1h"`
+	// PasswordResetURL is the page, usually in the frontend, where user
+	// enters a new password. Token is appended as a `token` query parameter.
+	PasswordResetURL string `split_words:"true" default:"http://localhost:3000/reset-password"`
+
+	// APIKeyExpiry is used when an API key is created without an expiry. Set
+	// to 0 to let such keys live until revoked.
+	APIKeyExpiry time.Duration `split_words:"true" default:"2160h"`
+
+	// TOTPIssuer is the name shown in authenticator apps.
+	TOTPIssuer string `split_words:"true" default:"go8"`
+	// TOTPEncryptionKey encrypts TOTP secrets at rest. It is a base64 encoded
+	// 32-byte key, generated with `openssl rand -base64 32`. Two-factor
+	// authentication cannot be enabled until it is set.
+	TOTPEncryptionKey string `split_words:"true"`
+	// TwoFactorPendingExpiry is how long users have to enter their code after
+	// a successful password check.
+	TwoFactorPendingExpiry time.Duration `split_words:"true" default:"5m"`
+
+	// AccountDeletionGrace is how long a deleted account is kept before it
+	// is removed for good. Logging in and restoring the account within this
+	// period cancels the deletion.
+	AccountDeletionGrace time.Duration `split_words:"true" default:"720h"`
+	// AccountPurgeInterval is how often accounts past their grace period are
+	// looked for.
+	AccountPurgeInterval time.Duration `split_words:"true" default:"1h"`
+
+	// LockoutThreshold is the number of failed logins to an account before
+	// it is locked for LockoutDuration. Failures before that are slowed down
+	// with an exponential backoff, starting from LockoutBackoff. Set to 0 to
+	// turn off login throttling.
+	LockoutThreshold      int           `split_words:"true" default:"10"`
+	LockoutThresholdPerIP int           `split_words:"true" default:"50"`
+	LockoutDuration       time.Duration `split_words:"true" default:"15m"`
+	LockoutBackoff        time.Duration `split_words:"true" default:"1s"`
+	LockoutBackoffMax     time.Duration `split_words:"true" default:"1m"`
+	// LockoutWindow is how long failures are remembered since the last one.
+	LockoutWindow time.Duration `split_words:"true" default:"15m"`
+}
+
+func NewAuthentication() Authentication {
+	var auth Authentication
+	envconfig.MustProcess("AUTH", &auth)
+
+	return auth
+}
@@ -13,9 +13,19 @@ type Config struct {
 	Database
 	Cache
 	Elasticsearch
+	Search
+	Pagination
+	Precondition
+	Proxy
 
 	OpenTelemetry
 	Session
+	Authentication
+	Mail
+	OIDC
+	CSRF
+	Token
+	Trash
 }
 
 func New() *Config {
@@ -25,12 +35,22 @@ func New() *Config {
 	}
 
 	return &Config{
-		API:           NewAPI(),
-		Cors:          NewCors(),
-		Database:      DataStore(),
-		Cache:         NewCache(),
-		Elasticsearch: ElasticSearch(),
-		Session:       NewSession(),
-		OpenTelemetry: NewOpenTelemetry(),
+		API:            NewAPI(),
+		Cors:           NewCors(),
+		Database:       DataStore(),
+		Cache:          NewCache(),
+		Elasticsearch:  ElasticSearch(),
+		Search:         NewSearch(),
+		Pagination:     NewPagination(),
+		Precondition:   NewPrecondition(),
+		Proxy:          NewProxy(),
+		Session:        NewSession(),
+		OpenTelemetry:  NewOpenTelemetry(),
+		Authentication: NewAuthentication(),
+		Mail:           NewMail(),
+		OIDC:           NewOIDC(),
+		CSRF:           NewCSRF(),
+		Token:          NewToken(),
+		Trash:          NewTrash(),
 	}
 }
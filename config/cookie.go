@@ -12,12 +12,33 @@ type Session struct {
 	Path     string          `default:"/"`
 	Domain   string          `default:""`
 	Secret   string          `required:"false"`
-	Duration time.Duration   `default:"24h"`
 	HTTPOnly bool            `split_words:"true" default:"true"`
 	Secure   bool            `default:"true"`
 	SameSite SameSiteDecoder `split_words:"true" default:"lax"`
+	// Duration is how long a session lasts from login, however active it
+	// is.
+	Duration time.Duration `default:"24h"`
+	// IdleTimeout ends a session that has not been used for this long. Every
+	// request pushes it forward, up to Duration. 0 turns it off.
+	IdleTimeout time.Duration `split_words:"true" default:"1h"`
+	// RememberMeDuration and RememberMeIdleTimeout replace Duration and
+	// IdleTimeout for users who log in with `remember_me`. Only their cookie
+	// outlives the browser.
+	RememberMeDuration    time.Duration `split_words:"true" default:"720h"`
+	RememberMeIdleTimeout time.Duration `split_words:"true" default:"168h"`
+	// RenewInterval is how often pushed forward expiries are written to the
+	// session store, in one batch.
+	RenewInterval time.Duration `split_words:"true" default:"1m"`
+	// Store is where sessions are kept, either `postgres` or `redis`. Redis
+	// requires REDIS_ENABLE.
+	Store string `default:"postgres"`
 }
 
+const (
+	SessionStorePostgres = "postgres"
+	SessionStoreRedis    = "redis"
+)
+
 func NewSession() Session {
 	var a Session
 	envconfig.MustProcess("SESSION", &a)
@@ -0,0 +1,30 @@
+package config
+
+import "github.com/kelseyhightower/envconfig"
+
+const (
+	// CSRFModeSession lets a token be reused until it expires.
+	CSRFModeSession = "session"
+	// CSRFModeSingleUse deletes a token once it has been checked.
+	CSRFModeSingleUse = "single_use"
+)
+
+// CSRF configures checking of CSRF tokens on requests that modify state and
+// are authenticated with a session cookie.
+type CSRF struct {
+	Enable bool   `default:"true"`
+	Mode   string `default:"session"`
+	// Header is checked first, then FormField for HTML form submissions.
+	Header    string `default:"X-CSRF-Token"`
+	FormField string `split_words:"true" default:"csrf_token"`
+	// Exempt lists paths that are not checked. A trailing `*` matches any
+	// path with that prefix, for example `/api/v1/webhooks/*`.
+	Exempt []string
+}
+
+func NewCSRF() CSRF {
+	var c CSRF
+	envconfig.MustProcess("CSRF", &c)
+
+	return c
+}
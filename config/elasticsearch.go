@@ -1,13 +1,19 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
+// Elasticsearch is only used when SEARCH_BACKEND is elasticsearch.
 type Elasticsearch struct {
 	Address  string `default:"http://localhost:9200"`
 	User     string
 	Password string
+	// Timeout bounds each request. Searches that time out are served from
+	// PostgreSQL instead.
+	Timeout time.Duration `default:"2s"`
 }
 
 func ElasticSearch() Elasticsearch {
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Mail struct {
+	// Driver is one of `smtp`, `file` or `log`.
+	Driver    string `default:"log"`
+	Host      string `default:"localhost"`
+	Port      int    `default:"1025"`
+	User      string
+	Pass      string
+	From      string `default:"no-reply@example.com"`
+	Directory string `default:"mail"`
+}
+
+func NewMail() Mail {
+	var mail Mail
+	envconfig.MustProcess("MAIL", &mail)
+
+	return mail
+}
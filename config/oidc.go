@@ -0,0 +1,35 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// OIDC configures login through an external OpenID Connect provider such as
+// Google, Keycloak or Auth0.
+type OIDC struct {
+	Enable bool `default:"false"`
+	// Issuer is the provider's URL. Its discovery document is read from
+	// `<Issuer>/.well-known/openid-configuration`.
+	Issuer       string
+	ClientID     string   `split_words:"true"`
+	ClientSecret string   `split_words:"true"`
+	RedirectURL  string   `split_words:"true" default:"http://localhost:3080/api/v1/oidc/callback"`
+	Scopes       []string `default:"openid,email,profile"`
+	// SuccessURL is where the browser is sent once logged in.
+	SuccessURL string `split_words:"true" default:"http://localhost:3000"`
+	// StateExpiry is how long users have to finish logging in at provider.
+	StateExpiry time.Duration `split_words:"true" default:"10m"`
+	// DiscoveryTTL is how long discovery document is cached before it is
+	// fetched again. Signing keys are refreshed whenever an unknown key ID is
+	// seen.
+	DiscoveryTTL time.Duration `split_words:"true" default:"24h"`
+}
+
+func NewOIDC() OIDC {
+	var oidc OIDC
+	envconfig.MustProcess("OIDC", &oidc)
+
+	return oidc
+}
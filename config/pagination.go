@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Pagination struct {
+	// CursorKey signs pagination cursors so that clients cannot forge them.
+	// It is a base64 encoded key of at least 32 bytes, generated with
+	// `openssl rand -base64 32`, and must be the same on every instance.
+	// Without it, a temporary key is used and cursors stop working on
+	// restart.
+	CursorKey string `split_words:"true"`
+}
+
+func NewPagination() Pagination {
+	var p Pagination
+	envconfig.MustProcess("PAGINATION", &p)
+
+	return p
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Precondition decides which writes must name the revision of the record
+// they change, so that they cannot overwrite changes they have not seen.
+type Precondition struct {
+	// Routes refuse writes without an If-Match header with 428 Precondition
+	// Required. Each is a route pattern, optionally after a method, e.g.
+	// "PUT /api/v1/book/{bookID}". Without a method, PUT, PATCH and DELETE
+	// are all refused.
+	Routes []string
+}
+
+func NewPrecondition() Precondition {
+	var p Precondition
+	envconfig.MustProcess("PRECONDITION", &p)
+
+	return p
+}
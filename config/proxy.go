@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Proxy lists reverse proxies in front of the API. Only they are believed
+// about who the client is.
+type Proxy struct {
+	// Trusted are addresses or CIDR ranges, e.g. "10.0.0.0/8". Forwarding
+	// headers from anyone else are ignored.
+	Trusted []string
+}
+
+func NewProxy() Proxy {
+	var p Proxy
+	envconfig.MustProcess("PROXY", &p)
+
+	return p
+}
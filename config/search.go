@@ -0,0 +1,35 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Search struct {
+	// Backend is where books and authors are searched: postgres,
+	// elasticsearch or embedded.
+	Backend string `default:"postgres"`
+	// Language is the PostgreSQL text search configuration used to stem
+	// words of books and search queries. The books' search column is
+	// generated with the language set when migrations were run, so changing
+	// it afterwards needs that column regenerated too.
+	Language string `default:"english"`
+	// SimilarityThreshold is how alike, from 0 to 1, an author's name must
+	// be to the searched one to be found. The default lets a short name have
+	// a couple of typos.
+	SimilarityThreshold float64 `split_words:"true" default:"0.12"`
+	// Directory is where the embedded search indexes are saved.
+	Directory string `default:"search"`
+	// SaveInterval is how often the embedded search indexes are saved when
+	// they changed. Changes since the last save are recovered on startup
+	// by rebuilding the indexes from the database.
+	SaveInterval time.Duration `split_words:"true" default:"1m"`
+}
+
+func NewSearch() Search {
+	var search Search
+	envconfig.MustProcess("SEARCH", &search)
+
+	return search
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Token configures stateless authentication with signed access tokens and
+// rotating refresh tokens, for clients that cannot use cookies. It works
+// alongside cookie sessions.
+type Token struct {
+	Enable bool `default:"false"`
+	// Issuer is put in `iss` claim and checked on every access token.
+	Issuer        string        `default:"go8"`
+	AccessExpiry  time.Duration `split_words:"true" default:"15m"`
+	RefreshExpiry time.Duration `split_words:"true" default:"720h"`
+	// SigningKeys are base64 encoded 32-byte Ed25519 seeds, generated with
+	// `openssl rand -base64 32`. First key signs new tokens. The rest are
+	// only used to verify tokens, so that keys can be rotated by adding a new
+	// key in front and removing the old one once its tokens have expired.
+	SigningKeys []string `split_words:"true"`
+}
+
+func NewToken() Token {
+	var t Token
+	envconfig.MustProcess("TOKEN", &t)
+
+	return t
+}
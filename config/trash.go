@@ -0,0 +1,23 @@
+package config
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Trash decides how long deleted books and authors can still be restored
+// before they are purged for good.
+type Trash struct {
+	Retention time.Duration `default:"720h"`
+	// PurgeInterval is how often the trash is emptied of records older than
+	// Retention. 0 turns purging off.
+	PurgeInterval time.Duration `split_words:"true" default:"24h"`
+}
+
+func NewTrash() Trash {
+	var t Trash
+	envconfig.MustProcess("TRASH", &t)
+
+	return t
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_tokens
+(
+    token      TEXT PRIMARY KEY,
+    user_id    BIGINT      NOT NULL CONSTRAINT user_tokens_user_fk REFERENCES users ON DELETE CASCADE,
+    purpose    TEXT        NOT NULL,
+    expiry     TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX user_tokens_user_id_purpose_idx ON user_tokens (user_id, purpose);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS roles
+(
+    id          bigint generated always as identity primary key,
+    name        text not null unique,
+    description text
+);
+
+CREATE TABLE IF NOT EXISTS permissions
+(
+    id          bigint generated always as identity primary key,
+    name        text not null unique,
+    description text
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role_id       bigint not null
+        constraint role_permissions_roles_id_fk references roles on delete cascade,
+    permission_id bigint not null
+        constraint role_permissions_permissions_id_fk references permissions on delete cascade,
+    constraint role_permissions_pk primary key (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles
+(
+    user_id    bigint not null
+        constraint user_roles_users_id_fk references users on delete cascade,
+    role_id    bigint not null
+        constraint user_roles_roles_id_fk references roles on delete cascade,
+    created_at timestamptz default current_timestamp,
+    constraint user_roles_pk primary key (user_id, role_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_roles;
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE roles;
+-- +goose StatementEnd
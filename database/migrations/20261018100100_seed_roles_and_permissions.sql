@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO roles (name, description)
+VALUES ('admin', 'Full access including user and role management'),
+       ('editor', 'Manages books and authors')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description)
+VALUES ('books:write', 'Create, update and delete books'),
+       ('authors:write', 'Create, update and delete authors'),
+       ('sessions:revoke', 'Log out any user'),
+       ('roles:write', 'Grant and revoke roles')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         CROSS JOIN permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.name IN ('books:write', 'authors:write')
+WHERE r.name = 'editor'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM roles WHERE name IN ('admin', 'editor');
+DELETE FROM permissions WHERE name IN ('books:write', 'authors:write', 'sessions:revoke', 'roles:write');
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           bigint generated always as identity primary key,
+    user_id      bigint      not null
+        constraint api_keys_users_id_fk references users on delete cascade,
+    name         text        not null,
+    token        text        not null unique,
+    prefix       text        not null,
+    scopes       jsonb       not null default '[]',
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    created_at   timestamptz default current_timestamp
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_keys;
+-- +goose StatementEnd
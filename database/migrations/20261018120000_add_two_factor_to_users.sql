@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN totp_secret     bytea,
+    ADD COLUMN totp_enabled_at timestamptz,
+    ADD COLUMN totp_last_step  bigint;
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes
+(
+    code       text primary key,
+    user_id    bigint not null
+        constraint user_recovery_codes_users_id_fk references users on delete cascade,
+    created_at timestamptz default current_timestamp
+);
+
+CREATE INDEX user_recovery_codes_user_id_idx ON user_recovery_codes (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN totp_last_step,
+    DROP COLUMN totp_enabled_at,
+    DROP COLUMN totp_secret;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    key             TEXT PRIMARY KEY,
+    failures        INT         NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    locked_until    TIMESTAMPTZ
+);
+
+INSERT INTO permissions (name, description)
+VALUES ('accounts:unlock', 'Unlock accounts locked after failed logins')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.name = 'accounts:unlock'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'accounts:unlock';
+DROP TABLE login_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions
+    ADD COLUMN public_id    UUID        NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    ADD COLUMN created_at   TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    ADD COLUMN last_seen_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    ADD COLUMN ip_address   TEXT,
+    ADD COLUMN user_agent   TEXT;
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX sessions_user_id_idx;
+
+ALTER TABLE sessions
+    DROP COLUMN user_agent,
+    DROP COLUMN ip_address,
+    DROP COLUMN last_seen_at,
+    DROP COLUMN created_at,
+    DROP COLUMN public_id;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    id         BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id    BIGINT      NOT NULL CONSTRAINT user_identities_user_fk REFERENCES users ON DELETE CASCADE,
+    issuer     TEXT        NOT NULL,
+    subject    TEXT        NOT NULL,
+    email      TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    CONSTRAINT user_identities_issuer_subject_key UNIQUE (issuer, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_identities;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audits
+(
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    actor_id     BIGINT CONSTRAINT audits_actor_fk REFERENCES users ON DELETE SET NULL,
+    table_row_id BIGINT,
+    table_name   TEXT        NOT NULL,
+    action       TEXT        NOT NULL,
+    old_values   TEXT,
+    new_values   TEXT,
+    http_method  TEXT,
+    url          TEXT,
+    ip_address   TEXT,
+    user_agent   TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX audits_actor_id_idx ON audits (actor_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audits;
+-- +goose StatementEnd
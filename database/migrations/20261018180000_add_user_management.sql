@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN disabled_at timestamptz;
+
+ALTER TABLE sessions
+    ADD COLUMN impersonator_id BIGINT CONSTRAINT sessions_impersonator_fk REFERENCES users ON DELETE CASCADE;
+
+ALTER TABLE audits
+    ADD COLUMN impersonated_id BIGINT CONSTRAINT audits_impersonated_fk REFERENCES users ON DELETE SET NULL;
+
+INSERT INTO permissions (name, description)
+VALUES ('users:read', 'List users and view their sessions'),
+       ('users:write', 'Disable, enable and verify user accounts'),
+       ('users:impersonate', 'Log in as another user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.name IN ('users:read', 'users:write', 'users:impersonate')
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('users:read', 'users:write', 'users:impersonate');
+
+ALTER TABLE audits
+    DROP COLUMN impersonated_id;
+
+ALTER TABLE sessions
+    DROP COLUMN impersonator_id;
+
+ALTER TABLE users
+    DROP COLUMN disabled_at;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS authors_deleted_at_idx ON authors (deleted_at) WHERE deleted_at IS NOT NULL;
+
+INSERT INTO permissions (name, description)
+VALUES ('trash:read', 'List deleted books and authors')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.name = 'trash:read'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'trash:read';
+
+DROP INDEX IF EXISTS authors_deleted_at_idx;
+DROP INDEX IF EXISTS books_deleted_at_idx;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- +goose ENVSUB ON
+ALTER TABLE books
+    ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('${SEARCH_LANGUAGE:-english}', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('${SEARCH_LANGUAGE:-english}', coalesce(description, '')), 'B')
+    ) STORED;
+-- +goose ENVSUB OFF
+
+CREATE INDEX IF NOT EXISTS books_search_idx ON books USING gin (search);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS books_search_idx;
+
+ALTER TABLE books DROP COLUMN IF EXISTS search;
+-- +goose StatementEnd
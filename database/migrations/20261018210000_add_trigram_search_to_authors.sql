@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS authors_full_name_trgm_idx ON authors
+    USING gin ((first_name || ' ' || coalesce(middle_name, '') || ' ' || last_name) gin_trgm_ops)
+    WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS authors_full_name_trgm_idx;
+-- +goose StatementEnd
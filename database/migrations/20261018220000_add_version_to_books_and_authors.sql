@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE books ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+ALTER TABLE authors ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION increment_version_column()
+    RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER increment_book_version BEFORE UPDATE
+    ON books FOR EACH ROW EXECUTE PROCEDURE
+    increment_version_column();
+
+CREATE TRIGGER increment_author_version BEFORE UPDATE
+    ON authors FOR EACH ROW EXECUTE PROCEDURE
+    increment_version_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS increment_author_version ON authors;
+DROP TRIGGER IF EXISTS increment_book_version ON books;
+DROP FUNCTION IF EXISTS increment_version_column();
+
+ALTER TABLE authors DROP COLUMN IF EXISTS version;
+ALTER TABLE books DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -13,12 +13,14 @@ import (
 )
 
 type Seed struct {
-	DB *sql.DB
+	DB     *sql.DB
+	Params *argon2id.Params
 }
 
-func Seeder(db *sql.DB) *Seed {
+func Seeder(db *sql.DB, params *argon2id.Params) *Seed {
 	return &Seed{
-		DB: db,
+		DB:     db,
+		Params: params,
 	}
 }
 
@@ -27,6 +29,7 @@ type user struct {
 	LastName  string
 	Email     string
 	Password  string
+	Roles     []string
 }
 
 func (m *Seed) SeedUsers() {
@@ -36,11 +39,12 @@ func (m *Seed) SeedUsers() {
 			LastName:  "Last Name",
 			Email:     "admin@gmhafiz.com",
 			Password:  randomAndWrite(16),
+			Roles:     []string{"admin"},
 		},
 	}
 
 	for _, u := range users {
-		password, err := argon2id.CreateHash(u.Password, argon2id.DefaultParams)
+		password, err := argon2id.CreateHash(u.Password, m.Params)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -57,6 +61,21 @@ func (m *Seed) SeedUsers() {
 		if err != nil {
 			log.Fatalln(err)
 		}
+
+		// Roles are created by migration.
+		for _, role := range u.Roles {
+			_, err = m.DB.ExecContext(
+				context.Background(),
+				`INSERT INTO user_roles (user_id, role_id)
+					SELECT u.id, r.id FROM users u, roles r WHERE u.email = $1 AND r.name = $2
+					ON CONFLICT DO NOTHING;`,
+				u.Email,
+				role,
+			)
+			if err != nil {
+				log.Fatalln(err)
+			}
+		}
 	}
 }
 
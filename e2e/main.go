@@ -2,16 +2,19 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
 	"net/http"
+	"net/http/cookiejar"
 	"time"
 
 	"github.com/gmhafiz/go8/config"
 	"github.com/gmhafiz/go8/internal/domain/book"
+	db "github.com/gmhafiz/go8/third_party/database"
 )
 
 // Version is injected using ldflags during build time
@@ -19,6 +22,9 @@ const Version = "v0.1.0"
 
 var url = ""
 
+// client keeps the session cookie of the logged-in editor.
+var client = &http.Client{}
+
 func main() {
 	log.Printf("Starting e2e API version: %s\n", Version)
 	cfg := config.New()
@@ -27,9 +33,80 @@ func main() {
 
 	waitForAPI(fmt.Sprintf("%s/api/health/readiness", url))
 
+	loginAsEditor(cfg)
+
 	run()
 }
 
+// loginAsEditor registers a new user, grants it the editor role directly in
+// the database and logs in. Write routes require `books:write` permission.
+func loginAsEditor(cfg *config.Config) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	client.Jar = jar
+
+	credentials, _ := json.Marshal(map[string]string{
+		"email":    "editor@example.com",
+		"password": "highEntropyPassword",
+	})
+
+	resp, err := client.Post(fmt.Sprintf("%s/api/v1/register", url), "application/json", bytes.NewBuffer(credentials))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	_ = resp.Body.Close()
+
+	store := db.NewSqlx(cfg.Database)
+	defer store.Close()
+
+	_, err = store.ExecContext(context.Background(), `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT u.id, r.id FROM users u, roles r WHERE u.email = $1 AND r.name = 'editor'
+		ON CONFLICT DO NOTHING`, "editor@example.com")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	resp, err = client.Post(fmt.Sprintf("%s/api/v1/login", url), "application/json", bytes.NewBuffer(credentials))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("unable to log in, want %d, got %d\n", http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = client.Get(fmt.Sprintf("%s/api/v1/restricted/csrf", url))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		CsrfToken string `json:"csrf_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		log.Fatalln(err)
+	}
+
+	client.Transport = &csrfTransport{token: token.CsrfToken}
+}
+
+// csrfTransport attaches CSRF token to every request, which is required on
+// write routes when logged in with a session cookie.
+type csrfTransport struct {
+	token string
+}
+
+func (t *csrfTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-CSRF-Token", t.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
 func run() {
 	testBook()
 
@@ -80,7 +157,7 @@ func testAddOneBook() uint64 {
 
 	bR, _ := json.Marshal(want)
 
-	resp, err := http.Post(
+	resp, err := client.Post(
 		fmt.Sprintf("%s/api/v1/book", url),
 		"Content-Type: application/json",
 		bytes.NewBuffer(bR),
@@ -115,8 +192,6 @@ func testAddOneBook() uint64 {
 }
 
 func testGetOneBook(id uint64) uint64 {
-	client := &http.Client{}
-
 	url := fmt.Sprintf("%s/api/v1/book/%d", url, id)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
@@ -157,8 +232,6 @@ func testUpdateBook(bookID uint64) {
 		Description:   "test description",
 	}
 
-	client := &http.Client{}
-
 	bR, err := json.Marshal(&newBook)
 	if err != nil {
 		log.Fatal(err)
@@ -202,8 +275,6 @@ func testUpdateBook(bookID uint64) {
 }
 
 func testDeleteOneBook(id uint64) {
-	client := &http.Client{}
-
 	req, err := http.NewRequest(
 		http.MethodDelete, fmt.Sprintf("%s/api/v1/book/%d", url, id),
 		nil,
@@ -29,6 +29,8 @@ type Author struct {
 	UpdatedAt time.Time `json:"-"`
 	// DeletedAt holds the value of the "deleted_at" field.
 	DeletedAt *time.Time `json:"-"`
+	// Version holds the value of the "version" field.
+	Version int64 `json:"-"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the AuthorQuery when eager-loading is set.
 	Edges        AuthorEdges `json:"edges"`
@@ -58,7 +60,7 @@ func (*Author) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case author.FieldID:
+		case author.FieldID, author.FieldVersion:
 			values[i] = new(sql.NullInt64)
 		case author.FieldFirstName, author.FieldMiddleName, author.FieldLastName:
 			values[i] = new(sql.NullString)
@@ -122,6 +124,12 @@ func (_m *Author) assignValues(columns []string, values []any) error {
 				_m.DeletedAt = new(time.Time)
 				*_m.DeletedAt = value.Time
 			}
+		case author.FieldVersion:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field version", values[i])
+			} else if value.Valid {
+				_m.Version = value.Int64
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -182,6 +190,9 @@ func (_m *Author) String() string {
 		builder.WriteString("deleted_at=")
 		builder.WriteString(v.Format(time.ANSIC))
 	}
+	builder.WriteString(", ")
+	builder.WriteString("version=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Version))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -89,6 +89,20 @@ func (_c *AuthorCreate) SetNillableDeletedAt(v *time.Time) *AuthorCreate {
 	return _c
 }
 
+// SetVersion sets the "version" field.
+func (_c *AuthorCreate) SetVersion(v int64) *AuthorCreate {
+	_c.mutation.SetVersion(v)
+	return _c
+}
+
+// SetNillableVersion sets the "version" field if the given value is not nil.
+func (_c *AuthorCreate) SetNillableVersion(v *int64) *AuthorCreate {
+	if v != nil {
+		_c.SetVersion(*v)
+	}
+	return _c
+}
+
 // SetID sets the "id" field.
 func (_c *AuthorCreate) SetID(v uint64) *AuthorCreate {
 	_c.mutation.SetID(v)
@@ -206,6 +220,10 @@ func (_c *AuthorCreate) createSpec() (*Author, *sqlgraph.CreateSpec) {
 		_spec.SetField(author.FieldDeletedAt, field.TypeTime, value)
 		_node.DeletedAt = &value
 	}
+	if value, ok := _c.mutation.Version(); ok {
+		_spec.SetField(author.FieldVersion, field.TypeInt64, value)
+		_node.Version = value
+	}
 	if nodes := _c.mutation.BooksIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -137,6 +137,33 @@ func (_u *AuthorUpdate) ClearDeletedAt() *AuthorUpdate {
 	return _u
 }
 
+// SetVersion sets the "version" field.
+func (_u *AuthorUpdate) SetVersion(v int64) *AuthorUpdate {
+	_u.mutation.ResetVersion()
+	_u.mutation.SetVersion(v)
+	return _u
+}
+
+// SetNillableVersion sets the "version" field if the given value is not nil.
+func (_u *AuthorUpdate) SetNillableVersion(v *int64) *AuthorUpdate {
+	if v != nil {
+		_u.SetVersion(*v)
+	}
+	return _u
+}
+
+// AddVersion adds value to the "version" field.
+func (_u *AuthorUpdate) AddVersion(v int64) *AuthorUpdate {
+	_u.mutation.AddVersion(v)
+	return _u
+}
+
+// ClearVersion clears the value of the "version" field.
+func (_u *AuthorUpdate) ClearVersion() *AuthorUpdate {
+	_u.mutation.ClearVersion()
+	return _u
+}
+
 // AddBookIDs adds the "books" edge to the Book entity by IDs.
 func (_u *AuthorUpdate) AddBookIDs(ids ...uint64) *AuthorUpdate {
 	_u.mutation.AddBookIDs(ids...)
@@ -244,6 +271,15 @@ func (_u *AuthorUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.DeletedAtCleared() {
 		_spec.ClearField(author.FieldDeletedAt, field.TypeTime)
 	}
+	if value, ok := _u.mutation.Version(); ok {
+		_spec.SetField(author.FieldVersion, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedVersion(); ok {
+		_spec.AddField(author.FieldVersion, field.TypeInt64, value)
+	}
+	if _u.mutation.VersionCleared() {
+		_spec.ClearField(author.FieldVersion, field.TypeInt64)
+	}
 	if _u.mutation.BooksCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -417,6 +453,33 @@ func (_u *AuthorUpdateOne) ClearDeletedAt() *AuthorUpdateOne {
 	return _u
 }
 
+// SetVersion sets the "version" field.
+func (_u *AuthorUpdateOne) SetVersion(v int64) *AuthorUpdateOne {
+	_u.mutation.ResetVersion()
+	_u.mutation.SetVersion(v)
+	return _u
+}
+
+// SetNillableVersion sets the "version" field if the given value is not nil.
+func (_u *AuthorUpdateOne) SetNillableVersion(v *int64) *AuthorUpdateOne {
+	if v != nil {
+		_u.SetVersion(*v)
+	}
+	return _u
+}
+
+// AddVersion adds value to the "version" field.
+func (_u *AuthorUpdateOne) AddVersion(v int64) *AuthorUpdateOne {
+	_u.mutation.AddVersion(v)
+	return _u
+}
+
+// ClearVersion clears the value of the "version" field.
+func (_u *AuthorUpdateOne) ClearVersion() *AuthorUpdateOne {
+	_u.mutation.ClearVersion()
+	return _u
+}
+
 // AddBookIDs adds the "books" edge to the Book entity by IDs.
 func (_u *AuthorUpdateOne) AddBookIDs(ids ...uint64) *AuthorUpdateOne {
 	_u.mutation.AddBookIDs(ids...)
@@ -554,6 +617,15 @@ func (_u *AuthorUpdateOne) sqlSave(ctx context.Context) (_node *Author, err erro
 	if _u.mutation.DeletedAtCleared() {
 		_spec.ClearField(author.FieldDeletedAt, field.TypeTime)
 	}
+	if value, ok := _u.mutation.Version(); ok {
+		_spec.SetField(author.FieldVersion, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedVersion(); ok {
+		_spec.AddField(author.FieldVersion, field.TypeInt64, value)
+	}
+	if _u.mutation.VersionCleared() {
+		_spec.ClearField(author.FieldVersion, field.TypeInt64)
+	}
 	if _u.mutation.BooksCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -95,6 +95,20 @@ func (_c *BookCreate) SetNillableDeletedAt(v *time.Time) *BookCreate {
 	return _c
 }
 
+// SetVersion sets the "version" field.
+func (_c *BookCreate) SetVersion(v int64) *BookCreate {
+	_c.mutation.SetVersion(v)
+	return _c
+}
+
+// SetNillableVersion sets the "version" field if the given value is not nil.
+func (_c *BookCreate) SetNillableVersion(v *int64) *BookCreate {
+	if v != nil {
+		_c.SetVersion(*v)
+	}
+	return _c
+}
+
 // SetID sets the "id" field.
 func (_c *BookCreate) SetID(v uint64) *BookCreate {
 	_c.mutation.SetID(v)
@@ -219,6 +233,10 @@ func (_c *BookCreate) createSpec() (*Book, *sqlgraph.CreateSpec) {
 		_spec.SetField(book.FieldDeletedAt, field.TypeTime, value)
 		_node.DeletedAt = &value
 	}
+	if value, ok := _c.mutation.Version(); ok {
+		_spec.SetField(book.FieldVersion, field.TypeInt64, value)
+		_node.Version = value
+	}
 	if nodes := _c.mutation.AuthorsIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -151,6 +151,33 @@ func (_u *BookUpdate) ClearDeletedAt() *BookUpdate {
 	return _u
 }
 
+// SetVersion sets the "version" field.
+func (_u *BookUpdate) SetVersion(v int64) *BookUpdate {
+	_u.mutation.ResetVersion()
+	_u.mutation.SetVersion(v)
+	return _u
+}
+
+// SetNillableVersion sets the "version" field if the given value is not nil.
+func (_u *BookUpdate) SetNillableVersion(v *int64) *BookUpdate {
+	if v != nil {
+		_u.SetVersion(*v)
+	}
+	return _u
+}
+
+// AddVersion adds value to the "version" field.
+func (_u *BookUpdate) AddVersion(v int64) *BookUpdate {
+	_u.mutation.AddVersion(v)
+	return _u
+}
+
+// ClearVersion clears the value of the "version" field.
+func (_u *BookUpdate) ClearVersion() *BookUpdate {
+	_u.mutation.ClearVersion()
+	return _u
+}
+
 // AddAuthorIDs adds the "authors" edge to the Author entity by IDs.
 func (_u *BookUpdate) AddAuthorIDs(ids ...uint64) *BookUpdate {
 	_u.mutation.AddAuthorIDs(ids...)
@@ -261,6 +288,15 @@ func (_u *BookUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.DeletedAtCleared() {
 		_spec.ClearField(book.FieldDeletedAt, field.TypeTime)
 	}
+	if value, ok := _u.mutation.Version(); ok {
+		_spec.SetField(book.FieldVersion, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedVersion(); ok {
+		_spec.AddField(book.FieldVersion, field.TypeInt64, value)
+	}
+	if _u.mutation.VersionCleared() {
+		_spec.ClearField(book.FieldVersion, field.TypeInt64)
+	}
 	if _u.mutation.AuthorsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -448,6 +484,33 @@ func (_u *BookUpdateOne) ClearDeletedAt() *BookUpdateOne {
 	return _u
 }
 
+// SetVersion sets the "version" field.
+func (_u *BookUpdateOne) SetVersion(v int64) *BookUpdateOne {
+	_u.mutation.ResetVersion()
+	_u.mutation.SetVersion(v)
+	return _u
+}
+
+// SetNillableVersion sets the "version" field if the given value is not nil.
+func (_u *BookUpdateOne) SetNillableVersion(v *int64) *BookUpdateOne {
+	if v != nil {
+		_u.SetVersion(*v)
+	}
+	return _u
+}
+
+// AddVersion adds value to the "version" field.
+func (_u *BookUpdateOne) AddVersion(v int64) *BookUpdateOne {
+	_u.mutation.AddVersion(v)
+	return _u
+}
+
+// ClearVersion clears the value of the "version" field.
+func (_u *BookUpdateOne) ClearVersion() *BookUpdateOne {
+	_u.mutation.ClearVersion()
+	return _u
+}
+
 // AddAuthorIDs adds the "authors" edge to the Author entity by IDs.
 func (_u *BookUpdateOne) AddAuthorIDs(ids ...uint64) *BookUpdateOne {
 	_u.mutation.AddAuthorIDs(ids...)
@@ -588,6 +651,15 @@ func (_u *BookUpdateOne) sqlSave(ctx context.Context) (_node *Book, err error) {
 	if _u.mutation.DeletedAtCleared() {
 		_spec.ClearField(book.FieldDeletedAt, field.TypeTime)
 	}
+	if value, ok := _u.mutation.Version(); ok {
+		_spec.SetField(book.FieldVersion, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedVersion(); ok {
+		_spec.AddField(book.FieldVersion, field.TypeInt64, value)
+	}
+	if _u.mutation.VersionCleared() {
+		_spec.ClearField(book.FieldVersion, field.TypeInt64)
+	}
 	if _u.mutation.AuthorsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
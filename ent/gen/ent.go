@@ -12,10 +12,14 @@ import (
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/gmhafiz/go8/ent/gen/apikey"
 	"github.com/gmhafiz/go8/ent/gen/author"
 	"github.com/gmhafiz/go8/ent/gen/book"
+	"github.com/gmhafiz/go8/ent/gen/permission"
+	"github.com/gmhafiz/go8/ent/gen/role"
 	"github.com/gmhafiz/go8/ent/gen/session"
 	"github.com/gmhafiz/go8/ent/gen/user"
+	"github.com/gmhafiz/go8/ent/gen/userrole"
 )
 
 // ent aliases to avoid import conflicts in user's code.
@@ -76,10 +80,14 @@ var (
 func checkColumn(t, c string) error {
 	initCheck.Do(func() {
 		columnCheck = sql.NewColumnCheck(map[string]func(string) bool{
-			author.Table:  author.ValidColumn,
-			book.Table:    book.ValidColumn,
-			session.Table: session.ValidColumn,
-			user.Table:    user.ValidColumn,
+			apikey.Table:     apikey.ValidColumn,
+			author.Table:     author.ValidColumn,
+			book.Table:       book.ValidColumn,
+			permission.Table: permission.ValidColumn,
+			role.Table:       role.ValidColumn,
+			session.Table:    session.ValidColumn,
+			user.Table:       user.ValidColumn,
+			userrole.Table:   userrole.ValidColumn,
 		})
 	})
 	return columnCheck(t, c)
@@ -21,6 +21,7 @@ func (Author) Fields() []ent.Field {
 		field.Time("created_at").Optional().StructTag(`json:"-"`),
 		field.Time("updated_at").Optional().StructTag(`json:"-"`),
 		field.Time("deleted_at").Optional().Nillable().StructTag(`json:"-"`),
+		field.Int64("version").Optional().StructTag(`json:"-"`),
 	}
 }
 
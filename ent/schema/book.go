@@ -22,6 +22,7 @@ func (Book) Fields() []ent.Field {
 		field.Time("created_at").Optional().StructTag(`json:"-"`),
 		field.Time("updated_at").Optional().StructTag(`json:"-"`),
 		field.Time("deleted_at").Optional().Nillable().StructTag(`json:"-"`),
+		field.Int64("version").Optional().StructTag(`json:"-"`),
 	}
 }
 
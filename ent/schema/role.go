@@ -0,0 +1,30 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// Role holds the schema definition for the Role entity.
+type Role struct {
+	ent.Schema
+}
+
+// Fields of the Role.
+func (Role) Fields() []ent.Field {
+	return []ent.Field{
+		field.Uint64("id"),
+		field.String("name").Unique(),
+		field.String("description").Optional(),
+	}
+}
+
+// Edges of the Role.
+func (Role) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("permissions", Permission.Type).
+			StorageKey(edge.Table("role_permissions"), edge.Columns("role_id", "permission_id")),
+		edge.From("users", User.Type).Ref("roles").Through("user_roles", UserRole.Type),
+	}
+}
@@ -17,5 +17,10 @@ func (Session) Fields() []ent.Field {
 		field.Uint64("user_id").Nillable().Optional(),
 		field.Bytes("data"),
 		field.Time("expiry"),
+		field.String("public_id").Optional(),
+		field.Time("created_at").Optional(),
+		field.Time("last_seen_at").Optional(),
+		field.String("ip_address").Optional(),
+		field.String("user_agent").Optional(),
 	}
 }
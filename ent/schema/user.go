@@ -2,6 +2,7 @@ package schema
 
 import (
 	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
 )
 
@@ -18,7 +19,20 @@ func (User) Fields() []ent.Field {
 		field.String("middle_name").Optional(),
 		field.String("last_name").Optional(),
 		field.String("email"),
-		field.String("password"),
+		field.String("password").Sensitive(),
 		field.Time("verified_at").Optional().Nillable().StructTag(`json:"-"`),
+		field.Bytes("totp_secret").Optional().Sensitive(),
+		field.Time("totp_enabled_at").Optional().Nillable().StructTag(`json:"-"`),
+		field.Int64("totp_last_step").Optional().Nillable().StructTag(`json:"-"`),
+		field.Time("delete_after").Optional().Nillable().StructTag(`json:"-"`),
+		field.Time("disabled_at").Optional().Nillable().StructTag(`json:"-"`),
+	}
+}
+
+// Edges of the User.
+func (User) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("roles", Role.Type).Through("user_roles", UserRole.Type),
+		edge.To("api_keys", APIKey.Type),
 	}
 }
@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// UserRole holds the schema definition for the UserRole entity. It is the
+// join table between users and roles.
+type UserRole struct {
+	ent.Schema
+}
+
+// Annotations of the UserRole.
+func (UserRole) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		field.ID("user_id", "role_id"),
+	}
+}
+
+// Fields of the UserRole.
+func (UserRole) Fields() []ent.Field {
+	return []ent.Field{
+		field.Uint64("user_id"),
+		field.Uint64("role_id"),
+		field.Time("created_at").Optional().StructTag(`json:"-"`),
+	}
+}
+
+// Edges of the UserRole.
+func (UserRole) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("user", User.Type).Unique().Required().Field("user_id"),
+		edge.To("role", Role.Type).Unique().Required().Field("role_id"),
+	}
+}
@@ -0,0 +1,238 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/request"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+var ErrNoDeletionScheduled = errors.New("account is not scheduled for deletion")
+
+// Profile returns current user's own details.
+func (h *Handler) Profile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value(middleware.KeyID).(uint64)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, ErrNotLoggedIn)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, ProfileResource(u))
+}
+
+// UpdateProfile changes current user's names. Email and password have their
+// own flows because they need to be verified.
+func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	var req UpdateProfileRequest
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	for _, name := range []*string{req.FirstName, req.MiddleName, req.LastName} {
+		if name != nil {
+			*name = strings.TrimSpace(*name)
+		}
+	}
+
+	u, err := h.repo.UpdateProfile(ctx, userID, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "updating profile", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, ProfileResource(u))
+}
+
+// DeleteAccount schedules current user's account to be deleted after
+// AccountDeletionGrace, and logs the user out everywhere. Logging in again
+// and calling RestoreAccount before then keeps the account.
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	var req DeleteAccountRequest
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if !h.confirmPassword(w, r, u, req.Password) {
+		return
+	}
+
+	deleteAfter := time.Now().Add(h.cfg.AccountDeletionGrace)
+	err = h.repo.ScheduleDeletion(ctx, userID, deleteAfter)
+	if err != nil {
+		if errors.Is(err, ErrDeletionScheduled) {
+			respond.Error(w, http.StatusConflict, err)
+			return
+		}
+		slog.ErrorContext(ctx, "scheduling account deletion", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	// Session row is already gone. Destroying it also expires the cookie.
+	if err := h.session.Destroy(ctx); err != nil {
+		slog.ErrorContext(ctx, "destroying session", "error", err)
+	}
+
+	respond.JSON(w, http.StatusAccepted, &AccountDeletionResponse{DeleteAfter: deleteAfter})
+}
+
+// RestoreAccount cancels a scheduled deletion of current user's account.
+func (h *Handler) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	cancelled, err := h.repo.CancelDeletion(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if !cancelled {
+		respond.Error(w, http.StatusConflict, ErrNoDeletionScheduled)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, ProfileResource(u))
+}
+
+// ExportAccount returns a copy of the data kept about current user: their
+// profile, sessions and the changes they made.
+func (h *Handler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	sessions, err := h.repo.Sessions(ctx, userID, h.session.Token(ctx))
+	if err != nil {
+		slog.ErrorContext(ctx, "listing sessions", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	audits, err := h.repo.AuditEntries(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "listing audit entries", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if audits == nil {
+		audits = []middleware.Event{}
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="account-%d.json"`, userID))
+	w.Header().Set("Cache-Control", "no-store")
+
+	respond.JSON(w, http.StatusOK, &ExportResponse{
+		ExportedAt: time.Now(),
+		User:       ProfileResource(u),
+		Sessions:   SessionResources(sessions),
+		Audits:     audits,
+	})
+}
+
+// AccountPurger deletes accounts whose deletion grace period has passed.
+type AccountPurger struct {
+	repo Repo
+	stop chan bool
+}
+
+// NewAccountPurger starts looking for accounts to delete every interval until
+// Stop is called. An interval of 0 turns purging off.
+func NewAccountPurger(repo Repo, interval time.Duration) *AccountPurger {
+	p := &AccountPurger{repo: repo}
+	if interval > 0 {
+		p.stop = make(chan bool)
+		go p.start(interval)
+	}
+
+	return p
+}
+
+func (p *AccountPurger) start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			purged, err := p.repo.PurgeDeletedAccounts(context.Background())
+			if err != nil {
+				slog.Error("purging deleted accounts", "error", err)
+				continue
+			}
+			if purged > 0 {
+				slog.Info("purged deleted accounts", "count", purged)
+			}
+		case <-p.stop:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// Stop terminates the background purge goroutine.
+func (p *AccountPurger) Stop() {
+	if p.stop != nil {
+		p.stop <- true
+	}
+}
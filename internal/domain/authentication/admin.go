@@ -0,0 +1,283 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/param"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+const (
+	actionDisable           = "disable"
+	actionEnable            = "enable"
+	actionVerify            = "verify"
+	actionImpersonate       = "impersonate"
+	actionStopImpersonation = "stop_impersonation"
+)
+
+var (
+	ErrImpersonating     = errors.New("this action is not allowed while impersonating a user")
+	ErrNotImpersonating  = errors.New("you are not impersonating anyone")
+	ErrImpersonateSelf   = errors.New("you cannot impersonate yourself")
+	ErrImpersonateAccess = errors.New("impersonation requires logging in with a password")
+	ErrImpersonateAdmin  = errors.New("you cannot impersonate a user with admin permissions")
+)
+
+// adminPermissions let a user manage other users. Holders of any of them
+// cannot be impersonated, so that impersonation never gains more access than
+// the admin already has.
+var adminPermissions = []string{
+	"roles:write",
+	"users:read",
+	"users:write",
+	"users:impersonate",
+	"sessions:revoke",
+	"accounts:unlock",
+}
+
+// ListUsers lists users, optionally filtered by `email`, `name` and
+// `disabled`, with the same paging and sorting parameters as other lists.
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	users, total, err := h.repo.ListUsers(ctx, UserFilters(r.URL.Query()))
+	if err != nil {
+		slog.ErrorContext(ctx, "listing users", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, respond.Standard{
+		Data: UserResources(users),
+		Meta: respond.Meta{
+			Size:  len(users),
+			Total: total,
+		},
+	})
+}
+
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	u, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, UserResource(u))
+}
+
+// UserSessions lists devices a user is logged in from.
+func (h *Handler) UserSessions(w http.ResponseWriter, r *http.Request) {
+	u, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+
+	sessions, err := h.repo.Sessions(ctx, u.ID, "")
+	if err != nil {
+		slog.ErrorContext(ctx, "listing sessions", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, SessionResources(sessions))
+}
+
+// DisableUser stops a user from logging in and logs them out everywhere.
+func (h *Handler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.changeUser(w, r, actionDisable, h.repo.DisableUser)
+}
+
+func (h *Handler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.changeUser(w, r, actionEnable, h.repo.EnableUser)
+}
+
+// VerifyUser marks a user's email address as verified.
+func (h *Handler) VerifyUser(w http.ResponseWriter, r *http.Request) {
+	h.changeUser(w, r, actionVerify, h.repo.MarkVerified)
+}
+
+func (h *Handler) changeUser(w http.ResponseWriter, r *http.Request, action string, change func(context.Context, uint64) error) {
+	userID, err := param.UInt64(r, "userID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	if err := change(ctx, userID); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			respond.Error(w, http.StatusNotFound, err)
+			return
+		}
+		slog.ErrorContext(ctx, "changing user", "action", action, "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if err := h.audit(ctx, action, userID); err != nil {
+		slog.ErrorContext(ctx, "saving audit event", "action", action, "error", err)
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, UserResource(u))
+}
+
+// Impersonate logs current admin in as another user to see what they see.
+// Session is flagged with the admin's ID until StopImpersonating is called,
+// and security settings of the user cannot be changed meanwhile. Nothing
+// happens unless the impersonation can be audited.
+func (h *Handler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Impersonating again would lose track of who the real admin is.
+	if _, ok := middleware.ImpersonatorID(ctx); ok {
+		respond.Error(w, http.StatusForbidden, ErrImpersonating)
+		return
+	}
+
+	adminID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+	if middleware.IsAccessToken(ctx) {
+		respond.Error(w, http.StatusForbidden, ErrImpersonateAccess)
+		return
+	}
+
+	u, ok := h.findUser(w, r)
+	if !ok {
+		return
+	}
+	if u.ID == adminID {
+		respond.Error(w, http.StatusBadRequest, ErrImpersonateSelf)
+		return
+	}
+	if u.DisabledAt != nil {
+		respond.Error(w, http.StatusConflict, middleware.ErrAccountDisabled)
+		return
+	}
+
+	admin, err := isAdmin(ctx, u.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "checking permission", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if admin {
+		respond.Error(w, http.StatusForbidden, ErrImpersonateAdmin)
+		return
+	}
+
+	if err := h.audit(ctx, actionImpersonate, u.ID); err != nil {
+		slog.ErrorContext(ctx, "saving audit event", "action", actionImpersonate, "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if err := h.session.RenewToken(ctx); err != nil {
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.session.Put(ctx, string(middleware.KeyID), u.ID)
+	h.session.Put(ctx, string(middleware.KeyImpersonatorID), adminID)
+
+	slog.WarnContext(ctx, "impersonating user",
+		"event", "impersonation",
+		"actor_id", adminID,
+		"user_id", u.ID,
+	)
+
+	respond.JSON(w, http.StatusOK, UserResource(u))
+}
+
+// StopImpersonating turns an impersonated session back into the admin's own
+// session.
+func (h *Handler) StopImpersonating(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	adminID, ok := middleware.ImpersonatorID(ctx)
+	if !ok {
+		respond.Error(w, http.StatusBadRequest, ErrNotImpersonating)
+		return
+	}
+	userID, _ := ctx.Value(middleware.KeyID).(uint64)
+
+	if err := h.audit(ctx, actionStopImpersonation, userID); err != nil {
+		slog.ErrorContext(ctx, "saving audit event", "action", actionStopImpersonation, "error", err)
+	}
+
+	if err := h.session.RenewToken(ctx); err != nil {
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	logIn(ctx, h.session, adminID)
+
+	respond.Status(w, http.StatusOK)
+}
+
+// isAdmin tells if userID holds any of adminPermissions, using the checker
+// registered by middleware.Permissions.
+func isAdmin(ctx context.Context, userID uint64) (bool, error) {
+	checker, ok := ctx.Value(middleware.KeyPermission).(middleware.PermissionChecker)
+	if !ok {
+		return false, errors.New("no permission checker registered")
+	}
+
+	for _, permission := range adminPermissions {
+		allowed, err := checker.HasPermission(ctx, userID, permission)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// findUser returns the user in `userID` URL parameter, or writes a 404.
+func (h *Handler) findUser(w http.ResponseWriter, r *http.Request) (*gen.User, bool) {
+	userID, err := param.UInt64(r, "userID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	u, err := h.repo.FindByID(r.Context(), userID)
+	if err != nil {
+		if gen.IsNotFound(err) {
+			respond.Error(w, http.StatusNotFound, ErrUserNotFound)
+			return nil, false
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return nil, false
+	}
+
+	return u, true
+}
+
+// audit records an action taken by an admin on a user. Request details come
+// from middleware.Audit.
+func (h *Handler) audit(ctx context.Context, action string, userID uint64) error {
+	ev, _ := ctx.Value(middleware.KeyAuditID).(middleware.Event)
+	ev.Table = "users"
+	ev.TableRowID = int(userID)
+	ev.Action = middleware.Action(action)
+
+	return h.repo.SaveAudit(ctx, ev)
+}
@@ -0,0 +1,38 @@
+package authentication
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/gmhafiz/go8/internal/utility/filter"
+)
+
+// UserFilter narrows down users listed by admins.
+type UserFilter struct {
+	Base filter.Filter
+
+	Email string `json:"email"`
+	// Name matches first, middle or last name.
+	Name     string `json:"name"`
+	Disabled *bool  `json:"disabled"`
+}
+
+func UserFilters(queries url.Values) *UserFilter {
+	f := filter.New(queries)
+	if queries.Has("email") || queries.Has("name") {
+		f.Search = true
+	}
+
+	var disabled *bool
+	if d, err := strconv.ParseBool(queries.Get("disabled")); err == nil {
+		disabled = &d
+	}
+
+	return &UserFilter{
+		Base: *f,
+
+		Email:    queries.Get("email"),
+		Name:     queries.Get("name"),
+		Disabled: disabled,
+	}
+}
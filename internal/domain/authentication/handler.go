@@ -1,32 +1,57 @@
 package authentication
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alexedwards/argon2id"
 	"github.com/gmhafiz/scs/v2"
+	"github.com/go-chi/chi/v5"
 
+	"github.com/gmhafiz/go8/config"
+	"github.com/gmhafiz/go8/ent/gen"
 	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/crypt"
 	"github.com/gmhafiz/go8/internal/utility/param"
+	"github.com/gmhafiz/go8/internal/utility/password"
 	"github.com/gmhafiz/go8/internal/utility/request"
 	"github.com/gmhafiz/go8/internal/utility/respond"
-)
-
-const (
-	minPasswordLength = 13
+	"github.com/gmhafiz/go8/third_party/mailer"
 )
 
 var (
-	ErrEmailRequired  = errors.New("email is required")
-	ErrPasswordLength = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	ErrEmailRequired   = errors.New("email is required")
+	ErrPasswordPolicy  = errors.New("password does not meet requirements")
+	ErrTokenRequired   = errors.New("token is required")
+	ErrNotVerified     = errors.New("email address has not been verified")
+	ErrNameRequired    = errors.New("name is required")
+	ErrExpiryInPast    = errors.New("expires_at must be in the future")
+	ErrAPIKeyCreation  = errors.New("api keys cannot be managed using an api key")
+	ErrPasswordChanged = errors.New("password was changed by another request, please try again")
 )
 
 type Handler struct {
+	cfg     config.Authentication
 	repo    Repo
 	session *scs.SessionManager
+	mail    mailer.Mailer
+	lockout *Lockout
+	// cipher encrypts TOTP secrets. It is nil when no encryption key is
+	// configured, in which case two-factor enrollment is unavailable.
+	cipher *crypt.Cipher
+	// params is used for hashing new passwords.
+	params *argon2id.Params
+	// policy checks new passwords. It is nil when the policy could not be
+	// loaded, in which case passwords cannot be set.
+	policy *password.Policy
 }
 
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
@@ -44,25 +69,207 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Password) < minPasswordLength {
-		respond.Error(w, http.StatusBadRequest, ErrPasswordLength)
+	if !h.validPassword(w, r, "password", req.Password, password.Owner{
+		Email:     req.Email,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	}) {
 		return
 	}
 
-	hashedPassword, err := argon2id.CreateHash(req.Password, argon2id.DefaultParams)
+	hashedPassword, err := password.Hash(req.Password, h.params)
 	if err != nil {
 		respond.Error(w, http.StatusInternalServerError, nil)
 		return
 	}
 
-	if err := h.repo.Register(r.Context(), req.FirstName, req.LastName, req.Email, hashedPassword); err != nil {
+	u, err := h.repo.Register(r.Context(), req.FirstName, req.LastName, req.Email, hashedPassword)
+	if err != nil {
 		respond.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
+	// Registration succeeds even if the email cannot be sent. User can ask for
+	// a new one through the resend endpoint.
+	if err := h.sendVerification(r.Context(), u); err != nil {
+		slog.ErrorContext(r.Context(), "sending verification email", "error", err)
+	}
+
 	respond.Status(w, http.StatusCreated)
 }
 
+// Verify consumes the token sent to user's email and marks the account as
+// verified.
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respond.Error(w, http.StatusBadRequest, ErrTokenRequired)
+		return
+	}
+
+	if err := h.repo.Verify(r.Context(), token); err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			respond.Error(w, http.StatusBadRequest, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// ResendVerification sends a new verification email. The response is the
+// same whether the email exists or not so that it cannot be used to find out
+// registered addresses.
+func (h *Handler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req.Email = strings.Trim(req.Email, " ")
+	if req.Email == "" {
+		respond.Error(w, http.StatusBadRequest, ErrEmailRequired)
+		return
+	}
+
+	ctx := r.Context()
+
+	u, err := h.repo.FindByEmail(ctx, req.Email)
+	switch {
+	case err != nil:
+		if !gen.IsNotFound(err) {
+			slog.ErrorContext(ctx, "finding user for verification", "error", err)
+		}
+	case u.VerifiedAt == nil:
+		if err := h.sendVerification(ctx, u); err != nil {
+			slog.ErrorContext(ctx, "sending verification email", "error", err)
+		}
+	}
+
+	respond.Status(w, http.StatusAccepted)
+}
+
+// ForgotPassword emails a password reset link. Like ResendVerification, it
+// responds identically whether the email exists or not. The email is sent in
+// the background so response time does not give it away either.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req.Email = strings.Trim(req.Email, " ")
+	if req.Email == "" {
+		respond.Error(w, http.StatusBadRequest, ErrEmailRequired)
+		return
+	}
+
+	ctx := context.WithoutCancel(r.Context())
+
+	go func() {
+		u, err := h.repo.FindByEmail(ctx, req.Email)
+		if err != nil {
+			if !gen.IsNotFound(err) {
+				slog.ErrorContext(ctx, "finding user for password reset", "error", err)
+			}
+			return
+		}
+
+		if err := h.sendPasswordReset(ctx, u); err != nil {
+			slog.ErrorContext(ctx, "sending password reset email", "error", err)
+		}
+	}()
+
+	respond.Status(w, http.StatusAccepted)
+}
+
+// ResetPassword sets a new password using the token from ForgotPassword. All
+// existing sessions of the user are revoked.
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	req.Password = strings.Trim(req.Password, " ")
+
+	if req.Token == "" {
+		respond.Error(w, http.StatusBadRequest, ErrTokenRequired)
+		return
+	}
+
+	u, err := h.repo.FindByToken(r.Context(), req.Token, purposePasswordReset)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			respond.Error(w, http.StatusBadRequest, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if !h.validPassword(w, r, "password", req.Password, owner(u)) {
+		return
+	}
+
+	hashedPassword, err := password.Hash(req.Password, h.params)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	_, err = h.repo.ResetPassword(r.Context(), req.Token, hashedPassword)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			respond.Error(w, http.StatusBadRequest, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+func (h *Handler) sendPasswordReset(ctx context.Context, u *gen.User) error {
+	token, err := h.repo.CreateToken(ctx, u.ID, purposePasswordReset, h.cfg.PasswordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := h.cfg.PasswordResetURL + "?token=" + url.QueryEscape(token)
+
+	return h.mail.Send(ctx, &mailer.Message{
+		To:      []string{u.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("A password reset was requested for your account. Open the following link to choose a new password:\n\n%s\n\n"+
+			"This link expires in %s. If you did not request this, you can ignore this email.\n", link, h.cfg.PasswordResetExpiry),
+	})
+}
+
+func (h *Handler) sendVerification(ctx context.Context, u *gen.User) error {
+	token, err := h.repo.CreateToken(ctx, u.ID, purposeVerification, h.cfg.VerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := h.cfg.VerificationURL + "?token=" + url.QueryEscape(token)
+
+	return h.mail.Send(ctx, &mailer.Message{
+		To:      []string{u.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Please verify your email address by opening the following link:\n\n%s\n\n"+
+			"This link expires in %s.\n", link, h.cfg.VerificationExpiry),
+	})
+}
+
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	err := request.DecodeJSON(w, r, &req)
@@ -73,9 +280,8 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	user, _, err := h.repo.Login(ctx, req)
-	if err != nil {
-		respond.Status(w, http.StatusUnauthorized)
+	user, ok := h.checkPassword(w, r, req.Email, req.Password)
+	if !ok {
 		return
 	}
 
@@ -84,19 +290,88 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.session.Put(ctx, string(middleware.KeyID), user.ID)
+	// Failures are only forgotten once login is complete, otherwise wrong
+	// second factor codes could be guessed forever by entering the password
+	// again.
+	if user.TotpEnabledAt != nil {
+		h.startTwoFactor(w, r, user.ID, req.RememberMe)
+		return
+	}
+
+	if err := h.lockout.Succeed(ctx, req.Email); err != nil {
+		slog.ErrorContext(ctx, "resetting failed logins", "error", err)
+	}
+
+	startSession(ctx, h.session, user.ID, req.RememberMe)
 
 	respond.Status(w, http.StatusOK)
 }
 
+// checkPassword finds the user and checks their password, subject to login
+// lockout. On failure, response is already written and false is returned.
+// Caller decides when to reset failed attempts with Lockout.Succeed.
+func (h *Handler) checkPassword(w http.ResponseWriter, r *http.Request, email, plain string) (*gen.User, bool) {
+	ctx := r.Context()
+	ip := middleware.ClientIP(r)
+
+	// Refuse early so that a locked account does not cost an argon2id
+	// comparison.
+	wait, err := h.lockout.Wait(ctx, email, ip)
+	if err != nil {
+		slog.ErrorContext(ctx, "checking login lockout", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return nil, false
+	}
+	if wait > 0 {
+		retryAfter(w, wait)
+		respond.Error(w, http.StatusTooManyRequests, ErrTooManyAttempts)
+		return nil, false
+	}
+
+	user, match, err := h.repo.Login(ctx, LoginRequest{Email: email, Password: plain})
+	if err != nil || !match {
+		wait, err := h.lockout.Fail(ctx, email, ip)
+		if err != nil {
+			slog.ErrorContext(ctx, "recording failed login", "error", err)
+		}
+		if wait > 0 {
+			retryAfter(w, wait)
+		}
+		respond.Status(w, http.StatusUnauthorized)
+		return nil, false
+	}
+
+	if user.DisabledAt != nil {
+		respond.Error(w, http.StatusForbidden, middleware.ErrAccountDisabled)
+		return nil, false
+	}
+
+	if h.cfg.RequireVerification && user.VerifiedAt == nil {
+		respond.Error(w, http.StatusForbidden, ErrNotVerified)
+		return nil, false
+	}
+
+	h.rehash(ctx, user, plain)
+
+	return user, true
+}
+
 func (h *Handler) Protected(w http.ResponseWriter, _ *http.Request) {
 	respond.JSON(w, http.StatusOK, map[string]string{"success": "yup!"})
 }
 
+// Me returns who is logged in and, for a session, when it expires if it is
+// not used again.
 func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
-	userID := h.session.Get(r.Context(), string(middleware.KeyID))
+	ctx := r.Context()
+	userID := ctx.Value(middleware.KeyID)
+
+	res := map[string]any{"user_id": userID}
+	if expiresAt, ok := middleware.SessionExpiresAt(ctx); ok && !middleware.IsAPIKey(ctx) && !middleware.IsAccessToken(ctx) {
+		res["expires_at"] = expiresAt
+	}
 
-	respond.JSON(w, http.StatusOK, map[string]any{"user_id": userID})
+	respond.JSON(w, http.StatusOK, res)
 }
 
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
@@ -107,16 +382,9 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ForceLogout deletes every session of a user. Only users with
+// `sessions:revoke` permission are let through by the route guard.
 func (h *Handler) ForceLogout(w http.ResponseWriter, r *http.Request) {
-	// Authorization is needed to ensure that only super admin can force delete
-	currUser := h.session.Get(r.Context(), string(middleware.KeyID))
-	// A more robust authorization is needed for real-world implementation.
-	// For now, we naively check if user id is equal to 1.
-	if currUser.(uint64) != 1 {
-		respond.Status(w, http.StatusInternalServerError)
-		return
-	}
-
 	userID, err := param.UInt64(r, "userID")
 	if err != nil {
 		respond.Status(w, http.StatusInternalServerError)
@@ -134,6 +402,230 @@ func (h *Handler) ForceLogout(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CreateAPIKey issues a new API key for current user. The key is shown only
+// in this response. Managing keys requires a session so that a leaked key
+// cannot be used to mint more keys.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if middleware.IsAPIKey(ctx) {
+		respond.Error(w, http.StatusForbidden, ErrAPIKeyCreation)
+		return
+	}
+
+	userID, ok := ctx.Value(middleware.KeyID).(uint64)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, ErrNotLoggedIn)
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req.Name = strings.Trim(req.Name, " ")
+	if req.Name == "" {
+		respond.Error(w, http.StatusBadRequest, ErrNameRequired)
+		return
+	}
+
+	if req.ExpiresAt == nil && h.cfg.APIKeyExpiry > 0 {
+		expiresAt := time.Now().Add(h.cfg.APIKeyExpiry)
+		req.ExpiresAt = &expiresAt
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		respond.Error(w, http.StatusBadRequest, ErrExpiryInPast)
+		return
+	}
+
+	if req.Scopes == nil {
+		req.Scopes = []string{}
+	}
+
+	created, key, err := h.repo.CreateAPIKey(ctx, userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "creating api key", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, &CreatedAPIKeyResponse{
+		APIKeyResponse: *APIKeyResource(created),
+		Key:            key,
+	})
+}
+
+// ListAPIKeys lists current user's API keys. Only their prefixes are shown.
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value(middleware.KeyID).(uint64)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, ErrNotLoggedIn)
+		return
+	}
+
+	keys, err := h.repo.ListAPIKeys(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, APIKeyResources(keys))
+}
+
+// RevokeAPIKey deletes one of current user's API keys.
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if middleware.IsAPIKey(ctx) {
+		respond.Error(w, http.StatusForbidden, ErrAPIKeyCreation)
+		return
+	}
+
+	userID, ok := ctx.Value(middleware.KeyID).(uint64)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, ErrNotLoggedIn)
+		return
+	}
+
+	id, err := param.UInt64(r, "id")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.repo.RevokeAPIKey(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			respond.Error(w, http.StatusNotFound, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+// Unlock clears failed logins of a user so that a locked account can log in
+// straight away. Only users with `accounts:unlock` permission are let
+// through by the route guard.
+func (h *Handler) Unlock(w http.ResponseWriter, r *http.Request) {
+	userID, err := param.UInt64(r, "userID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		if gen.IsNotFound(err) {
+			respond.Error(w, http.StatusNotFound, ErrUserNotFound)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	actorID, _ := ctx.Value(middleware.KeyID).(uint64)
+	if err := h.lockout.Unlock(ctx, u.Email, actorID); err != nil {
+		slog.ErrorContext(ctx, "unlocking account", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// Sessions lists devices current user is logged in from.
+func (h *Handler) Sessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value(middleware.KeyID).(uint64)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, ErrNotLoggedIn)
+		return
+	}
+
+	sessions, err := h.repo.Sessions(ctx, userID, h.session.Token(ctx))
+	if err != nil {
+		slog.ErrorContext(ctx, "listing sessions", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, SessionResources(sessions))
+}
+
+// RevokeSession logs out one of current user's sessions.
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	err = h.repo.RevokeSession(ctx, userID, chi.URLParam(r, "sessionID"))
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			respond.Error(w, http.StatusNotFound, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+// RevokeOtherSessions logs current user out of every session but this one.
+func (h *Handler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	revoked, err := h.revokeOtherSessions(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, map[string]int64{"revoked": revoked})
+}
+
+// revokeOtherSessions logs user out of every cookie session and refresh token
+// family except the one making this request.
+func (h *Handler) revokeOtherSessions(ctx context.Context, userID uint64) (int64, error) {
+	var currentToken, currentFamily string
+	if middleware.IsAccessToken(ctx) {
+		currentFamily, _ = ctx.Value(middleware.KeyTokenSession).(string)
+	} else {
+		currentToken = h.session.Token(ctx)
+	}
+
+	sessions, err := h.repo.RevokeOtherSessions(ctx, userID, currentToken)
+	if err != nil {
+		return 0, err
+	}
+
+	families, err := h.repo.RevokeRefreshTokens(ctx, userID, currentFamily)
+	if err != nil {
+		return 0, err
+	}
+
+	return sessions + families, nil
+}
+
 // Csrf stores a new csrf token in the database.
 // For a Data modifying requests in <form action="" method="POST"> including PUT and PATCH,
 // this csrf token needs to be attached along in the HTML along.
@@ -154,9 +646,190 @@ func (h *Handler) Csrf(w http.ResponseWriter, r *http.Request) {
 	respond.JSON(w, http.StatusOK, &RespondCsrf{CsrfToken: token})
 }
 
-func NewHandler(session *scs.SessionManager, repo Repo) *Handler {
+// ChangePassword sets a new password for current user. Current password is
+// required so that an unattended logged-in browser is not enough. Other
+// sessions can be logged out at the same time.
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	var req ChangePasswordRequest
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	req.NewPassword = strings.Trim(req.NewPassword, " ")
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if !h.validPassword(w, r, "new_password", req.NewPassword, owner(u)) {
+		return
+	}
+
+	if !h.confirmPassword(w, r, u, req.CurrentPassword) {
+		return
+	}
+
+	hashedPassword, err := password.Hash(req.NewPassword, h.params)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	replaced, err := h.repo.ReplacePassword(ctx, userID, u.Password, hashedPassword)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if !replaced {
+		respond.Error(w, http.StatusConflict, ErrPasswordChanged)
+		return
+	}
+
+	var revoked int64
+	if req.RevokeOtherSessions {
+		revoked, err = h.revokeOtherSessions(ctx, userID)
+		if err != nil {
+			respond.Error(w, http.StatusInternalServerError, nil)
+			return
+		}
+	}
+
+	respond.JSON(w, http.StatusOK, map[string]int64{"revoked": revoked})
+}
+
+// validPassword checks a new password against the password policy. Every
+// rule it breaks is listed under field. Response is written when it returns
+// false.
+func (h *Handler) validPassword(w http.ResponseWriter, r *http.Request, field, plain string, owner password.Owner) bool {
+	ctx := r.Context()
+
+	if h.policy == nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return false
+	}
+
+	violations, err := h.policy.Check(plain, owner)
+	if err != nil {
+		slog.ErrorContext(ctx, "checking password policy", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return false
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	fields := make([]respond.FieldError, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, respond.FieldError{
+			Field:   field,
+			Code:    v.Code,
+			Message: v.Message,
+		})
+	}
+	respond.FieldErrors(w, http.StatusBadRequest, ErrPasswordPolicy, fields)
+
+	return false
+}
+
+func owner(u *gen.User) password.Owner {
+	return password.Owner{
+		Email:      u.Email,
+		FirstName:  u.FirstName,
+		MiddleName: u.MiddleName,
+		LastName:   u.LastName,
+	}
+}
+
+// confirmPassword checks the password of a logged-in user before a sensitive
+// change. Guessing it here is as good as guessing it at login, so the same
+// lockout applies. Response is written when it returns false.
+func (h *Handler) confirmPassword(w http.ResponseWriter, r *http.Request, u *gen.User, plain string) bool {
+	ctx := r.Context()
+	ip := middleware.ClientIP(r)
+
+	wait, err := h.lockout.Wait(ctx, u.Email, ip)
+	if err != nil {
+		slog.ErrorContext(ctx, "checking login lockout", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return false
+	}
+	if wait > 0 {
+		retryAfter(w, wait)
+		respond.Error(w, http.StatusTooManyRequests, ErrTooManyAttempts)
+		return false
+	}
+
+	match, err := argon2id.ComparePasswordAndHash(plain, u.Password)
+	if err != nil || !match {
+		if _, err := h.lockout.Fail(ctx, u.Email, ip); err != nil {
+			slog.ErrorContext(ctx, "recording failed login", "error", err)
+		}
+		respond.Error(w, http.StatusForbidden, ErrWrongPassword)
+		return false
+	}
+
+	return true
+}
+
+// rehash upgrades user's password hash when it was made with weaker argon2id
+// parameters than currently configured. Plain password is only available
+// at login, so this is the only chance to do it. Failure is logged but does
+// not stop the login.
+func (h *Handler) rehash(ctx context.Context, u *gen.User, plain string) {
+	if !password.NeedsRehash(u.Password, h.params) {
+		return
+	}
+
+	hashedPassword, err := password.Hash(plain, h.params)
+	if err != nil {
+		slog.ErrorContext(ctx, "rehashing password", "error", err)
+		return
+	}
+
+	if _, err := h.repo.ReplacePassword(ctx, u.ID, u.Password, hashedPassword); err != nil {
+		slog.ErrorContext(ctx, "saving rehashed password", "error", err)
+	}
+}
+
+// retryAfter tells clients how many seconds to wait, rounded up.
+func retryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+func NewHandler(cfg config.Authentication, session *scs.SessionManager, repo Repo, mail mailer.Mailer, lockout LockoutStore) *Handler {
+	var cipher *crypt.Cipher
+	if cfg.TOTPEncryptionKey != "" {
+		c, err := crypt.New(cfg.TOTPEncryptionKey)
+		if err != nil {
+			slog.Error("invalid TOTP encryption key, two-factor authentication is unavailable", "error", err)
+		}
+		cipher = c
+	}
+
+	policy, err := password.NewPolicy(cfg)
+	if err != nil {
+		slog.Error("invalid password policy, passwords cannot be set", "error", err)
+	}
+
 	return &Handler{
+		cfg:     cfg,
 		repo:    repo,
 		session: session,
+		mail:    mail,
+		lockout: NewLockout(cfg, lockout),
+		cipher:  cipher,
+		params:  password.Params(cfg),
+		policy:  policy,
 	}
 }
@@ -3,7 +3,13 @@ package authentication
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,13 +17,18 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	entsql "entgo.io/ent/dialect/sql"
 	"github.com/alexedwards/argon2id"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/coreos/go-oidc/v3/oidc/oidctest"
 	"github.com/gmhafiz/go8/internal/utility/csrf"
 	"github.com/gmhafiz/scs/v2"
 	"github.com/go-chi/chi/v5"
@@ -26,9 +37,15 @@ import (
 	"github.com/ory/dockertest/v3/docker"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/gmhafiz/go8/config"
 	"github.com/gmhafiz/go8/database"
 	"github.com/gmhafiz/go8/ent/gen"
+	"github.com/gmhafiz/go8/internal/domain/authorization"
 	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+	"github.com/gmhafiz/go8/internal/utility/token"
+	"github.com/gmhafiz/go8/internal/utility/totp"
+	"github.com/gmhafiz/go8/third_party/mailer"
 	"github.com/gmhafiz/go8/third_party/postgresstore"
 )
 
@@ -108,6 +125,14 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		log.Fatalln(err)
 	}
+	_, err = migrator.DB.ExecContext(context.Background(), `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT u.id, r.id FROM users u, roles r WHERE u.email = $1 AND r.name = 'admin'
+		ON CONFLICT DO NOTHING
+		`, "admin@gmhafiz.com")
+	if err != nil {
+		log.Fatalln(err)
+	}
 
 	// We can access database with m.hostAndPort or m.databaseURL
 	// port changes everytime a new docker instance is run
@@ -186,7 +211,20 @@ func TestHandler_RegisterIntegration(t *testing.T) {
 				},
 			},
 			want: want{
-				error:  ErrPasswordLength,
+				error:  ErrPasswordPolicy,
+				status: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "common password",
+			args: args{
+				RegisterRequest: &RegisterRequest{
+					Email:    "common@example.com",
+					Password: "passwordpassword",
+				},
+			},
+			want: want{
+				error:  ErrPasswordPolicy,
 				status: http.StatusBadRequest,
 			},
 		},
@@ -209,9 +247,9 @@ func TestHandler_RegisterIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 
 			router.ServeHTTP(ww, rr)
 
@@ -318,9 +356,9 @@ func TestHandler_LoginIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 
 			router.ServeHTTP(ww, rr)
 
@@ -389,9 +427,9 @@ func TestHandler_ProtectedIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 
 			router.ServeHTTP(ww, rr)
 
@@ -410,8 +448,8 @@ func TestHandler_ProtectedIntegration(t *testing.T) {
 			})
 
 			router = chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
-			RegisterHTTPEndPoints(router, session, repo)
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -477,9 +515,9 @@ func TestHandler_MeIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 
 			router.ServeHTTP(ww, rr)
 
@@ -498,8 +536,8 @@ func TestHandler_MeIntegration(t *testing.T) {
 			})
 
 			router = chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
-			RegisterHTTPEndPoints(router, session, repo)
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -523,6 +561,102 @@ func TestHandler_MeIntegration(t *testing.T) {
 	}
 }
 
+func TestHandler_RememberMeIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type want struct {
+		persistent bool
+		expiresIn  time.Duration
+	}
+	tests := []struct {
+		name       string
+		rememberMe bool
+		want       want
+	}{
+		{
+			name:       "browser session",
+			rememberMe: false,
+			want: want{
+				persistent: false,
+				expiresIn:  10 * time.Minute,
+			},
+		},
+		{
+			name:       "remembered",
+			rememberMe: true,
+			want: want{
+				persistent: true,
+				expiresIn:  7 * 24 * time.Hour,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	session.Cookie.Persist = false
+	repo := NewRepo(client, migrator.DB, session)
+
+	expiry := middleware.NewSessionExpiry(config.Session{
+		Duration:              time.Hour,
+		IdleTimeout:           10 * time.Minute,
+		RememberMeDuration:    30 * 24 * time.Hour,
+		RememberMeIdleTimeout: 7 * 24 * time.Hour,
+		RenewInterval:         time.Hour,
+	}, repo.sessions)
+	defer expiry.Stop()
+
+	hashedPassword, err := argon2id.CreateHash("highEntropyPassword", argon2id.DefaultParams)
+	assert.Nil(t, err)
+
+	_, err = repo.db.ExecContext(context.Background(), `
+		INSERT INTO users (email, password) VALUES ($1, $2)
+		ON CONFLICT (email) DO NOTHING 
+		`, "remember@example.com", hashedPassword)
+	assert.Nil(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, expiry))
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			var buf bytes.Buffer
+			err := json.NewEncoder(&buf).Encode(&LoginRequest{
+				Email:      "remember@example.com",
+				Password:   "highEntropyPassword",
+				RememberMe: tt.rememberMe,
+			})
+			assert.Nil(t, err)
+
+			rr := httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf)
+			ww := httptest.NewRecorder()
+			router.ServeHTTP(ww, rr)
+
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			cookies := ww.Result().Cookies()
+			assert.Len(t, cookies, 1)
+			assert.Equal(t, tt.want.persistent, cookies[0].MaxAge > 0)
+
+			rr = httptest.NewRequest(http.MethodGet, "/api/v1/restricted/me", nil)
+			rr.AddCookie(&http.Cookie{Name: sessionName, Value: cookies[0].Value})
+			ww = httptest.NewRecorder()
+			router.ServeHTTP(ww, rr)
+
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			var res struct {
+				ExpiresAt time.Time `json:"expires_at"`
+			}
+			err = json.NewDecoder(ww.Body).Decode(&res)
+			assert.Nil(t, err)
+			assert.WithinDuration(t, time.Now().Add(tt.want.expiresIn), res.ExpiresAt, time.Minute)
+		})
+	}
+}
+
 func TestHandler_LogoutIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -581,9 +715,9 @@ func TestHandler_LogoutIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -680,9 +814,10 @@ func TestHandler_Force_LogoutIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
+			router.Use(middleware.Permissions(authorization.NewRepo(client)))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -720,6 +855,18 @@ func TestHandler_Force_LogoutIntegration(t *testing.T) {
 			adminToken, err := extractToken(ww.Header().Get("Set-Cookie"))
 			assert.Nil(t, err)
 
+			// A normal user is not allowed to log out other users
+			rr = httptest.NewRequest(http.MethodPost, "/api/v1/restricted/logout/1", nil)
+			ww = httptest.NewRecorder()
+			rr.AddCookie(&http.Cookie{
+				Name:  sessionName,
+				Value: token,
+			})
+
+			router.ServeHTTP(ww, rr)
+
+			assert.Equal(t, http.StatusForbidden, ww.Code)
+
 			// ID 2 is our normal user to be forced-log out
 			rr = httptest.NewRequest(http.MethodPost, "/api/v1/restricted/logout/2", nil)
 			ww = httptest.NewRecorder()
@@ -809,9 +956,9 @@ func TestHandler_Csrf_Valid_TokenIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -841,21 +988,202 @@ func TestHandler_Csrf_Valid_TokenIntegration(t *testing.T) {
 
 			assert.NotNil(t, resp.CsrfToken)
 
-			validity := csrf.ValidToken(context.Background(), migrator.DB, resp.CsrfToken)
+			validity := csrf.ValidToken(context.Background(), repo.sessions, resp.CsrfToken)
 			assert.Equal(t, tt.want.csrfTokenValidity, validity)
 
 			// csrf token does not get deleted yet
-			validity = csrf.ValidToken(context.Background(), migrator.DB, resp.CsrfToken)
+			validity = csrf.ValidToken(context.Background(), repo.sessions, resp.CsrfToken)
 			assert.Equal(t, tt.want.csrfTokenValidity, validity)
 
 			time.Sleep(101 * time.Millisecond)
 
-			validity = csrf.ValidToken(context.Background(), migrator.DB, resp.CsrfToken)
+			validity = csrf.ValidToken(context.Background(), repo.sessions, resp.CsrfToken)
 			assert.Equal(t, false, validity)
 		})
 	}
 }
 
+func TestHandler_CSRFMiddlewareIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		mode      string
+		path      string
+		exempt    []string
+		loggedIn  bool
+		token     string // own, other or empty for none
+		form      bool
+		bearer    string // key, junk or empty for none
+		reuseOnce bool
+	}
+	type want struct {
+		status      int
+		reuseStatus int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "missing token",
+			args: args{mode: config.CSRFModeSession, path: "/write", loggedIn: true},
+			want: want{status: http.StatusForbidden},
+		},
+		{
+			name: "per-session token can be reused",
+			args: args{mode: config.CSRFModeSession, path: "/write", loggedIn: true, token: "own", reuseOnce: true},
+			want: want{status: http.StatusOK, reuseStatus: http.StatusOK},
+		},
+		{
+			name: "single-use token cannot be reused",
+			args: args{mode: config.CSRFModeSingleUse, path: "/write", loggedIn: true, token: "own", reuseOnce: true},
+			want: want{status: http.StatusOK, reuseStatus: http.StatusForbidden},
+		},
+		{
+			name: "token issued to another user",
+			args: args{mode: config.CSRFModeSession, path: "/write", loggedIn: true, token: "other"},
+			want: want{status: http.StatusForbidden},
+		},
+		{
+			name: "token in form field",
+			args: args{mode: config.CSRFModeSession, path: "/write", loggedIn: true, token: "own", form: true},
+			want: want{status: http.StatusOK},
+		},
+		{
+			name: "exempt path",
+			args: args{mode: config.CSRFModeSession, path: "/hooks/incoming", exempt: []string{"/hooks/*"}, loggedIn: true},
+			want: want{status: http.StatusOK},
+		},
+		{
+			name: "api key is not checked",
+			args: args{mode: config.CSRFModeSession, path: "/write", loggedIn: true, bearer: "key"},
+			want: want{status: http.StatusOK},
+		},
+		{
+			name: "bearer that authenticates nobody is checked",
+			args: args{mode: config.CSRFModeSession, path: "/write", loggedIn: true, bearer: "junk"},
+			want: want{status: http.StatusForbidden},
+		},
+		{
+			name: "not logged in",
+			args: args{mode: config.CSRFModeSession, path: "/write"},
+			want: want{status: http.StatusOK},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+
+	hashedPassword, err := argon2id.CreateHash("highEntropyPassword", argon2id.DefaultParams)
+	assert.Nil(t, err)
+
+	for _, email := range []string{"csrf@example.com", "csrf-other@example.com"} {
+		_, err = repo.db.ExecContext(context.Background(), `
+			INSERT INTO users (email, password) VALUES ($1, $2)
+			ON CONFLICT (email) DO NOTHING
+			`, email, hashedPassword)
+		assert.Nil(t, err)
+	}
+
+	u, err := repo.FindByEmail(context.Background(), "csrf@example.com")
+	assert.Nil(t, err)
+	_, apiKey, err := repo.CreateAPIKey(context.Background(), u.ID, "csrf", []string{}, nil)
+	assert.Nil(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			router.Use(middleware.APIKey(repo))
+			router.Use(middleware.CSRF(repo.sessions, config.CSRF{
+				Mode:      tt.args.mode,
+				Header:    "X-CSRF-Token",
+				FormField: "csrf_token",
+				Exempt:    tt.args.exempt,
+			}))
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+			ok := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}
+			router.Post("/write", ok)
+			router.Post("/hooks/incoming", ok)
+
+			login := func(email string) (cookie, csrfToken string) {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: email, Password: "highEntropyPassword"})
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+				assert.Equal(t, http.StatusOK, ww.Code)
+				cookie, err = extractToken(ww.Header().Get("Set-Cookie"))
+				assert.Nil(t, err)
+
+				rr := httptest.NewRequest(http.MethodGet, "/api/v1/restricted/csrf", nil)
+				rr.AddCookie(&http.Cookie{Name: sessionName, Value: cookie})
+				ww = httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				assert.Equal(t, http.StatusOK, ww.Code)
+
+				var resp RespondCsrf
+				err = json.NewDecoder(ww.Body).Decode(&resp)
+				assert.Nil(t, err)
+
+				return cookie, resp.CsrfToken
+			}
+
+			var cookie, token string
+			if tt.args.loggedIn {
+				cookie, token = login("csrf@example.com")
+			}
+			if tt.args.token == "other" {
+				_, token = login("csrf-other@example.com")
+			}
+			if tt.args.token == "" {
+				token = ""
+			}
+
+			send := func() int {
+				var rr *http.Request
+				if tt.args.form {
+					form := url.Values{}
+					form.Set("csrf_token", token)
+					rr = httptest.NewRequest(http.MethodPost, tt.args.path, strings.NewReader(form.Encode()))
+					rr.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				} else {
+					rr = httptest.NewRequest(http.MethodPost, tt.args.path, nil)
+					if token != "" {
+						rr.Header.Set("X-CSRF-Token", token)
+					}
+				}
+				if cookie != "" {
+					rr.AddCookie(&http.Cookie{Name: sessionName, Value: cookie})
+				}
+				switch tt.args.bearer {
+				case "key":
+					rr.Header.Set("Authorization", "Bearer "+apiKey)
+				case "junk":
+					// Looks like an access token, but nothing verifies it.
+					rr.Header.Set("Authorization", "Bearer a.b.c")
+				}
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				return ww.Code
+			}
+
+			assert.Equal(t, tt.want.status, send())
+			if tt.args.reuseOnce {
+				assert.Equal(t, tt.want.reuseStatus, send())
+			}
+		})
+	}
+}
+
 func TestHandler_Csrf_Valid_And_Delete_TokenIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -918,9 +1246,9 @@ func TestHandler_Csrf_Valid_And_Delete_TokenIntegration(t *testing.T) {
 			ww := httptest.NewRecorder()
 
 			router := chi.NewRouter()
-			router.Use(middleware.LoadAndSave(session))
+			router.Use(middleware.LoadAndSave(session, nil))
 
-			RegisterHTTPEndPoints(router, session, repo)
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
 			router.ServeHTTP(ww, rr)
 
 			assert.Equal(t, tt.want.status, ww.Code)
@@ -950,50 +1278,2141 @@ func TestHandler_Csrf_Valid_And_Delete_TokenIntegration(t *testing.T) {
 
 			assert.NotNil(t, resp.CsrfToken)
 
-			err = csrf.ValidAndDeleteToken(context.Background(), migrator.DB, resp.CsrfToken)
+			err = csrf.ValidAndDeleteToken(context.Background(), repo.sessions, resp.CsrfToken)
 			assert.Nil(t, err)
 
 			// at this point, the csrf token would have been deleted
-			err = csrf.ValidAndDeleteToken(context.Background(), migrator.DB, resp.CsrfToken)
+			err = csrf.ValidAndDeleteToken(context.Background(), repo.sessions, resp.CsrfToken)
 			assert.NotNil(t, err)
 		})
 	}
 }
 
-func extractToken(cookie string) (string, error) {
-	parts := strings.Split(cookie, ";")
-	if len(parts) == 0 {
-		return "", errors.New("invalid cookie")
+func TestHandler_VerifyIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
 	}
 
-	for _, part := range parts {
-		keyVal := strings.Split(part, "=")
-		if len(keyVal) != 2 {
-			return "", errors.New("invalid cookie")
-		}
-		if keyVal[0] == sessionName {
-			return keyVal[1], nil
-		}
+	type want struct {
+		registerStatus     int
+		loginBeforeStatus  int
+		verifyStatus       int
+		verifyReusedStatus int
+		loginAfterStatus   int
+	}
+	tests := []struct {
+		name string
+		args *RegisterRequest
+		want want
+	}{
+		{
+			name: "unverified user cannot log in until verified",
+			args: &RegisterRequest{
+				Email:    "verify@example.com",
+				Password: "highEntropyPassword",
+			},
+			want: want{
+				registerStatus:     http.StatusCreated,
+				loginBeforeStatus:  http.StatusForbidden,
+				verifyStatus:       http.StatusOK,
+				verifyReusedStatus: http.StatusBadRequest,
+				loginAfterStatus:   http.StatusOK,
+			},
+		},
 	}
 
-	return "", errors.New("invalid cookie")
-}
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	cfg := config.Authentication{
+		RequireVerification: true,
+		VerificationExpiry:  1 * time.Hour,
+		VerificationURL:     "http://localhost:3080/api/v1/verify",
+	}
 
-func dbClient() *gen.Client {
-	drv := entsql.OpenDB(DBDriver, migrator.DB)
-	return gen.NewClient(gen.Driver(drv))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mail := &fakeMailer{}
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, cfg, session, repo, mail, NewPostgresLockoutStore(migrator.DB))
+
+			var buf bytes.Buffer
+			err := json.NewEncoder(&buf).Encode(tt.args)
+			assert.Nil(t, err)
+
+			ww := httptest.NewRecorder()
+			router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/register", &buf))
+			assert.Equal(t, tt.want.registerStatus, ww.Code)
+
+			login := func() int {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: tt.args.Email, Password: tt.args.Password})
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+				return ww.Code
+			}
+
+			assert.Equal(t, tt.want.loginBeforeStatus, login())
+
+			token := mail.token(t, "token")
+
+			ww = httptest.NewRecorder()
+			router.ServeHTTP(ww, httptest.NewRequest(http.MethodGet, "/api/v1/verify?token="+token, nil))
+			assert.Equal(t, tt.want.verifyStatus, ww.Code)
+
+			ww = httptest.NewRecorder()
+			router.ServeHTTP(ww, httptest.NewRequest(http.MethodGet, "/api/v1/verify?token="+token, nil))
+			assert.Equal(t, tt.want.verifyReusedStatus, ww.Code)
+
+			assert.Equal(t, tt.want.loginAfterStatus, login())
+		})
+	}
 }
 
-func newSession(db *sql.DB, duration time.Duration) *scs.SessionManager {
-	manager := scs.New()
-	manager.Store = postgresstore.New(db)
-	manager.CtxStore = postgresstore.New(db)
-	manager.Lifetime = duration
-	manager.Cookie.Name = sessionName
-	manager.Cookie.HttpOnly = false
-	manager.Cookie.Path = "/"
-	manager.Cookie.SameSite = http.SameSiteLaxMode
-	manager.Cookie.Secure = false
+func TestHandler_ResetPasswordIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
 
-	return manager
+	type args struct {
+		email       string
+		oldPassword string
+		newPassword string
+	}
+	type want struct {
+		forgotStatus        int
+		forgotUnknownStatus int
+		resetStatus         int
+		oldSessionStatus    int
+		oldPasswordStatus   int
+		newPasswordStatus   int
+		reusedTokenStatus   int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "reset revokes sessions and old password",
+			args: args{
+				email:       "reset@example.com",
+				oldPassword: "highEntropyPassword",
+				newPassword: "anotherHighEntropyPassword",
+			},
+			want: want{
+				forgotStatus:        http.StatusAccepted,
+				forgotUnknownStatus: http.StatusAccepted,
+				resetStatus:         http.StatusOK,
+				oldSessionStatus:    http.StatusUnauthorized,
+				oldPasswordStatus:   http.StatusUnauthorized,
+				newPasswordStatus:   http.StatusOK,
+				reusedTokenStatus:   http.StatusBadRequest,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	cfg := config.Authentication{
+		PasswordResetExpiry: 1 * time.Hour,
+		PasswordResetURL:    "http://localhost:3000/reset-password",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash(tt.args.oldPassword, argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			_, err = repo.db.ExecContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				ON CONFLICT (email) DO NOTHING
+				`, tt.args.email, hashedPassword)
+			assert.Nil(t, err)
+
+			mail := &fakeMailer{}
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, cfg, session, repo, mail, NewPostgresLockoutStore(migrator.DB))
+
+			post := func(path string, body any) *httptest.ResponseRecorder {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(body)
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, path, &buf))
+				return ww
+			}
+
+			ww := post("/api/v1/login", &LoginRequest{Email: tt.args.email, Password: tt.args.oldPassword})
+			assert.Equal(t, http.StatusOK, ww.Code)
+			sessionToken, err := extractToken(ww.Header().Get("Set-Cookie"))
+			assert.Nil(t, err)
+
+			ww = post("/api/v1/password/forgot", &ForgotPasswordRequest{Email: "unknown@example.com"})
+			assert.Equal(t, tt.want.forgotUnknownStatus, ww.Code)
+			unknownBody := ww.Body.String()
+
+			ww = post("/api/v1/password/forgot", &ForgotPasswordRequest{Email: tt.args.email})
+			assert.Equal(t, tt.want.forgotStatus, ww.Code)
+			assert.Equal(t, unknownBody, ww.Body.String())
+
+			token := mail.token(t, "token")
+
+			ww = post("/api/v1/password/reset", &ResetPasswordRequest{Token: token, Password: tt.args.newPassword})
+			assert.Equal(t, tt.want.resetStatus, ww.Code)
+
+			rr := httptest.NewRequest(http.MethodGet, "/api/v1/restricted", nil)
+			rr.AddCookie(&http.Cookie{Name: sessionName, Value: sessionToken})
+			ww = httptest.NewRecorder()
+			router.ServeHTTP(ww, rr)
+			assert.Equal(t, tt.want.oldSessionStatus, ww.Code)
+
+			ww = post("/api/v1/login", &LoginRequest{Email: tt.args.email, Password: tt.args.oldPassword})
+			assert.Equal(t, tt.want.oldPasswordStatus, ww.Code)
+
+			ww = post("/api/v1/login", &LoginRequest{Email: tt.args.email, Password: tt.args.newPassword})
+			assert.Equal(t, tt.want.newPasswordStatus, ww.Code)
+
+			ww = post("/api/v1/password/reset", &ResetPasswordRequest{Token: token, Password: tt.args.newPassword})
+			assert.Equal(t, tt.want.reusedTokenStatus, ww.Code)
+		})
+	}
+}
+
+func TestHandler_APIKeyIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email    string
+		password string
+		scopes   []string
+	}
+	type want struct {
+		createStatus        int
+		listStatus          int
+		meStatus            int
+		createWithKeyStatus int
+		revokeStatus        int
+		revokedKeyStatus    int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "create, use and revoke",
+			args: args{
+				email:    "apikey@example.com",
+				password: "highEntropyPassword",
+				scopes:   []string{"books:write"},
+			},
+			want: want{
+				createStatus:        http.StatusCreated,
+				listStatus:          http.StatusOK,
+				meStatus:            http.StatusOK,
+				createWithKeyStatus: http.StatusForbidden,
+				revokeStatus:        http.StatusNoContent,
+				revokedKeyStatus:    http.StatusUnauthorized,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	cfg := config.Authentication{APIKeyExpiry: 1 * time.Hour}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash(tt.args.password, argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			var userID uint64
+			err = repo.db.QueryRowContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				RETURNING id
+				`, tt.args.email, hashedPassword).Scan(&userID)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			router.Use(middleware.APIKey(repo))
+			RegisterHTTPEndPoints(router, cfg, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			do := func(method, path string, body any, auth func(r *http.Request)) *httptest.ResponseRecorder {
+				var buf bytes.Buffer
+				if body != nil {
+					err := json.NewEncoder(&buf).Encode(body)
+					assert.Nil(t, err)
+				}
+
+				rr := httptest.NewRequest(method, path, &buf)
+				auth(rr)
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				return ww
+			}
+
+			ww := do(http.MethodPost, "/api/v1/login", &LoginRequest{Email: tt.args.email, Password: tt.args.password}, func(*http.Request) {})
+			assert.Equal(t, http.StatusOK, ww.Code)
+			sessionToken, err := extractToken(ww.Header().Get("Set-Cookie"))
+			assert.Nil(t, err)
+
+			withCookie := func(r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: sessionName, Value: sessionToken})
+			}
+
+			ww = do(http.MethodPost, "/api/v1/restricted/api-keys", &CreateAPIKeyRequest{Name: "ci", Scopes: tt.args.scopes}, withCookie)
+			assert.Equal(t, tt.want.createStatus, ww.Code)
+
+			var created CreatedAPIKeyResponse
+			err = json.NewDecoder(ww.Body).Decode(&created)
+			assert.Nil(t, err)
+			assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+			assert.Equal(t, tt.args.scopes, created.Scopes)
+			assert.NotNil(t, created.ExpiresAt)
+
+			withKey := func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+created.Key)
+			}
+
+			ww = do(http.MethodGet, "/api/v1/restricted/api-keys", nil, withCookie)
+			assert.Equal(t, tt.want.listStatus, ww.Code)
+			assert.NotContains(t, ww.Body.String(), created.Key)
+
+			var listed []APIKeyResponse
+			err = json.NewDecoder(ww.Body).Decode(&listed)
+			assert.Nil(t, err)
+			assert.Len(t, listed, 1)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", nil, withKey)
+			assert.Equal(t, tt.want.meStatus, ww.Code)
+
+			var me map[string]uint64
+			err = json.NewDecoder(ww.Body).Decode(&me)
+			assert.Nil(t, err)
+			assert.Equal(t, userID, me["user_id"])
+
+			ww = do(http.MethodPost, "/api/v1/restricted/api-keys", &CreateAPIKeyRequest{Name: "minted"}, withKey)
+			assert.Equal(t, tt.want.createWithKeyStatus, ww.Code)
+
+			ww = do(http.MethodDelete, fmt.Sprintf("/api/v1/restricted/api-keys/%d", created.ID), nil, withCookie)
+			assert.Equal(t, tt.want.revokeStatus, ww.Code)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", nil, withKey)
+			assert.Equal(t, tt.want.revokedKeyStatus, ww.Code)
+		})
+	}
+}
+
+func TestHandler_DisabledAPIKeyIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type want struct {
+		enabledStatus  int
+		disabledStatus int
+	}
+	tests := []struct {
+		name  string
+		email string
+		want  want
+	}{
+		{
+			name:  "api key of a disabled user cannot write",
+			email: "disabled-apikey@example.com",
+			want: want{
+				enabledStatus:  http.StatusCreated,
+				disabledStatus: http.StatusForbidden,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	cfg := config.Authentication{APIKeyExpiry: 1 * time.Hour}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash("highEntropyPassword", argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			var userID uint64
+			err = repo.db.QueryRowContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				RETURNING id
+				`, tt.email, hashedPassword).Scan(&userID)
+			assert.Nil(t, err)
+			_, err = repo.db.ExecContext(context.Background(), `
+				INSERT INTO user_roles (user_id, role_id)
+				SELECT $1, r.id FROM roles r WHERE r.name = 'editor'
+				`, userID)
+			assert.Nil(t, err)
+
+			_, key, err := repo.CreateAPIKey(context.Background(), userID, "ci", []string{"books:write"}, nil)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			router.Use(middleware.APIKey(repo))
+			router.Use(middleware.Permissions(authorization.NewRepo(client)))
+			router.Use(middleware.Accounts(repo))
+			RegisterHTTPEndPoints(router, cfg, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+			// Book writes are guarded by permission alone, like in
+			// book/handler.
+			router.With(middleware.RequirePermission("books:write")).
+				Post("/api/v1/book", func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusCreated)
+				})
+
+			createBook := func() *httptest.ResponseRecorder {
+				rr := httptest.NewRequest(http.MethodPost, "/api/v1/book", nil)
+				rr.Header.Set("Authorization", "Bearer "+key)
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				return ww
+			}
+
+			ww := createBook()
+			assert.Equal(t, tt.want.enabledStatus, ww.Code)
+
+			err = repo.DisableUser(context.Background(), userID)
+			assert.Nil(t, err)
+
+			ww = createBook()
+			assert.Equal(t, tt.want.disabledStatus, ww.Code)
+			assert.Contains(t, ww.Body.String(), middleware.ErrAccountDisabled.Error())
+		})
+	}
+}
+
+func TestHandler_TwoFactorIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email    string
+		password string
+	}
+	type want struct {
+		enrollStatus       int
+		confirmStatus      int
+		loginStatus        int
+		pendingMeStatus    int
+		wrongCodeStatus    int
+		replayStatus       int
+		recoveryStatus     int
+		reusedRecovery     int
+		wrongDisableStatus int
+		disableStatus      int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "enroll, login with second factor and disable",
+			args: args{
+				email:    "2fa@example.com",
+				password: "highEntropyPassword",
+			},
+			want: want{
+				enrollStatus:       http.StatusOK,
+				confirmStatus:      http.StatusOK,
+				loginStatus:        http.StatusAccepted,
+				pendingMeStatus:    http.StatusUnauthorized,
+				wrongCodeStatus:    http.StatusUnauthorized,
+				replayStatus:       http.StatusUnauthorized,
+				recoveryStatus:     http.StatusOK,
+				reusedRecovery:     http.StatusUnauthorized,
+				wrongDisableStatus: http.StatusForbidden,
+				disableStatus:      http.StatusOK,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	cfg := config.Authentication{
+		TOTPIssuer:             "go8",
+		TOTPEncryptionKey:      base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+		TwoFactorPendingExpiry: 5 * time.Minute,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash(tt.args.password, argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			_, err = repo.db.ExecContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				`, tt.args.email, hashedPassword)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, cfg, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			var cookie string
+			do := func(method, path string, body any) *httptest.ResponseRecorder {
+				var buf bytes.Buffer
+				if body != nil {
+					err := json.NewEncoder(&buf).Encode(body)
+					assert.Nil(t, err)
+				}
+
+				rr := httptest.NewRequest(method, path, &buf)
+				if cookie != "" {
+					rr.AddCookie(&http.Cookie{Name: sessionName, Value: cookie})
+				}
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+
+				if token, err := extractToken(ww.Header().Get("Set-Cookie")); err == nil {
+					cookie = token
+				}
+				return ww
+			}
+			login := func() *httptest.ResponseRecorder {
+				cookie = ""
+				return do(http.MethodPost, "/api/v1/login", &LoginRequest{Email: tt.args.email, Password: tt.args.password})
+			}
+
+			ww := login()
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/restricted/2fa/enroll", nil)
+			assert.Equal(t, tt.want.enrollStatus, ww.Code)
+
+			var enrolled TwoFactorEnrollResponse
+			err = json.NewDecoder(ww.Body).Decode(&enrolled)
+			assert.Nil(t, err)
+			assert.True(t, strings.HasPrefix(enrolled.URI, "otpauth://totp/"))
+
+			secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrolled.Secret)
+			assert.Nil(t, err)
+
+			var stored []byte
+			err = repo.db.QueryRowContext(context.Background(), `SELECT totp_secret FROM users WHERE email = $1`, tt.args.email).Scan(&stored)
+			assert.Nil(t, err)
+			assert.NotContains(t, string(stored), string(secret))
+
+			code := totp.Code(secret, time.Now())
+			ww = do(http.MethodPost, "/api/v1/restricted/2fa/confirm", &TwoFactorCodeRequest{Code: code})
+			assert.Equal(t, tt.want.confirmStatus, ww.Code)
+
+			var recovery RecoveryCodesResponse
+			err = json.NewDecoder(ww.Body).Decode(&recovery)
+			assert.Nil(t, err)
+			assert.Len(t, recovery.RecoveryCodes, recoveryCodeCount)
+
+			ww = login()
+			assert.Equal(t, tt.want.loginStatus, ww.Code)
+			assert.Contains(t, ww.Body.String(), `"two_factor_required":true`)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", nil)
+			assert.Equal(t, tt.want.pendingMeStatus, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/login/2fa", &TwoFactorLoginRequest{Code: "000000"})
+			assert.Equal(t, tt.want.wrongCodeStatus, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/login/2fa", &TwoFactorLoginRequest{Code: code})
+			assert.Equal(t, tt.want.replayStatus, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/login/2fa", &TwoFactorLoginRequest{RecoveryCode: recovery.RecoveryCodes[0]})
+			assert.Equal(t, tt.want.recoveryStatus, ww.Code)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", nil)
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/restricted/2fa/disable", &DisableTwoFactorRequest{Password: "wrong password"})
+			assert.Equal(t, tt.want.wrongDisableStatus, ww.Code)
+
+			ww = login()
+			assert.Equal(t, tt.want.loginStatus, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/login/2fa", &TwoFactorLoginRequest{RecoveryCode: recovery.RecoveryCodes[0]})
+			assert.Equal(t, tt.want.reusedRecovery, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/login/2fa", &TwoFactorLoginRequest{RecoveryCode: recovery.RecoveryCodes[1]})
+			assert.Equal(t, tt.want.recoveryStatus, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/restricted/2fa/disable", &DisableTwoFactorRequest{Password: tt.args.password})
+			assert.Equal(t, tt.want.disableStatus, ww.Code)
+
+			ww = login()
+			assert.Equal(t, http.StatusOK, ww.Code)
+		})
+	}
+}
+
+func TestHandler_LockoutIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email    string
+		password string
+		failures int
+	}
+	type want struct {
+		failedStatus   int
+		lockedStatus   int
+		unlockStatus   int
+		unlockedStatus int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "locks after threshold and admin unlocks",
+			args: args{
+				email:    "lockout@example.com",
+				password: "highEntropyPassword",
+				failures: 3,
+			},
+			want: want{
+				failedStatus:   http.StatusUnauthorized,
+				lockedStatus:   http.StatusTooManyRequests,
+				unlockStatus:   http.StatusOK,
+				unlockedStatus: http.StatusOK,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	cfg := config.Authentication{
+		LockoutThreshold:      3,
+		LockoutThresholdPerIP: 100,
+		LockoutDuration:       1 * time.Hour,
+		LockoutBackoff:        1 * time.Millisecond,
+		LockoutBackoffMax:     1 * time.Millisecond,
+		LockoutWindow:         1 * time.Hour,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash(tt.args.password, argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			var userID uint64
+			err = repo.db.QueryRowContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				RETURNING id
+				`, tt.args.email, hashedPassword).Scan(&userID)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			router.Use(middleware.Permissions(authorization.NewRepo(client)))
+			RegisterHTTPEndPoints(router, cfg, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			login := func(email, password string) *httptest.ResponseRecorder {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: email, Password: password})
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+				return ww
+			}
+
+			var ww *httptest.ResponseRecorder
+			for range tt.args.failures {
+				time.Sleep(5 * time.Millisecond)
+				ww = login(tt.args.email, "wrongPassword")
+				assert.Equal(t, tt.want.failedStatus, ww.Code)
+			}
+			assert.NotEmpty(t, ww.Header().Get("Retry-After"))
+
+			ww = login(tt.args.email, tt.args.password)
+			assert.Equal(t, tt.want.lockedStatus, ww.Code)
+			assert.Equal(t, "3600", ww.Header().Get("Retry-After"))
+
+			ww = login("admin@gmhafiz.com", "highEntropyPassword")
+			assert.Equal(t, http.StatusOK, ww.Code)
+			adminToken, err := extractToken(ww.Header().Get("Set-Cookie"))
+			assert.Nil(t, err)
+
+			rr := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/unlock", userID), nil)
+			rr.AddCookie(&http.Cookie{Name: sessionName, Value: adminToken})
+			ww = httptest.NewRecorder()
+			router.ServeHTTP(ww, rr)
+			assert.Equal(t, tt.want.unlockStatus, ww.Code)
+
+			ww = login(tt.args.email, tt.args.password)
+			assert.Equal(t, tt.want.unlockedStatus, ww.Code)
+		})
+	}
+}
+
+func TestHandler_TwoFactorLockoutIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email    string
+		password string
+		logins   int
+	}
+	type want struct {
+		loginStatus     int
+		wrongCodeStatus int
+		lockedStatus    int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "wrong codes across logins lock the account",
+			args: args{
+				email:    "2fa-lockout@example.com",
+				password: "highEntropyPassword",
+				logins:   3,
+			},
+			want: want{
+				loginStatus:     http.StatusAccepted,
+				wrongCodeStatus: http.StatusUnauthorized,
+				lockedStatus:    http.StatusTooManyRequests,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	cfg := config.Authentication{
+		TOTPIssuer:             "go8",
+		TOTPEncryptionKey:      base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+		TwoFactorPendingExpiry: 5 * time.Minute,
+		LockoutThreshold:       3,
+		LockoutThresholdPerIP:  100,
+		LockoutDuration:        1 * time.Hour,
+		LockoutBackoff:         1 * time.Millisecond,
+		LockoutBackoffMax:      1 * time.Millisecond,
+		LockoutWindow:          1 * time.Hour,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash(tt.args.password, argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			_, err = repo.db.ExecContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				`, tt.args.email, hashedPassword)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, cfg, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			var cookie string
+			do := func(method, path string, body any) *httptest.ResponseRecorder {
+				var buf bytes.Buffer
+				if body != nil {
+					err := json.NewEncoder(&buf).Encode(body)
+					assert.Nil(t, err)
+				}
+
+				rr := httptest.NewRequest(method, path, &buf)
+				if cookie != "" {
+					rr.AddCookie(&http.Cookie{Name: sessionName, Value: cookie})
+				}
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+
+				if token, err := extractToken(ww.Header().Get("Set-Cookie")); err == nil {
+					cookie = token
+				}
+				return ww
+			}
+			login := func() *httptest.ResponseRecorder {
+				cookie = ""
+				return do(http.MethodPost, "/api/v1/login", &LoginRequest{Email: tt.args.email, Password: tt.args.password})
+			}
+
+			ww := login()
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			ww = do(http.MethodPost, "/api/v1/restricted/2fa/enroll", nil)
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			var enrolled TwoFactorEnrollResponse
+			err = json.NewDecoder(ww.Body).Decode(&enrolled)
+			assert.Nil(t, err)
+
+			secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrolled.Secret)
+			assert.Nil(t, err)
+
+			ww = do(http.MethodPost, "/api/v1/restricted/2fa/confirm", &TwoFactorCodeRequest{Code: totp.Code(secret, time.Now())})
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			// A correct password alone must not forget wrong codes of
+			// previous attempts.
+			for range tt.args.logins {
+				time.Sleep(5 * time.Millisecond)
+				ww = login()
+				assert.Equal(t, tt.want.loginStatus, ww.Code)
+
+				ww = do(http.MethodPost, "/api/v1/login/2fa", &TwoFactorLoginRequest{Code: "000000"})
+				assert.Equal(t, tt.want.wrongCodeStatus, ww.Code)
+			}
+			assert.Equal(t, "3600", ww.Header().Get("Retry-After"))
+
+			ww = login()
+			assert.Equal(t, tt.want.lockedStatus, ww.Code)
+		})
+	}
+}
+
+func TestHandler_SessionsIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email    string
+		password string
+	}
+	type want struct {
+		listStatus         int
+		revokeStatus       int
+		revokedStatus      int
+		revokeOthersStatus int
+		unknownStatus      int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "list and revoke sessions",
+			args: args{
+				email:    "sessions@example.com",
+				password: "highEntropyPassword",
+			},
+			want: want{
+				listStatus:         http.StatusOK,
+				revokeStatus:       http.StatusNoContent,
+				revokedStatus:      http.StatusUnauthorized,
+				revokeOthersStatus: http.StatusOK,
+				unknownStatus:      http.StatusNotFound,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash(tt.args.password, argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			_, err = repo.db.ExecContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				`, tt.args.email, hashedPassword)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			login := func(userAgent string) string {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: tt.args.email, Password: tt.args.password})
+				assert.Nil(t, err)
+
+				rr := httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf)
+				rr.Header.Set("User-Agent", userAgent)
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				assert.Equal(t, http.StatusOK, ww.Code)
+
+				token, err := extractToken(ww.Header().Get("Set-Cookie"))
+				assert.Nil(t, err)
+				return token
+			}
+			do := func(method, path, token string) *httptest.ResponseRecorder {
+				rr := httptest.NewRequest(method, path, nil)
+				rr.AddCookie(&http.Cookie{Name: sessionName, Value: token})
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				return ww
+			}
+
+			laptop := login("laptop")
+			phone := login("phone")
+
+			ww := do(http.MethodGet, "/api/v1/restricted/sessions", laptop)
+			assert.Equal(t, tt.want.listStatus, ww.Code)
+			assert.NotContains(t, ww.Body.String(), laptop)
+			assert.NotContains(t, ww.Body.String(), phone)
+
+			var sessions []SessionResponse
+			err = json.NewDecoder(ww.Body).Decode(&sessions)
+			assert.Nil(t, err)
+			assert.Len(t, sessions, 2)
+
+			var phoneID string
+			for _, s := range sessions {
+				switch s.UserAgent {
+				case "laptop":
+					assert.True(t, s.Current)
+				case "phone":
+					assert.False(t, s.Current)
+					phoneID = s.ID
+				}
+			}
+			assert.NotEmpty(t, phoneID)
+
+			ww = do(http.MethodDelete, "/api/v1/restricted/sessions/"+phoneID, laptop)
+			assert.Equal(t, tt.want.revokeStatus, ww.Code)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", phone)
+			assert.Equal(t, tt.want.revokedStatus, ww.Code)
+
+			ww = do(http.MethodDelete, "/api/v1/restricted/sessions/"+phoneID, laptop)
+			assert.Equal(t, tt.want.unknownStatus, ww.Code)
+
+			tablet := login("tablet")
+
+			ww = do(http.MethodDelete, "/api/v1/restricted/sessions/others", laptop)
+			assert.Equal(t, tt.want.revokeOthersStatus, ww.Code)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", tablet)
+			assert.Equal(t, tt.want.revokedStatus, ww.Code)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", laptop)
+			assert.Equal(t, http.StatusOK, ww.Code)
+		})
+	}
+}
+
+func TestHandler_ChangePasswordIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email string
+		*ChangePasswordRequest
+	}
+	type want struct {
+		status          int
+		otherStatus     int
+		newLoginStatus  int
+		oldLoginStatus  int
+		revokedSessions int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "change and revoke other sessions",
+			args: args{
+				email: "change-password@example.com",
+				ChangePasswordRequest: &ChangePasswordRequest{
+					CurrentPassword:     "highEntropyPassword",
+					NewPassword:         "anotherHighEntropyPassword",
+					RevokeOtherSessions: true,
+				},
+			},
+			want: want{
+				status:          http.StatusOK,
+				otherStatus:     http.StatusUnauthorized,
+				newLoginStatus:  http.StatusOK,
+				oldLoginStatus:  http.StatusUnauthorized,
+				revokedSessions: 1,
+			},
+		},
+		{
+			name: "change and keep other sessions",
+			args: args{
+				email: "change-password-keep@example.com",
+				ChangePasswordRequest: &ChangePasswordRequest{
+					CurrentPassword: "highEntropyPassword",
+					NewPassword:     "anotherHighEntropyPassword",
+				},
+			},
+			want: want{
+				status:         http.StatusOK,
+				otherStatus:    http.StatusOK,
+				newLoginStatus: http.StatusOK,
+				oldLoginStatus: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "wrong current password",
+			args: args{
+				email: "change-password-wrong@example.com",
+				ChangePasswordRequest: &ChangePasswordRequest{
+					CurrentPassword: "notMyPassword",
+					NewPassword:     "anotherHighEntropyPassword",
+				},
+			},
+			want: want{
+				status:         http.StatusForbidden,
+				otherStatus:    http.StatusOK,
+				newLoginStatus: http.StatusUnauthorized,
+				oldLoginStatus: http.StatusOK,
+			},
+		},
+		{
+			name: "new password too short",
+			args: args{
+				email: "change-password-short@example.com",
+				ChangePasswordRequest: &ChangePasswordRequest{
+					CurrentPassword: "highEntropyPassword",
+					NewPassword:     "short",
+				},
+			},
+			want: want{
+				status:         http.StatusBadRequest,
+				otherStatus:    http.StatusOK,
+				newLoginStatus: http.StatusUnauthorized,
+				oldLoginStatus: http.StatusOK,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash("highEntropyPassword", argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			_, err = repo.db.ExecContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2)
+				`, tt.args.email, hashedPassword)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			login := func(password string) (int, string) {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: tt.args.email, Password: password})
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+				if ww.Code != http.StatusOK {
+					return ww.Code, ""
+				}
+
+				token, err := extractToken(ww.Header().Get("Set-Cookie"))
+				assert.Nil(t, err)
+				return ww.Code, token
+			}
+			me := func(token string) int {
+				rr := httptest.NewRequest(http.MethodGet, "/api/v1/restricted/me", nil)
+				rr.AddCookie(&http.Cookie{Name: sessionName, Value: token})
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				return ww.Code
+			}
+
+			_, current := login("highEntropyPassword")
+			_, other := login("highEntropyPassword")
+
+			var buf bytes.Buffer
+			err = json.NewEncoder(&buf).Encode(tt.args.ChangePasswordRequest)
+			assert.Nil(t, err)
+
+			rr := httptest.NewRequest(http.MethodPost, "/api/v1/restricted/password", &buf)
+			rr.AddCookie(&http.Cookie{Name: sessionName, Value: current})
+			ww := httptest.NewRecorder()
+			router.ServeHTTP(ww, rr)
+			assert.Equal(t, tt.want.status, ww.Code)
+
+			if ww.Code == http.StatusOK {
+				var resp map[string]int64
+				err = json.NewDecoder(ww.Body).Decode(&resp)
+				assert.Nil(t, err)
+				assert.Equal(t, tt.want.revokedSessions, resp["revoked"])
+			}
+
+			assert.Equal(t, http.StatusOK, me(current))
+			assert.Equal(t, tt.want.otherStatus, me(other))
+
+			status, _ := login(tt.args.NewPassword)
+			assert.Equal(t, tt.want.newLoginStatus, status)
+			status, _ = login("highEntropyPassword")
+			assert.Equal(t, tt.want.oldLoginStatus, status)
+		})
+	}
+}
+
+func TestHandler_RehashIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email  string
+		stored *argon2id.Params
+	}
+	type want struct {
+		memory     uint32
+		iterations uint32
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "weaker hash is upgraded",
+			args: args{
+				email:  "rehash@example.com",
+				stored: &argon2id.Params{Memory: 16 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+			},
+			want: want{memory: 32 * 1024, iterations: 2},
+		},
+		{
+			name: "stronger hash is kept",
+			args: args{
+				email:  "rehash-stronger@example.com",
+				stored: &argon2id.Params{Memory: 64 * 1024, Iterations: 3, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+			},
+			want: want{memory: 64 * 1024, iterations: 3},
+		},
+	}
+
+	cfg := config.Authentication{
+		PasswordMemory:      32 * 1024,
+		PasswordIterations:  2,
+		PasswordParallelism: 1,
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash("highEntropyPassword", tt.args.stored)
+			assert.Nil(t, err)
+
+			var userID uint64
+			err = repo.db.QueryRowContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id
+				`, tt.args.email, hashedPassword).Scan(&userID)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, cfg, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			for range 2 {
+				var buf bytes.Buffer
+				err = json.NewEncoder(&buf).Encode(&LoginRequest{Email: tt.args.email, Password: "highEntropyPassword"})
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+				assert.Equal(t, http.StatusOK, ww.Code)
+			}
+
+			u, err := repo.FindByID(context.Background(), userID)
+			assert.Nil(t, err)
+
+			params, _, _, err := argon2id.DecodeHash(u.Password)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want.memory, params.Memory)
+			assert.Equal(t, tt.want.iterations, params.Iterations)
+		})
+	}
+}
+
+func TestHandler_TokenIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email    string
+		password string
+	}
+	type want struct {
+		wrongPasswordStatus int
+		tokenStatus         int
+		meStatus            int
+		forgedStatus        int
+		refreshStatus       int
+		reuseStatus         int
+		afterReuseStatus    int
+		revokedStatus       int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "issue, rotate and revoke",
+			args: args{
+				email:    "token@example.com",
+				password: "highEntropyPassword",
+			},
+			want: want{
+				wrongPasswordStatus: http.StatusUnauthorized,
+				tokenStatus:         http.StatusOK,
+				meStatus:            http.StatusOK,
+				forgedStatus:        http.StatusUnauthorized,
+				refreshStatus:       http.StatusOK,
+				reuseStatus:         http.StatusUnauthorized,
+				afterReuseStatus:    http.StatusUnauthorized,
+				revokedStatus:       http.StatusUnauthorized,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+
+	key, err := token.GenerateKey()
+	assert.Nil(t, err)
+	issuer, err := token.NewIssuer("go8", 15*time.Minute, []ed25519.PrivateKey{key})
+	assert.Nil(t, err)
+
+	tokenCfg := config.Token{
+		Enable:        true,
+		Issuer:        "go8",
+		AccessExpiry:  15 * time.Minute,
+		RefreshExpiry: 24 * time.Hour,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash(tt.args.password, argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			var userID uint64
+			err = repo.db.QueryRowContext(context.Background(), `
+				INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id
+				`, tt.args.email, hashedPassword).Scan(&userID)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			router.Use(middleware.AccessToken(issuer))
+			RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+			RegisterTokenEndPoints(router, config.Authentication{}, tokenCfg, issuer, repo, NewPostgresLockoutStore(migrator.DB))
+
+			post := func(path string, body any) (int, TokenResponse) {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(body)
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, path, &buf))
+
+				var resp TokenResponse
+				if ww.Code == http.StatusOK && ww.Body.Len() > 0 {
+					err = json.NewDecoder(ww.Body).Decode(&resp)
+					assert.Nil(t, err)
+				}
+				return ww.Code, resp
+			}
+			me := func(accessToken string) (int, uint64) {
+				rr := httptest.NewRequest(http.MethodGet, "/api/v1/restricted/me", nil)
+				rr.Header.Set("Authorization", "Bearer "+accessToken)
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+
+				var resp map[string]uint64
+				if ww.Code == http.StatusOK {
+					err := json.NewDecoder(ww.Body).Decode(&resp)
+					assert.Nil(t, err)
+				}
+				return ww.Code, resp["user_id"]
+			}
+
+			status, _ := post("/api/v1/token", &TokenRequest{Email: tt.args.email, Password: "wrongPassword"})
+			assert.Equal(t, tt.want.wrongPasswordStatus, status)
+
+			status, first := post("/api/v1/token", &TokenRequest{Email: tt.args.email, Password: tt.args.password})
+			assert.Equal(t, tt.want.tokenStatus, status)
+			assert.Equal(t, "Bearer", first.TokenType)
+			assert.NotEmpty(t, first.RefreshToken)
+
+			status, gotID := me(first.AccessToken)
+			assert.Equal(t, tt.want.meStatus, status)
+			assert.Equal(t, userID, gotID)
+
+			status, _ = me("eyJhbGciOiJub25lIn0.eyJzdWIiOiIxIn0.")
+			assert.Equal(t, tt.want.forgedStatus, status)
+
+			status, second := post("/api/v1/token/refresh", &RefreshTokenRequest{RefreshToken: first.RefreshToken})
+			assert.Equal(t, tt.want.refreshStatus, status)
+			assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+			status, _ = me(second.AccessToken)
+			assert.Equal(t, tt.want.meStatus, status)
+
+			// Using a rotated token again revokes the whole family,
+			// including the token that replaced it.
+			status, _ = post("/api/v1/token/refresh", &RefreshTokenRequest{RefreshToken: first.RefreshToken})
+			assert.Equal(t, tt.want.reuseStatus, status)
+			status, _ = post("/api/v1/token/refresh", &RefreshTokenRequest{RefreshToken: second.RefreshToken})
+			assert.Equal(t, tt.want.afterReuseStatus, status)
+
+			// Logging out a device revokes its refresh token.
+			_, third := post("/api/v1/token", &TokenRequest{Email: tt.args.email, Password: tt.args.password})
+			status, _ = post("/api/v1/token/revoke", &RefreshTokenRequest{RefreshToken: third.RefreshToken})
+			assert.Equal(t, http.StatusOK, status)
+			status, _ = post("/api/v1/token/refresh", &RefreshTokenRequest{RefreshToken: third.RefreshToken})
+			assert.Equal(t, tt.want.revokedStatus, status)
+
+			ww := httptest.NewRecorder()
+			router.ServeHTTP(ww, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+			assert.Equal(t, http.StatusOK, ww.Code)
+			var jwks struct {
+				Keys []map[string]any `json:"keys"`
+			}
+			err = json.NewDecoder(ww.Body).Decode(&jwks)
+			assert.Nil(t, err)
+			assert.Len(t, jwks.Keys, 1)
+		})
+	}
+}
+
+func TestHandler_OIDCIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		subject       string
+		email         string
+		emailVerified bool
+		existing      bool
+	}
+	type want struct {
+		status       int
+		linked       bool
+		oldRevoked   bool
+		successQuery string
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "links existing unverified account by email",
+			args: args{
+				subject:       "subject-1",
+				email:         "oidc-link@example.com",
+				emailVerified: true,
+				existing:      true,
+			},
+			want: want{
+				status:     http.StatusFound,
+				linked:     true,
+				oldRevoked: true,
+			},
+		},
+		{
+			name: "creates new user",
+			args: args{
+				subject:       "subject-2",
+				email:         "oidc-new@example.com",
+				emailVerified: true,
+			},
+			want: want{
+				status: http.StatusFound,
+			},
+		},
+		{
+			name: "rejects unverified email",
+			args: args{
+				subject:       "subject-3",
+				email:         "oidc-unverified@example.com",
+				emailVerified: false,
+			},
+			want: want{
+				status: http.StatusForbidden,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+	idp := newFakeIdP(t, "go8")
+
+	cfg := config.OIDC{
+		Enable:       true,
+		Issuer:       idp.URL,
+		ClientID:     "go8",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3080/api/v1/oidc/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+		SuccessURL:   "http://localhost:3000",
+		StateExpiry:  10 * time.Minute,
+		DiscoveryTTL: 1 * time.Hour,
+	}
+
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session, nil))
+	RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+	RegisterOIDCEndPoints(router, cfg, config.Authentication{}, session, repo, idp.Client())
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		rr := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			rr.AddCookie(&http.Cookie{Name: sessionName, Value: token})
+		}
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		return ww
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var existingID uint64
+			var oldToken string
+			if tt.args.existing {
+				hashedPassword, err := argon2id.CreateHash("highEntropyPassword", argon2id.DefaultParams)
+				assert.Nil(t, err)
+
+				err = repo.db.QueryRowContext(context.Background(), `
+					INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id
+					`, tt.args.email, hashedPassword).Scan(&existingID)
+				assert.Nil(t, err)
+
+				var buf bytes.Buffer
+				err = json.NewEncoder(&buf).Encode(&LoginRequest{Email: tt.args.email, Password: "highEntropyPassword"})
+				assert.Nil(t, err)
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+				assert.Equal(t, http.StatusOK, ww.Code)
+				oldToken, err = extractToken(ww.Header().Get("Set-Cookie"))
+				assert.Nil(t, err)
+			}
+
+			idp.setUser(tt.args.subject, tt.args.email, tt.args.emailVerified)
+
+			// Start login, which sends browser to provider.
+			ww := do(http.MethodGet, "/api/v1/oidc/login", "")
+			assert.Equal(t, http.StatusFound, ww.Code)
+			pending, err := extractToken(ww.Header().Get("Set-Cookie"))
+			assert.Nil(t, err)
+
+			// Provider authenticates user and sends browser back with a code.
+			callback := idp.authorize(t, ww.Header().Get("Location"))
+
+			ww = do(http.MethodGet, callback, pending)
+			assert.Equal(t, tt.want.status, ww.Code)
+			if tt.want.status != http.StatusFound {
+				return
+			}
+			assert.Equal(t, cfg.SuccessURL, ww.Header().Get("Location"))
+
+			loggedIn, err := extractToken(ww.Header().Get("Set-Cookie"))
+			assert.Nil(t, err)
+
+			ww = do(http.MethodGet, "/api/v1/restricted/me", loggedIn)
+			assert.Equal(t, http.StatusOK, ww.Code)
+			var me map[string]uint64
+			err = json.NewDecoder(ww.Body).Decode(&me)
+			assert.Nil(t, err)
+			if tt.want.linked {
+				assert.Equal(t, existingID, me["user_id"])
+			} else {
+				assert.NotZero(t, me["user_id"])
+			}
+
+			if tt.want.oldRevoked {
+				ww = do(http.MethodGet, "/api/v1/restricted/me", oldToken)
+				assert.Equal(t, http.StatusUnauthorized, ww.Code)
+			}
+
+			// The same callback cannot be used twice.
+			ww = do(http.MethodGet, callback, pending)
+			assert.Equal(t, http.StatusBadRequest, ww.Code)
+		})
+	}
+
+	assert.Equal(t, int32(1), idp.discoveries.Load(), "discovery document should be cached")
+}
+
+func extractToken(cookie string) (string, error) {
+	parts := strings.Split(cookie, ";")
+	if len(parts) == 0 {
+		return "", errors.New("invalid cookie")
+	}
+
+	for _, part := range parts {
+		keyVal := strings.Split(part, "=")
+		if len(keyVal) != 2 {
+			return "", errors.New("invalid cookie")
+		}
+		if keyVal[0] == sessionName {
+			return keyVal[1], nil
+		}
+	}
+
+	return "", errors.New("invalid cookie")
+}
+
+func dbClient() *gen.Client {
+	drv := entsql.OpenDB(DBDriver, migrator.DB)
+	return gen.NewClient(gen.Driver(drv))
+}
+
+func newSession(db *sql.DB, duration time.Duration) *scs.SessionManager {
+	manager := scs.New()
+	manager.Store = postgresstore.New(db)
+	manager.CtxStore = postgresstore.New(db)
+	manager.Lifetime = duration
+	manager.Cookie.Name = sessionName
+	manager.Cookie.HttpOnly = false
+	manager.Cookie.Path = "/"
+	manager.Cookie.SameSite = http.SameSiteLaxMode
+	manager.Cookie.Secure = false
+
+	return manager
+}
+
+// fakeMailer keeps sent messages in memory so that tests can read the links
+// that would have been emailed.
+type fakeMailer struct {
+	mu       sync.Mutex
+	messages []*mailer.Message
+}
+
+func (f *fakeMailer) Send(_ context.Context, msg *mailer.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+// token returns the query parameter value of the link in the latest message.
+// Some emails are sent in the background, so it waits for one to arrive.
+func (f *fakeMailer) token(t *testing.T, key string) string {
+	t.Helper()
+
+	var msg *mailer.Message
+	ok := assert.Eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if len(f.messages) == 0 {
+			return false
+		}
+		msg = f.messages[len(f.messages)-1]
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	if !ok {
+		t.Fatal("no email was sent")
+	}
+
+	for _, field := range strings.Fields(msg.Body) {
+		u, err := url.Parse(field)
+		if err != nil {
+			continue
+		}
+		if val := u.Query().Get(key); val != "" {
+			return val
+		}
+	}
+
+	t.Fatal("no token found in email")
+	return ""
+}
+
+// fakeIdP is an in-process OpenID Connect provider. Discovery and keys are
+// served by go-oidc's oidctest. It authorises every request as the user set
+// with setUser, and checks PKCE verifier when exchanging a code.
+type fakeIdP struct {
+	*httptest.Server
+
+	clientID    string
+	key         *rsa.PrivateKey
+	discoveries atomic.Int32
+
+	mu            sync.Mutex
+	grants        map[string]fakeGrant
+	subject       string
+	email         string
+	emailVerified bool
+}
+
+type fakeGrant struct {
+	nonce     string
+	challenge string
+}
+
+func newFakeIdP(t *testing.T, clientID string) *fakeIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	idp := &fakeIdP{
+		clientID: clientID,
+		key:      key,
+		grants:   make(map[string]fakeGrant),
+	}
+
+	discovery := &oidctest.Server{
+		PublicKeys: []oidctest.PublicKey{
+			{PublicKey: key.Public(), KeyID: "test", Algorithm: oidc.RS256},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		idp.discoveries.Add(1)
+		discovery.ServeHTTP(w, r)
+	})
+	mux.Handle("/keys", discovery)
+	mux.HandleFunc("/token", idp.token)
+
+	idp.Server = httptest.NewServer(mux)
+	discovery.SetIssuer(idp.URL)
+	t.Cleanup(idp.Close)
+
+	return idp
+}
+
+func (f *fakeIdP) setUser(subject, email string, emailVerified bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subject = subject
+	f.email = email
+	f.emailVerified = emailVerified
+}
+
+// authorize plays the provider's login page. It returns the path and query
+// that the browser would be redirected back to.
+func (f *fakeIdP) authorize(t *testing.T, location string) string {
+	t.Helper()
+
+	u, err := url.Parse(location)
+	assert.Nil(t, err)
+	q := u.Query()
+	assert.Equal(t, f.clientID, q.Get("client_id"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+
+	code := rand.Text()
+	f.mu.Lock()
+	f.grants[code] = fakeGrant{nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+	f.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	assert.Nil(t, err)
+	back := url.Values{}
+	back.Set("code", code)
+	back.Set("state", q.Get("state"))
+
+	return redirect.Path + "?" + back.Encode()
+}
+
+func (f *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	grant, ok := f.grants[r.PostForm.Get("code")]
+	delete(f.grants, r.PostForm.Get("code"))
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+		return
+	}
+
+	claims, _ := json.Marshal(map[string]any{
+		"iss":            f.URL,
+		"aud":            f.clientID,
+		"sub":            f.subject,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          grant.nonce,
+		"email":          f.email,
+		"email_verified": f.emailVerified,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     oidctest.SignIDToken(f.key, "test", oidc.RS256, string(claims)),
+	})
+}
+
+func TestHandler_AccountIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		email string
+		*UpdateProfileRequest
+		*DeleteAccountRequest
+	}
+	type want struct {
+		profile       *ProfileResponse
+		deleteStatus  int
+		meStatus      int
+		restoreStatus int
+	}
+	first, middle, last, empty := "Jane", "Q", "Doe", ""
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "update names, delete and restore",
+			args: args{
+				email: "account@example.com",
+				UpdateProfileRequest: &UpdateProfileRequest{
+					FirstName:  &first,
+					MiddleName: &middle,
+					LastName:   &last,
+				},
+				DeleteAccountRequest: &DeleteAccountRequest{Password: "highEntropyPassword"},
+			},
+			want: want{
+				profile: &ProfileResponse{
+					FirstName:  "Jane",
+					MiddleName: "Q",
+					LastName:   "Doe",
+					Email:      "account@example.com",
+				},
+				deleteStatus:  http.StatusAccepted,
+				meStatus:      http.StatusUnauthorized,
+				restoreStatus: http.StatusOK,
+			},
+		},
+		{
+			name: "clear middle name, wrong password",
+			args: args{
+				email: "account-wrong@example.com",
+				UpdateProfileRequest: &UpdateProfileRequest{
+					MiddleName: &empty,
+				},
+				DeleteAccountRequest: &DeleteAccountRequest{Password: "notMyPassword"},
+			},
+			want: want{
+				profile: &ProfileResponse{
+					FirstName: "First",
+					LastName:  "Last",
+					Email:     "account-wrong@example.com",
+				},
+				deleteStatus:  http.StatusForbidden,
+				meStatus:      http.StatusOK,
+				restoreStatus: http.StatusConflict,
+			},
+		},
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, err := argon2id.CreateHash("highEntropyPassword", argon2id.DefaultParams)
+			assert.Nil(t, err)
+
+			var userID uint64
+			err = repo.db.QueryRowContext(context.Background(), `
+				INSERT INTO users (first_name, middle_name, last_name, email, password)
+				VALUES ('First', 'Middle', 'Last', $1, $2)
+				RETURNING id
+				`, tt.args.email, hashedPassword).Scan(&userID)
+			assert.Nil(t, err)
+
+			_, err = repo.db.ExecContext(context.Background(), `
+				INSERT INTO audits (actor_id, table_row_id, table_name, action)
+				VALUES ($1, $1, 'User', 'OpUpdateOne')
+				`, userID)
+			assert.Nil(t, err)
+
+			router := chi.NewRouter()
+			router.Use(middleware.LoadAndSave(session, nil))
+			RegisterHTTPEndPoints(router, config.Authentication{AccountDeletionGrace: time.Hour}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+			login := func() string {
+				var buf bytes.Buffer
+				err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: tt.args.email, Password: "highEntropyPassword"})
+				assert.Nil(t, err)
+
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+				assert.Equal(t, http.StatusOK, ww.Code)
+
+				token, err := extractToken(ww.Header().Get("Set-Cookie"))
+				assert.Nil(t, err)
+				return token
+			}
+			call := func(method, target, token string, body any) *httptest.ResponseRecorder {
+				var buf bytes.Buffer
+				if body != nil {
+					err := json.NewEncoder(&buf).Encode(body)
+					assert.Nil(t, err)
+				}
+
+				rr := httptest.NewRequest(method, target, &buf)
+				rr.AddCookie(&http.Cookie{Name: sessionName, Value: token})
+				ww := httptest.NewRecorder()
+				router.ServeHTTP(ww, rr)
+				return ww
+			}
+
+			current := login()
+
+			ww := call(http.MethodPatch, "/api/v1/restricted/profile", current, tt.args.UpdateProfileRequest)
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			var profile ProfileResponse
+			err = json.NewDecoder(ww.Body).Decode(&profile)
+			assert.Nil(t, err)
+			tt.want.profile.ID = userID
+			assert.Equal(t, tt.want.profile, &profile)
+
+			ww = call(http.MethodGet, "/api/v1/restricted/account/export", current, nil)
+			assert.Equal(t, http.StatusOK, ww.Code)
+			assert.Contains(t, ww.Header().Get("Content-Disposition"), "attachment")
+			assert.NotContains(t, ww.Body.String(), hashedPassword)
+
+			var export ExportResponse
+			err = json.NewDecoder(ww.Body).Decode(&export)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want.profile, export.User)
+			assert.Len(t, export.Sessions, 1)
+			assert.True(t, export.Sessions[0].Current)
+			assert.GreaterOrEqual(t, len(export.Audits), 1)
+
+			ww = call(http.MethodDelete, "/api/v1/restricted/account", current, tt.args.DeleteAccountRequest)
+			assert.Equal(t, tt.want.deleteStatus, ww.Code)
+			assert.Equal(t, tt.want.meStatus, call(http.MethodGet, "/api/v1/restricted/me", current, nil).Code)
+
+			// Logging in is still possible within the grace period.
+			current = login()
+			ww = call(http.MethodPost, "/api/v1/restricted/account/restore", current, nil)
+			assert.Equal(t, tt.want.restoreStatus, ww.Code)
+
+			u, err := repo.FindByID(context.Background(), userID)
+			assert.Nil(t, err)
+			assert.Nil(t, u.DeleteAfter)
+		})
+	}
+
+	t.Run("purge after grace period", func(t *testing.T) {
+		var userID uint64
+		err := repo.db.QueryRowContext(context.Background(), `
+			INSERT INTO users (email, password, delete_after)
+			VALUES ('account-purge@example.com', 'password', current_timestamp - interval '1 minute')
+			RETURNING id
+			`).Scan(&userID)
+		assert.Nil(t, err)
+
+		_, err = repo.db.ExecContext(context.Background(), `
+			INSERT INTO audits (actor_id, table_name, action) VALUES ($1, 'User', 'OpUpdateOne')
+			`, userID)
+		assert.Nil(t, err)
+
+		purged, err := repo.PurgeDeletedAccounts(context.Background())
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, purged, int64(1))
+
+		_, err = repo.FindByID(context.Background(), userID)
+		assert.True(t, gen.IsNotFound(err))
+
+		entries, err := repo.AuditEntries(context.Background(), userID)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestHandler_AdminUsersIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	client := dbClient()
+	session := newSession(migrator.DB, 1*time.Hour)
+	repo := NewRepo(client, migrator.DB, session)
+
+	hashedPassword, err := argon2id.CreateHash("highEntropyPassword", argon2id.DefaultParams)
+	assert.Nil(t, err)
+
+	var userID uint64
+	err = repo.db.QueryRowContext(context.Background(), `
+		INSERT INTO users (first_name, last_name, email, password)
+		VALUES ('Zebedee', 'Managed', 'managed-a@example.com', $1), ('Other', 'Managed', 'managed-b@example.com', $1)
+		RETURNING id
+		`, hashedPassword).Scan(&userID)
+	assert.Nil(t, err)
+
+	router := chi.NewRouter()
+	router.Use(middleware.LoadAndSave(session, nil))
+	router.Use(middleware.Permissions(authorization.NewRepo(client)))
+	router.Use(middleware.Accounts(repo))
+	router.Use(middleware.Audit)
+	RegisterHTTPEndPoints(router, config.Authentication{}, session, repo, &fakeMailer{}, NewPostgresLockoutStore(migrator.DB))
+
+	login := func(email string) (int, string) {
+		var buf bytes.Buffer
+		err := json.NewEncoder(&buf).Encode(&LoginRequest{Email: email, Password: "highEntropyPassword"})
+		assert.Nil(t, err)
+
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, "/api/v1/login", &buf))
+		if ww.Code != http.StatusOK {
+			return ww.Code, ""
+		}
+
+		token, err := extractToken(ww.Header().Get("Set-Cookie"))
+		assert.Nil(t, err)
+		return ww.Code, token
+	}
+	call := func(method, target, token string) *httptest.ResponseRecorder {
+		rr := httptest.NewRequest(method, target, nil)
+		rr.AddCookie(&http.Cookie{Name: sessionName, Value: token})
+		ww := httptest.NewRecorder()
+		router.ServeHTTP(ww, rr)
+		return ww
+	}
+	me := func(token string) (int, uint64) {
+		ww := call(http.MethodGet, "/api/v1/restricted/me", token)
+		var resp struct {
+			UserID uint64 `json:"user_id"`
+		}
+		_ = json.NewDecoder(ww.Body).Decode(&resp)
+		return ww.Code, resp.UserID
+	}
+
+	_, adminToken := login("admin@gmhafiz.com")
+	_, adminID := me(adminToken)
+
+	t.Run("list", func(t *testing.T) {
+		type want struct {
+			status int
+			total  int
+		}
+		tests := []struct {
+			name  string
+			query string
+			want  want
+		}{
+			{name: "by email", query: "?email=managed-", want: want{status: http.StatusOK, total: 2}},
+			{name: "by name", query: "?name=zebedee", want: want{status: http.StatusOK, total: 1}},
+			{name: "paged", query: "?email=managed-&limit=1&sort=email,desc", want: want{status: http.StatusOK, total: 2}},
+			{name: "disabled", query: "?email=managed-&disabled=true", want: want{status: http.StatusOK, total: 0}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ww := call(http.MethodGet, "/api/v1/admin/users"+tt.query, adminToken)
+				assert.Equal(t, tt.want.status, ww.Code)
+
+				var resp struct {
+					Data []*UserResponse `json:"data"`
+					Meta respond.Meta    `json:"meta"`
+				}
+				err := json.NewDecoder(ww.Body).Decode(&resp)
+				assert.Nil(t, err)
+				assert.Equal(t, tt.want.total, resp.Meta.Total)
+				assert.LessOrEqual(t, len(resp.Data), tt.want.total)
+			})
+		}
+
+		_, userToken := login("managed-b@example.com")
+		ww := call(http.MethodGet, "/api/v1/admin/users", userToken)
+		assert.Equal(t, http.StatusForbidden, ww.Code)
+	})
+
+	t.Run("disable and enable", func(t *testing.T) {
+		_, userToken := login("managed-a@example.com")
+
+		ww := call(http.MethodGet, fmt.Sprintf("/api/v1/admin/users/%d/sessions", userID), adminToken)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		var sessions []*SessionResponse
+		err := json.NewDecoder(ww.Body).Decode(&sessions)
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 1)
+
+		ww = call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/disable", userID), adminToken)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		var u UserResponse
+		err = json.NewDecoder(ww.Body).Decode(&u)
+		assert.Nil(t, err)
+		assert.NotNil(t, u.DisabledAt)
+
+		status, _ := me(userToken)
+		assert.Equal(t, http.StatusUnauthorized, status)
+		status, _ = login("managed-a@example.com")
+		assert.Equal(t, http.StatusForbidden, status)
+
+		ww = call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/enable", userID), adminToken)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		status, _ = login("managed-a@example.com")
+		assert.Equal(t, http.StatusOK, status)
+
+		ww = call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/verify", userID), adminToken)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		err = json.NewDecoder(ww.Body).Decode(&u)
+		assert.Nil(t, err)
+		assert.NotNil(t, u.VerifiedAt)
+
+		ww = call(http.MethodPost, "/api/v1/admin/users/999999/disable", adminToken)
+		assert.Equal(t, http.StatusNotFound, ww.Code)
+	})
+
+	t.Run("impersonate", func(t *testing.T) {
+		ww := call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/impersonate", adminID), adminToken)
+		assert.Equal(t, http.StatusBadRequest, ww.Code)
+
+		ww = call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/impersonate", userID), adminToken)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		impersonated, err := extractToken(ww.Header().Get("Set-Cookie"))
+		assert.Nil(t, err)
+
+		_, id := me(impersonated)
+		assert.Equal(t, userID, id)
+
+		ww = call(http.MethodGet, "/api/v1/restricted/sessions", impersonated)
+		var sessions []*SessionResponse
+		err = json.NewDecoder(ww.Body).Decode(&sessions)
+		assert.Nil(t, err)
+		var flagged bool
+		for _, s := range sessions {
+			if s.Current {
+				flagged = s.Impersonated
+			}
+		}
+		assert.True(t, flagged)
+
+		// Security settings are off limits while impersonating.
+		ww = call(http.MethodPost, "/api/v1/restricted/2fa/enroll", impersonated)
+		assert.Equal(t, http.StatusForbidden, ww.Code)
+
+		var audited int
+		err = repo.db.QueryRowContext(context.Background(), `
+			SELECT count(*) FROM audits WHERE actor_id = $1 AND table_row_id = $2 AND action = 'impersonate'
+			`, adminID, userID).Scan(&audited)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, audited)
+
+		ww = call(http.MethodDelete, "/api/v1/restricted/impersonation", impersonated)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		restored, err := extractToken(ww.Header().Get("Set-Cookie"))
+		assert.Nil(t, err)
+
+		_, id = me(restored)
+		assert.Equal(t, adminID, id)
+
+		ww = call(http.MethodDelete, "/api/v1/restricted/impersonation", restored)
+		assert.Equal(t, http.StatusBadRequest, ww.Code)
+	})
+
+	t.Run("impersonate admin", func(t *testing.T) {
+		var otherAdminID uint64
+		err := repo.db.QueryRowContext(context.Background(), `
+			INSERT INTO users (email, password) VALUES ('managed-admin@example.com', $1)
+			RETURNING id
+			`, hashedPassword).Scan(&otherAdminID)
+		assert.Nil(t, err)
+		_, err = repo.db.ExecContext(context.Background(), `
+			INSERT INTO user_roles (user_id, role_id)
+			SELECT $1, r.id FROM roles r WHERE r.name = 'admin'
+			`, otherAdminID)
+		assert.Nil(t, err)
+
+		ww := call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/impersonate", otherAdminID), adminToken)
+		assert.Equal(t, http.StatusForbidden, ww.Code)
+		assert.Contains(t, ww.Body.String(), ErrImpersonateAdmin.Error())
+
+		_, id := me(adminToken)
+		assert.Equal(t, adminID, id)
+	})
+
+	t.Run("impersonate while impersonating", func(t *testing.T) {
+		var otherID uint64
+		err := repo.db.QueryRowContext(context.Background(), `
+			SELECT id FROM users WHERE email = 'managed-b@example.com'
+			`).Scan(&otherID)
+		assert.Nil(t, err)
+
+		_, token := login("admin@gmhafiz.com")
+		ww := call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/impersonate", userID), token)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		impersonated, err := extractToken(ww.Header().Get("Set-Cookie"))
+		assert.Nil(t, err)
+
+		// Made an admin after impersonation began, so that only being in an
+		// impersonated session stands in the way.
+		_, err = repo.db.ExecContext(context.Background(), `
+			INSERT INTO user_roles (user_id, role_id)
+			SELECT $1, r.id FROM roles r WHERE r.name = 'admin'
+			`, userID)
+		assert.Nil(t, err)
+		defer func() {
+			_, err := repo.db.ExecContext(context.Background(), `DELETE FROM user_roles WHERE user_id = $1`, userID)
+			assert.Nil(t, err)
+		}()
+
+		ww = call(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%d/impersonate", otherID), impersonated)
+		assert.Equal(t, http.StatusForbidden, ww.Code)
+		assert.Contains(t, ww.Body.String(), ErrImpersonating.Error())
+
+		// Stopping still returns to the admin who started it.
+		ww = call(http.MethodDelete, "/api/v1/restricted/impersonation", impersonated)
+		assert.Equal(t, http.StatusOK, ww.Code)
+		restored, err := extractToken(ww.Header().Get("Set-Cookie"))
+		assert.Nil(t, err)
+
+		_, id := me(restored)
+		assert.Equal(t, adminID, id)
+	})
 }
@@ -0,0 +1,150 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+var (
+	ErrTooManyAttempts = errors.New("too many failed login attempts, please try again later")
+)
+
+// LockoutStore keeps count of failed logins. Keys look like
+// `account:<email>` or `ip:<address>`.
+type LockoutStore interface {
+	// LockedUntil returns when the lock on key ends. Zero time means key is
+	// not locked.
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Fail records a failure and returns the number of failures so far. The
+	// count starts afresh if the previous failure is older than window.
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Lockout slows down password guessing. Every failure to log in to an account
+// makes it wait exponentially longer before the next attempt, until the
+// account is locked once threshold is reached. Client IPs are tracked too so
+// that one client cannot try many accounts, but with a higher threshold and
+// without backoff since many users can share an address.
+type Lockout struct {
+	cfg   config.Authentication
+	store LockoutStore
+}
+
+func NewLockout(cfg config.Authentication, store LockoutStore) *Lockout {
+	return &Lockout{
+		cfg:   cfg,
+		store: store,
+	}
+}
+
+// Wait returns how long a login to this account, or from this IP, has to
+// wait. Zero means it may go ahead.
+func (l *Lockout) Wait(ctx context.Context, email, ip string) (time.Duration, error) {
+	if l.disabled() {
+		return 0, nil
+	}
+
+	var wait time.Duration
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		until, err := l.store.LockedUntil(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if d := time.Until(until); d > wait {
+			wait = d
+		}
+	}
+
+	return wait, nil
+}
+
+// Fail records a failed login and returns how long until the next attempt is
+// allowed.
+func (l *Lockout) Fail(ctx context.Context, email, ip string) (time.Duration, error) {
+	if l.disabled() {
+		return 0, nil
+	}
+
+	failures, err := l.store.Fail(ctx, accountKey(email), l.cfg.LockoutWindow)
+	if err != nil {
+		return 0, err
+	}
+
+	wait := l.backoff(failures)
+	if failures >= l.cfg.LockoutThreshold {
+		wait = l.cfg.LockoutDuration
+		slog.WarnContext(ctx, "account locked after failed logins",
+			"event", "login_lockout", "email", email, "ip", ip, "failures", failures, "duration", wait)
+	}
+
+	if err := l.store.Lock(ctx, accountKey(email), time.Now().Add(wait)); err != nil {
+		return 0, err
+	}
+
+	if l.cfg.LockoutThresholdPerIP > 0 {
+		failures, err := l.store.Fail(ctx, ipKey(ip), l.cfg.LockoutWindow)
+		if err != nil {
+			return 0, err
+		}
+
+		if failures >= l.cfg.LockoutThresholdPerIP {
+			slog.WarnContext(ctx, "ip locked after failed logins",
+				"event", "login_lockout", "ip", ip, "failures", failures, "duration", l.cfg.LockoutDuration)
+
+			if err := l.store.Lock(ctx, ipKey(ip), time.Now().Add(l.cfg.LockoutDuration)); err != nil {
+				return 0, err
+			}
+			wait = max(wait, l.cfg.LockoutDuration)
+		}
+	}
+
+	return wait, nil
+}
+
+// Succeed forgets previous failures to this account.
+func (l *Lockout) Succeed(ctx context.Context, email string) error {
+	if l.disabled() {
+		return nil
+	}
+	return l.store.Reset(ctx, accountKey(email))
+}
+
+// Unlock lets a locked account log in straight away.
+func (l *Lockout) Unlock(ctx context.Context, email string, actorID uint64) error {
+	if err := l.store.Reset(ctx, accountKey(email)); err != nil {
+		return err
+	}
+
+	slog.WarnContext(ctx, "account unlocked", "event", "login_unlock", "email", email, "actor_id", actorID)
+
+	return nil
+}
+
+func (l *Lockout) disabled() bool {
+	return l.cfg.LockoutThreshold <= 0
+}
+
+// backoff doubles from LockoutBackoff for each failure, up to
+// LockoutBackoffMax.
+func (l *Lockout) backoff(failures int) time.Duration {
+	wait := l.cfg.LockoutBackoff
+	for i := 1; i < failures && wait < l.cfg.LockoutBackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, l.cfg.LockoutBackoffMax)
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
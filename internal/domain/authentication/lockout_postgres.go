@@ -0,0 +1,66 @@
+package authentication
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type postgresLockoutStore struct {
+	db *sql.DB
+}
+
+// NewPostgresLockoutStore keeps failed logins in `login_attempts` table. It
+// is used when Redis is not enabled.
+func NewPostgresLockoutStore(db *sql.DB) *postgresLockoutStore {
+	return &postgresLockoutStore{db: db}
+}
+
+func (s *postgresLockoutStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	var until sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT locked_until FROM login_attempts WHERE key = $1
+		`, key).Scan(&until)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	return until.Time, nil
+}
+
+func (s *postgresLockoutStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, current_timestamp)
+		ON CONFLICT (key) DO UPDATE
+			SET failures = CASE
+			                   WHEN login_attempts.last_failure_at < current_timestamp - make_interval(secs => $2)
+			                       THEN 1
+			                   ELSE login_attempts.failures + 1
+			    END,
+			    last_failure_at = current_timestamp
+		RETURNING failures
+		`, key, window.Seconds()).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+func (s *postgresLockoutStore) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE login_attempts SET locked_until = $2 WHERE key = $1
+		`, key, until)
+	return err
+}
+
+func (s *postgresLockoutStore) Reset(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
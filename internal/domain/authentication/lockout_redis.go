@@ -0,0 +1,68 @@
+package authentication
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisLockoutStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisLockoutStore keeps failed logins in Redis. Counters expire on their
+// own once window has passed since the last failure. Works with both single
+// node and cluster clients.
+func NewRedisLockoutStore(client redis.Cmdable) *redisLockoutStore {
+	return &redisLockoutStore{client: client}
+}
+
+func (s *redisLockoutStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	ttl, err := s.client.PTTL(ctx, lockKey(key)).Result()
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Negative values mean key does not exist, or has no expiry.
+	if ttl <= 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Now().Add(ttl), nil
+}
+
+func (s *redisLockoutStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, failKey(key))
+		pipe.PExpire(ctx, failKey(key), window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(incr.Val()), nil
+}
+
+func (s *redisLockoutStore) Lock(ctx context.Context, key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, lockKey(key), 1, ttl).Err()
+}
+
+func (s *redisLockoutStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, failKey(key), lockKey(key)).Err()
+}
+
+// Both keys share a hash tag so that they live in the same cluster slot and
+// can be deleted together.
+func failKey(key string) string {
+	return "login:{" + key + "}:fail"
+}
+
+func lockKey(key string) string {
+	return "login:{" + key + "}:lock"
+}
@@ -1,6 +1,30 @@
 package authentication
 
+import "time"
+
 type User struct {
 	ID       int
 	Username string
 }
+
+// Session is a logged-in device. ID is the session's public ID, never its
+// token. Impersonated sessions are used by an admin acting as the user.
+type Session struct {
+	ID           string
+	Current      bool
+	Impersonated bool
+	IPAddress    string
+	UserAgent    string
+	CreatedAt    time.Time
+	LastSeenAt   time.Time
+	Expiry       time.Time
+}
+
+// Identity is a user as known by an OpenID Connect provider.
+type Identity struct {
+	Issuer    string
+	Subject   string
+	Email     string
+	FirstName string
+	LastName  string
+}
@@ -0,0 +1,288 @@
+package authentication
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gmhafiz/scs/v2"
+	"golang.org/x/oauth2"
+
+	"github.com/gmhafiz/go8/config"
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+const (
+	sessionOIDCState    = "oidc_state"
+	sessionOIDCNonce    = "oidc_nonce"
+	sessionOIDCVerifier = "oidc_verifier"
+	sessionOIDCExpiry   = "oidc_expiry"
+)
+
+var (
+	ErrProviderUnavailable = errors.New("identity provider is unavailable")
+	ErrInvalidState        = errors.New("login request is invalid or has expired, please try again")
+	ErrProviderDenied      = errors.New("identity provider did not authorise the login")
+	ErrInvalidIDToken      = errors.New("identity provider returned an invalid id token")
+	ErrEmailUnverified     = errors.New("identity provider has not verified this email address")
+)
+
+// OIDCHandler logs users in through an OpenID Connect provider using the
+// authorization code flow with PKCE.
+type OIDCHandler struct {
+	cfg      config.OIDC
+	auth     config.Authentication
+	repo     Repo
+	session  *scs.SessionManager
+	provider *oidcProvider
+}
+
+func NewOIDCHandler(cfg config.OIDC, auth config.Authentication, session *scs.SessionManager, repo Repo, client *http.Client) *OIDCHandler {
+	return &OIDCHandler{
+		cfg:      cfg,
+		auth:     auth,
+		repo:     repo,
+		session:  session,
+		provider: newOIDCProvider(cfg, client),
+	}
+}
+
+// Login redirects the browser to provider's login page. State, nonce and
+// PKCE verifier are kept in session to be checked when provider redirects
+// back to Callback.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	oauth, _, err := h.provider.discover(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "discovering oidc provider", "error", err)
+		respond.Error(w, http.StatusBadGateway, ErrProviderUnavailable)
+		return
+	}
+
+	state, err := generateToken()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	nonce, err := generateToken()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	h.session.Put(ctx, sessionOIDCState, state)
+	h.session.Put(ctx, sessionOIDCNonce, nonce)
+	h.session.Put(ctx, sessionOIDCVerifier, verifier)
+	h.session.Put(ctx, sessionOIDCExpiry, time.Now().Add(h.cfg.StateExpiry))
+
+	http.Redirect(w, r, oauth.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), http.StatusFound)
+}
+
+// Callback exchanges the authorization code for tokens, validates the ID
+// token and logs in the linked user. Browser is then sent to SuccessURL.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	// Popped so that a callback URL cannot be replayed.
+	state := h.session.PopString(ctx, sessionOIDCState)
+	nonce := h.session.PopString(ctx, sessionOIDCNonce)
+	verifier := h.session.PopString(ctx, sessionOIDCVerifier)
+	expiry := h.session.PopTime(ctx, sessionOIDCExpiry)
+
+	if query.Get("error") != "" {
+		respond.Error(w, http.StatusUnauthorized, ErrProviderDenied)
+		return
+	}
+
+	if state == "" || time.Now().After(expiry) ||
+		subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		respond.Error(w, http.StatusBadRequest, ErrInvalidState)
+		return
+	}
+
+	oauth, idVerifier, err := h.provider.discover(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "discovering oidc provider", "error", err)
+		respond.Error(w, http.StatusBadGateway, ErrProviderUnavailable)
+		return
+	}
+
+	token, err := oauth.Exchange(h.provider.context(ctx), query.Get("code"), oauth2.VerifierOption(verifier))
+	if err != nil {
+		slog.WarnContext(ctx, "exchanging oidc authorization code", "error", err)
+		respond.Error(w, http.StatusUnauthorized, ErrProviderDenied)
+		return
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, ErrInvalidIDToken)
+		return
+	}
+
+	idToken, err := idVerifier.Verify(h.provider.context(ctx), rawIDToken)
+	if err != nil {
+		slog.WarnContext(ctx, "verifying oidc id token", "error", err)
+		respond.Error(w, http.StatusUnauthorized, ErrInvalidIDToken)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		respond.Error(w, http.StatusUnauthorized, ErrInvalidIDToken)
+		return
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		respond.Error(w, http.StatusUnauthorized, ErrInvalidIDToken)
+		return
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		respond.Error(w, http.StatusForbidden, ErrEmailUnverified)
+		return
+	}
+
+	u, err := h.repo.LinkIdentity(ctx, Identity{
+		Issuer:    idToken.Issuer,
+		Subject:   idToken.Subject,
+		Email:     claims.Email,
+		FirstName: claims.GivenName,
+		LastName:  claims.FamilyName,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "linking oidc identity", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if u.DisabledAt != nil {
+		respond.Error(w, http.StatusForbidden, middleware.ErrAccountDisabled)
+		return
+	}
+
+	if err := h.session.RenewToken(ctx); err != nil {
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Provider vouches for the password, but not for our own second factor.
+	if u.TotpEnabledAt != nil {
+		putPendingLogin(ctx, h.session, h.auth.TwoFactorPendingExpiry, u.ID, false)
+		http.Redirect(w, r, withQuery(h.cfg.SuccessURL, "two_factor_required", "true"), http.StatusFound)
+		return
+	}
+
+	startSession(ctx, h.session, u.ID, false)
+
+	http.Redirect(w, r, h.cfg.SuccessURL, http.StatusFound)
+}
+
+type oidcClaims struct {
+	Email         string     `json:"email"`
+	EmailVerified stringBool `json:"email_verified"`
+	GivenName     string     `json:"given_name"`
+	FamilyName    string     `json:"family_name"`
+}
+
+// stringBool accepts both `true` and `"true"` since some providers send
+// `email_verified` as a string.
+type stringBool bool
+
+func (b *stringBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case bool:
+		*b = stringBool(v)
+	case string:
+		*b = v == "true"
+	default:
+		return fmt.Errorf("unexpected email_verified value: %s", data)
+	}
+
+	return nil
+}
+
+// oidcProvider fetches provider's discovery document on first use and keeps
+// it for DiscoveryTTL. Signing keys are cached by go-oidc's key set, which
+// only refetches them when it meets an unknown key ID.
+type oidcProvider struct {
+	cfg    config.OIDC
+	client *http.Client
+
+	mu        sync.Mutex
+	oauth     *oauth2.Config
+	verifier  *oidc.IDTokenVerifier
+	fetchedAt time.Time
+}
+
+func newOIDCProvider(cfg config.OIDC, client *http.Client) *oidcProvider {
+	return &oidcProvider{
+		cfg:    cfg,
+		client: client,
+	}
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil && time.Since(p.fetchedAt) < p.cfg.DiscoveryTTL {
+		return p.oauth, p.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(p.context(context.WithoutCancel(ctx)), p.cfg.Issuer)
+	if err != nil {
+		// A stale document is better than failing every login while the
+		// provider is briefly unreachable.
+		if p.oauth != nil {
+			slog.WarnContext(ctx, "refreshing oidc discovery, using cached document", "error", err)
+			return p.oauth, p.verifier, nil
+		}
+		return nil, nil, err
+	}
+
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+	}
+	p.verifier = provider.VerifierContext(p.context(context.WithoutCancel(ctx)), &oidc.Config{ClientID: p.cfg.ClientID})
+	p.fetchedAt = time.Now()
+
+	return p.oauth, p.verifier, nil
+}
+
+// context makes go-oidc and oauth2 use our http client.
+func (p *oidcProvider) context(ctx context.Context) context.Context {
+	return oidc.ClientContext(ctx, p.client)
+}
+
+func withQuery(rawURL, key, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
@@ -1,18 +1,34 @@
 package authentication
 
 import (
+	"net/http"
+
 	"github.com/gmhafiz/scs/v2"
 	"github.com/go-chi/chi/v5"
 
+	"github.com/gmhafiz/go8/config"
 	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/token"
+	"github.com/gmhafiz/go8/third_party/mailer"
 )
 
-func RegisterHTTPEndPoints(router *chi.Mux, session *scs.SessionManager, repo Repo) {
-	h := NewHandler(session, repo)
+func RegisterHTTPEndPoints(router *chi.Mux, cfg config.Authentication, session *scs.SessionManager, repo Repo, mail mailer.Mailer, lockout LockoutStore) {
+	h := NewHandler(cfg, session, repo, mail, lockout)
 
 	router.Post("/api/v1/login", h.Login)
+	router.Post("/api/v1/login/2fa", h.LoginTwoFactor)
 	router.Post("/api/v1/register", h.Register)
 
+	router.Route("/api/v1/verify", func(router chi.Router) {
+		router.Get("/", h.Verify)
+		router.Post("/resend", h.ResendVerification)
+	})
+
+	router.Route("/api/v1/password", func(router chi.Router) {
+		router.Post("/forgot", h.ForgotPassword)
+		router.Post("/reset", h.ResetPassword)
+	})
+
 	router.Route("/api/v1/logout", func(router chi.Router) {
 		router.Post("/", h.Logout)
 	})
@@ -22,6 +38,78 @@ func RegisterHTTPEndPoints(router *chi.Mux, session *scs.SessionManager, repo Re
 		router.Get("/csrf", h.Csrf)
 		router.Get("/", h.Protected)
 		router.Get("/me", h.Me)
-		router.Post("/logout/{userID}", h.ForceLogout)
+		router.Post("/password", h.ChangePassword)
+		router.Route("/profile", func(router chi.Router) {
+			router.Get("/", h.Profile)
+			router.Patch("/", h.UpdateProfile)
+		})
+		router.Route("/account", func(router chi.Router) {
+			router.Delete("/", h.DeleteAccount)
+			router.Post("/restore", h.RestoreAccount)
+			router.Get("/export", h.ExportAccount)
+		})
+		router.Route("/2fa", func(router chi.Router) {
+			router.Post("/enroll", h.EnrollTwoFactor)
+			router.Post("/confirm", h.ConfirmTwoFactor)
+			router.Post("/disable", h.DisableTwoFactor)
+		})
+		router.Route("/sessions", func(router chi.Router) {
+			router.Get("/", h.Sessions)
+			router.Delete("/others", h.RevokeOtherSessions)
+			router.Delete("/{sessionID}", h.RevokeSession)
+		})
+		router.Route("/api-keys", func(router chi.Router) {
+			router.Post("/", h.CreateAPIKey)
+			router.Get("/", h.ListAPIKeys)
+			router.Delete("/{id}", h.RevokeAPIKey)
+		})
+		router.Delete("/impersonation", h.StopImpersonating)
+		router.With(middleware.RequirePermission("sessions:revoke")).
+			Post("/logout/{userID}", h.ForceLogout)
+	})
+
+	router.Route("/api/v1/admin/users", func(router chi.Router) {
+		router.Use(middleware.Authenticate(session))
+
+		router.Group(func(router chi.Router) {
+			router.Use(middleware.RequirePermission("users:read"))
+			router.Get("/", h.ListUsers)
+			router.Get("/{userID}", h.GetUser)
+			router.Get("/{userID}/sessions", h.UserSessions)
+		})
+		router.Group(func(router chi.Router) {
+			router.Use(middleware.RequirePermission("users:write"))
+			router.Post("/{userID}/disable", h.DisableUser)
+			router.Post("/{userID}/enable", h.EnableUser)
+			router.Post("/{userID}/verify", h.VerifyUser)
+		})
+		router.With(middleware.RequirePermission("users:impersonate")).
+			Post("/{userID}/impersonate", h.Impersonate)
+		router.With(middleware.RequirePermission("accounts:unlock")).
+			Post("/{userID}/unlock", h.Unlock)
+	})
+}
+
+// RegisterTokenEndPoints adds login with access and refresh tokens, and
+// publishes the keys access tokens are signed with.
+func RegisterTokenEndPoints(router *chi.Mux, cfg config.Authentication, tokenCfg config.Token, issuer *token.Issuer, repo Repo, lockout LockoutStore) {
+	// Token endpoints never touch a cookie session nor send emails.
+	h := NewTokenHandler(NewHandler(cfg, nil, repo, nil, lockout), tokenCfg, issuer)
+
+	router.Route("/api/v1/token", func(router chi.Router) {
+		router.Post("/", h.Token)
+		router.Post("/refresh", h.Refresh)
+		router.Post("/revoke", h.Revoke)
+	})
+	router.Get("/.well-known/jwks.json", h.JWKS)
+}
+
+// RegisterOIDCEndPoints adds login through an OpenID Connect provider.
+func RegisterOIDCEndPoints(router *chi.Mux, cfg config.OIDC, auth config.Authentication, session *scs.SessionManager, repo Repo, client *http.Client) {
+	h := NewOIDCHandler(cfg, auth, session, repo, client)
+
+	router.Route("/api/v1/oidc", func(router chi.Router) {
+		router.Get("/login", h.Login)
+		router.Get("/callback", h.Callback)
 	})
 }
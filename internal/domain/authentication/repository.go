@@ -1,54 +1,125 @@
 package authentication
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
+	entsql "entgo.io/ent/dialect/sql"
 	"github.com/alexedwards/argon2id"
 	"github.com/gmhafiz/scs/v2"
 
 	"github.com/gmhafiz/go8/ent/gen"
-	"github.com/gmhafiz/go8/ent/gen/session"
+	"github.com/gmhafiz/go8/ent/gen/apikey"
+	"github.com/gmhafiz/go8/ent/gen/predicate"
 	"github.com/gmhafiz/go8/ent/gen/user"
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/csrf"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
 )
 
 type repo struct {
-	ent     *gen.Client
-	db      *sql.DB
-	session *scs.SessionManager
+	ent      *gen.Client
+	db       *sql.DB
+	session  *scs.SessionManager
+	sessions sessionstore.Store
 }
 
 var (
 	ErrEmailNotAvailable = errors.New("email is not available")
 	ErrNotLoggedIn       = errors.New("you are not logged in yet")
+	ErrInvalidToken      = errors.New("token is invalid or has expired")
+	ErrAPIKeyNotFound    = errors.New("api key not found")
+	ErrTwoFactorEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrSessionNotFound   = errors.New("session not found")
+	// ErrDeletionScheduled means the account is already waiting to be
+	// deleted.
+	ErrDeletionScheduled = errors.New("account is already scheduled for deletion")
+	ErrUserNotFound      = errors.New("user not found")
+	// ErrRefreshTokenReused means a refresh token was presented after it had
+	// already been exchanged.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
+
+const (
+	apiKeyPrefix = "go8_"
+	// apiKeyPrefixLength is how many characters of a key are kept in plain
+	// text so that users can tell their keys apart.
+	apiKeyPrefixLength = 12
+	// lastUsedInterval throttles writes to `last_used_at` so that a busy key
+	// does not cause an UPDATE on every request.
+	lastUsedInterval = time.Minute
+)
+
+const (
+	purposeVerification  = "email_verification"
+	purposePasswordReset = "password_reset"
 )
 
 type Repo interface {
-	Register(ctx context.Context, firstName, lastName, email, hashedPassword string) error
+	Register(ctx context.Context, firstName, lastName, email, hashedPassword string) (*gen.User, error)
 	Login(ctx context.Context, req LoginRequest) (*gen.User, bool, error)
 	Logout(ctx context.Context, userID uint64) (bool, error)
 	Csrf(ctx context.Context) (string, error)
+	FindByEmail(ctx context.Context, email string) (*gen.User, error)
+	CreateToken(ctx context.Context, userID uint64, purpose string, ttl time.Duration) (string, error)
+	Verify(ctx context.Context, token string) error
+	FindByToken(ctx context.Context, token, purpose string) (*gen.User, error)
+	ResetPassword(ctx context.Context, token, hashedPassword string) (uint64, error)
+	CreateAPIKey(ctx context.Context, userID uint64, name string, scopes []string, expiresAt *time.Time) (*gen.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, userID uint64) ([]*gen.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id uint64) error
+	FindAPIKey(ctx context.Context, key string) (uint64, []string, bool, error)
+	FindByID(ctx context.Context, userID uint64) (*gen.User, error)
+	SetTOTPSecret(ctx context.Context, userID uint64, encryptedSecret []byte) error
+	EnableTOTP(ctx context.Context, userID uint64, step int64, recoveryCodes []string) error
+	UseTOTPStep(ctx context.Context, userID uint64, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID uint64, code string) (bool, error)
+	DisableTOTP(ctx context.Context, userID uint64) error
+	Sessions(ctx context.Context, userID uint64, currentToken string) ([]*Session, error)
+	RevokeSession(ctx context.Context, userID uint64, publicID string) error
+	RevokeOtherSessions(ctx context.Context, userID uint64, currentToken string) (int64, error)
+	LinkIdentity(ctx context.Context, identity Identity) (*gen.User, error)
+	ReplacePassword(ctx context.Context, userID uint64, oldHash, newHash string) (bool, error)
+	CreateRefreshToken(ctx context.Context, userID uint64, ttl time.Duration) (token, familyID string, err error)
+	RotateRefreshToken(ctx context.Context, token string, ttl time.Duration) (userID uint64, familyID, newToken string, err error)
+	RevokeRefreshToken(ctx context.Context, token string) error
+	RevokeRefreshTokens(ctx context.Context, userID uint64, exceptFamilyID string) (int64, error)
+	UpdateProfile(ctx context.Context, userID uint64, req UpdateProfileRequest) (*gen.User, error)
+	ScheduleDeletion(ctx context.Context, userID uint64, deleteAfter time.Time) error
+	CancelDeletion(ctx context.Context, userID uint64) (bool, error)
+	PurgeDeletedAccounts(ctx context.Context) (int64, error)
+	AuditEntries(ctx context.Context, actorID uint64) ([]middleware.Event, error)
+	SaveAudit(ctx context.Context, ev middleware.Event) error
+	ListUsers(ctx context.Context, f *UserFilter) ([]*gen.User, int, error)
+	DisableUser(ctx context.Context, userID uint64) error
+	EnableUser(ctx context.Context, userID uint64) error
+	MarkVerified(ctx context.Context, userID uint64) error
+	IsDisabled(ctx context.Context, userID uint64) (bool, error)
 }
 
-func (r *repo) Register(ctx context.Context, firstName, lastName, email, hashedPassword string) error {
-	err := r.ent.User.Create().
+func (r *repo) Register(ctx context.Context, firstName, lastName, email, hashedPassword string) (*gen.User, error) {
+	u, err := r.ent.User.Create().
 		SetFirstName(firstName).
 		SetLastName(lastName).
 		SetEmail(email).
 		SetPassword(hashedPassword).
-		Exec(ctx)
+		Save(ctx)
 	if err != nil {
 		if gen.IsConstraintError(err) {
-			return ErrEmailNotAvailable
+			return nil, ErrEmailNotAvailable
 		}
-		return err
+		return nil, err
 	}
 
-	return nil
+	return u, nil
 }
 
 func (r *repo) Login(ctx context.Context, req LoginRequest) (*gen.User, bool, error) {
@@ -65,32 +136,35 @@ func (r *repo) Login(ctx context.Context, req LoginRequest) (*gen.User, bool, er
 	return u, match, nil
 }
 
-func (r *repo) Logout(ctx context.Context, userID uint64) (bool, error) {
-	var found bool
-	rows := r.db.QueryRowContext(ctx, `
-			   SELECT CASE
-			   WHEN EXISTS(SELECT *
-						   FROM sessions
-						   WHERE sessions.user_id = $1)
-				   THEN true
-			   ELSE false
-			   END
-	;
-	`, userID)
-	err := rows.Scan(&found)
+// ReplacePassword sets a new password hash only if stored hash is still
+// oldHash, so that a password changed in the meantime is not overwritten.
+func (r *repo) ReplacePassword(ctx context.Context, userID uint64, oldHash, newHash string) (bool, error) {
+	updated, err := r.ent.User.Update().
+		Where(user.ID(userID), user.PasswordEQ(oldHash)).
+		SetPassword(newHash).
+		Save(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	if !found {
-		return false, ErrNotLoggedIn
+	return updated == 1, nil
+}
+
+func (r *repo) Logout(ctx context.Context, userID uint64) (bool, error) {
+	deleted, err := r.sessions.DeleteUserCtx(ctx, userID, "")
+	if err != nil {
+		return false, err
 	}
 
-	_, err = r.ent.Session.Delete().Where(session.UserIDEQ(userID)).Exec(ctx)
+	revoked, err := r.RevokeRefreshTokens(ctx, userID, "")
 	if err != nil {
 		return false, err
 	}
 
+	if deleted == 0 && revoked == 0 {
+		return false, ErrNotLoggedIn
+	}
+
 	return true, nil
 }
 
@@ -100,19 +174,941 @@ func (r *repo) Csrf(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	err = r.session.CtxStore.CommitCtx(ctx, token, []byte("csrf_token"), time.Now().Add(r.session.Lifetime))
+	err = r.sessions.CommitCtx(ctx, token, csrf.Data, time.Now().Add(r.session.Lifetime))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (r *repo) FindByEmail(ctx context.Context, email string) (*gen.User, error) {
+	return r.ent.User.Query().Where(user.EmailEqualFold(email)).First(ctx)
+}
+
+// CreateToken issues a new single-use token for the given purpose. Any
+// previous token of the same purpose for this user is discarded so that only
+// the latest one sent out is usable. Only the hash of the token is stored.
+func (r *repo) CreateToken(ctx context.Context, userID uint64, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return "", err
 	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2
+		`, userID, purpose)
+	if err != nil {
+		return "", fmt.Errorf("deleting previous tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_tokens (token, user_id, purpose, expiry) VALUES ($1, $2, $3, $4)
+		`, hashToken(token), userID, purpose, time.Now().Add(ttl))
+	if err != nil {
+		return "", fmt.Errorf("inserting token: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
 
 	return token, nil
 }
 
+// Verify consumes an email verification token and stamps the owner's
+// `verified_at` column.
+func (r *repo) Verify(ctx context.Context, token string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeToken(ctx, tx, token, purposeVerification)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET verified_at = current_timestamp WHERE id = $1 AND verified_at IS NULL
+		`, userID)
+	if err != nil {
+		return fmt.Errorf("stamping verified_at: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword consumes a password reset token, replaces the owner's
+// password and logs the owner out of every session.
+func (r *repo) ResetPassword(ctx context.Context, token, hashedPassword string) (uint64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeToken(ctx, tx, token, purposePasswordReset)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET password = $1 WHERE id = $2`, hashedPassword, userID)
+	if err != nil {
+		return 0, fmt.Errorf("updating password: %w", err)
+	}
+
+	err = revokeRefreshTokens(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return userID, r.deleteSessions(ctx, userID)
+}
+
+// CreateAPIKey issues a new API key. The plain key is returned only once
+// here. Like reset and verification tokens, only its SHA-256 hash is stored
+// since the key stays valid for a long time.
+func (r *repo) CreateAPIKey(ctx context.Context, userID uint64, name string, scopes []string, expiresAt *time.Time) (*gen.APIKey, string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	key := apiKeyPrefix + token
+
+	created, err := r.ent.APIKey.Create().
+		SetUserID(userID).
+		SetName(name).
+		SetToken(hashToken(key)).
+		SetPrefix(key[:apiKeyPrefixLength]).
+		SetScopes(scopes).
+		SetNillableExpiresAt(expiresAt).
+		SetCreatedAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return created, key, nil
+}
+
+func (r *repo) ListAPIKeys(ctx context.Context, userID uint64) ([]*gen.APIKey, error) {
+	return r.ent.APIKey.Query().
+		Where(apikey.UserIDEQ(userID)).
+		Order(gen.Asc(apikey.FieldID)).
+		All(ctx)
+}
+
+// RevokeAPIKey deletes an API key. A key belonging to another user is
+// reported as not found.
+func (r *repo) RevokeAPIKey(ctx context.Context, userID, id uint64) error {
+	deleted, err := r.ent.APIKey.Delete().
+		Where(apikey.IDEQ(id), apikey.UserIDEQ(userID)).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// FindAPIKey returns the owner and scopes of an unexpired API key, and
+// records its use.
+func (r *repo) FindAPIKey(ctx context.Context, key string) (uint64, []string, bool, error) {
+	k, err := r.ent.APIKey.Query().
+		Where(
+			apikey.TokenEQ(hashToken(key)),
+			apikey.Or(apikey.ExpiresAtIsNil(), apikey.ExpiresAtGT(time.Now())),
+		).
+		Only(ctx)
+	if err != nil {
+		if gen.IsNotFound(err) {
+			return 0, nil, false, nil
+		}
+		return 0, nil, false, err
+	}
+
+	now := time.Now()
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) > lastUsedInterval {
+		err = r.ent.APIKey.UpdateOneID(k.ID).SetLastUsedAt(now).Exec(ctx)
+		if err != nil {
+			return 0, nil, false, fmt.Errorf("updating last_used_at: %w", err)
+		}
+	}
+
+	return k.UserID, k.Scopes, true, nil
+}
+
+func (r *repo) FindByID(ctx context.Context, userID uint64) (*gen.User, error) {
+	return r.ent.User.Get(ctx, userID)
+}
+
+// SetTOTPSecret stores a new, not yet confirmed, TOTP secret. Enrolling again
+// before confirming replaces the previous secret.
+func (r *repo) SetTOTPSecret(ctx context.Context, userID uint64, encryptedSecret []byte) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users SET totp_secret = $1, totp_last_step = NULL
+		WHERE id = $2 AND totp_enabled_at IS NULL
+		`, encryptedSecret, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTwoFactorEnabled
+	}
+
+	return nil
+}
+
+// EnableTOTP turns on two-factor authentication once user has proven their
+// authenticator works, and replaces any previous recovery codes. Only hashes
+// of the recovery codes are stored.
+func (r *repo) EnableTOTP(ctx context.Context, userID uint64, step int64, recoveryCodes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users SET totp_enabled_at = current_timestamp, totp_last_step = $1
+		WHERE id = $2 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+		`, step, userID)
+	if err != nil {
+		return fmt.Errorf("enabling totp: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTwoFactorEnabled
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("deleting recovery codes: %w", err)
+	}
+
+	for _, code := range recoveryCodes {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO user_recovery_codes (code, user_id) VALUES ($1, $2)
+			`, hashToken(code), userID)
+		if err != nil {
+			return fmt.Errorf("inserting recovery code: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records the time step of an accepted code. It returns false if
+// that step, or a later one, has already been used so that a code cannot be
+// replayed.
+func (r *repo) UseTOTPStep(ctx context.Context, userID uint64, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users SET totp_last_step = $1
+		WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)
+		`, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// UseRecoveryCode deletes a recovery code so that it can only be used once.
+func (r *repo) UseRecoveryCode(ctx context.Context, userID uint64, code string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_recovery_codes WHERE code = $1 AND user_id = $2
+		`, hashToken(code), userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *repo) DisableTOTP(ctx context.Context, userID uint64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+		WHERE id = $1
+		`, userID)
+	if err != nil {
+		return fmt.Errorf("disabling totp: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("deleting recovery codes: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Sessions lists active sessions of a user. The session making the request is
+// marked as current by comparing token hashes, so tokens never leave the
+// store.
+func (r *repo) Sessions(ctx context.Context, userID uint64, currentToken string) ([]*Session, error) {
+	stored, err := r.sessions.UserSessionsCtx(ctx, userID, currentToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*Session
+	for _, s := range stored {
+		// CSRF tokens are kept alongside sessions but are not devices.
+		if bytes.Equal(s.Data, csrf.Data) {
+			continue
+		}
+		sessions = append(sessions, &Session{
+			ID:           s.PublicID,
+			Current:      s.Current,
+			Impersonated: s.Impersonated,
+			IPAddress:    s.IPAddress,
+			UserAgent:    s.UserAgent,
+			CreatedAt:    s.CreatedAt,
+			LastSeenAt:   s.LastSeenAt,
+			Expiry:       s.Expiry,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (r *repo) RevokeSession(ctx context.Context, userID uint64, publicID string) error {
+	deleted, err := r.sessions.DeleteUserSessionCtx(ctx, userID, publicID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions logs a user out everywhere except the current session
+// and returns the number of sessions revoked.
+func (r *repo) RevokeOtherSessions(ctx context.Context, userID uint64, currentToken string) (int64, error) {
+	return r.sessions.DeleteUserCtx(ctx, userID, currentToken)
+}
+
+// deleteSessions logs a user out everywhere. Sessions may not be kept in the
+// database, so this is called once a transaction has been committed.
+func (r *repo) deleteSessions(ctx context.Context, userID uint64) error {
+	_, err := r.sessions.DeleteUserCtx(ctx, userID, "")
+	if err != nil {
+		return fmt.Errorf("deleting sessions: %w", err)
+	}
+
+	return nil
+}
+
+// CreateRefreshToken starts a new refresh token family, which lasts for as
+// long as its tokens keep being rotated before they expire. Only the hash of
+// a token is stored.
+func (r *repo) CreateRefreshToken(ctx context.Context, userID uint64, ttl time.Duration) (string, string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	var familyID string
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, family_id, user_id, expires_at)
+		VALUES ($1, gen_random_uuid(), $2, $3)
+		RETURNING family_id
+		`, hashToken(token), userID, time.Now().Add(ttl)).Scan(&familyID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, familyID, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same
+// family. A token can only be exchanged once. Presenting it again means it
+// has been stolen, either by whoever used it first or by whoever is using it
+// now, so the whole family is revoked and ErrRefreshTokenReused is returned.
+func (r *repo) RotateRefreshToken(ctx context.Context, token string, ttl time.Duration) (uint64, string, string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, "", "", err
+	}
+	defer tx.Rollback()
+
+	var (
+		id        uint64
+		userID    uint64
+		familyID  string
+		expiresAt time.Time
+		usedAt    sql.NullTime
+		revokedAt sql.NullTime
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, expires_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+		`, hashToken(token)).Scan(&id, &userID, &familyID, &expiresAt, &usedAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", "", ErrInvalidToken
+		}
+		return 0, "", "", err
+	}
+
+	switch {
+	case revokedAt.Valid:
+		return 0, "", "", ErrInvalidToken
+	case usedAt.Valid:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE refresh_tokens SET revoked_at = current_timestamp
+			WHERE family_id = $1 AND revoked_at IS NULL
+			`, familyID)
+		if err != nil {
+			return 0, "", "", fmt.Errorf("revoking refresh token family: %w", err)
+		}
+		if err = tx.Commit(); err != nil {
+			return 0, "", "", err
+		}
+		return userID, familyID, "", ErrRefreshTokenReused
+	case time.Now().After(expiresAt):
+		return 0, "", "", ErrInvalidToken
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = current_timestamp WHERE id = $1`, id)
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	newToken, err := generateToken()
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, family_id, user_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+		`, hashToken(newToken), familyID, userID, time.Now().Add(ttl))
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	return userID, familyID, newToken, tx.Commit()
+}
+
+// RevokeRefreshToken revokes the family a refresh token belongs to. Unknown
+// tokens are ignored.
+func (r *repo) RevokeRefreshToken(ctx context.Context, token string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = current_timestamp
+		WHERE family_id = (SELECT family_id FROM refresh_tokens WHERE token_hash = $1)
+		  AND revoked_at IS NULL
+		`, hashToken(token))
+	return err
+}
+
+// RevokeRefreshTokens revokes every refresh token family of a user except
+// exceptFamilyID, and returns how many families were revoked.
+func (r *repo) RevokeRefreshTokens(ctx context.Context, userID uint64, exceptFamilyID string) (int64, error) {
+	var revoked int64
+	err := r.db.QueryRowContext(ctx, `
+		WITH revoked AS (
+			UPDATE refresh_tokens SET revoked_at = current_timestamp
+			WHERE user_id = $1
+			  AND family_id::text <> $2
+			  AND revoked_at IS NULL
+			  AND current_timestamp < expires_at
+			RETURNING family_id
+		)
+		SELECT count(DISTINCT family_id) FROM revoked
+		`, userID, exceptFamilyID).Scan(&revoked)
+
+	return revoked, err
+}
+
+func revokeRefreshTokens(ctx context.Context, tx *sql.Tx, userID uint64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = current_timestamp
+		WHERE user_id = $1 AND revoked_at IS NULL
+		`, userID)
+	if err != nil {
+		return fmt.Errorf("revoking refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateProfile changes the names of a user. Only fields that are not nil are
+// changed.
+func (r *repo) UpdateProfile(ctx context.Context, userID uint64, req UpdateProfileRequest) (*gen.User, error) {
+	update := r.ent.User.UpdateOneID(userID)
+	if req.FirstName != nil {
+		update.SetFirstName(*req.FirstName)
+	}
+	if req.MiddleName != nil {
+		update.SetMiddleName(*req.MiddleName)
+	}
+	if req.LastName != nil {
+		update.SetLastName(*req.LastName)
+	}
+
+	return update.Save(ctx)
+}
+
+// ScheduleDeletion marks an account to be deleted after deleteAfter, and
+// logs the user out everywhere. API keys are deleted right away so that
+// nothing keeps acting on behalf of the account during its grace period.
+func (r *repo) ScheduleDeletion(ctx context.Context, userID uint64, deleteAfter time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users SET delete_after = $1 WHERE id = $2 AND delete_after IS NULL
+		`, deleteAfter, userID)
+	if err != nil {
+		return fmt.Errorf("scheduling deletion: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDeletionScheduled
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM api_keys WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("deleting api keys: %w", err)
+	}
+
+	err = revokeRefreshTokens(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return r.deleteSessions(ctx, userID)
+}
+
+// CancelDeletion keeps an account that is scheduled for deletion. It returns
+// false if there is no deletion to cancel, including one whose grace period
+// has already passed.
+func (r *repo) CancelDeletion(ctx context.Context, userID uint64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users SET delete_after = NULL
+		WHERE id = $1 AND current_timestamp < delete_after
+		`, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// PurgeDeletedAccounts deletes accounts whose grace period has passed, and
+// returns how many were deleted. Everything owned by them in the database goes
+// along through `ON DELETE CASCADE`, while audit entries are kept without an
+// actor. Sessions are deleted separately since they may be kept elsewhere.
+func (r *repo) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		DELETE FROM users WHERE delete_after <= current_timestamp RETURNING id
+		`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var userIDs []uint64
+	for rows.Next() {
+		var userID uint64
+		if err = rows.Scan(&userID); err != nil {
+			return 0, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, userID := range userIDs {
+		if err = r.deleteSessions(ctx, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(userIDs)), nil
+}
+
+// AuditEntries lists changes made by a user, oldest first.
+func (r *repo) AuditEntries(ctx context.Context, actorID uint64) ([]middleware.Event, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT actor_id, COALESCE(impersonated_id, 0), COALESCE(table_row_id, 0), table_name, action,
+		       COALESCE(old_values, ''), COALESCE(new_values, ''), COALESCE(http_method, ''),
+		       COALESCE(url, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+		FROM audits
+		WHERE actor_id = $1
+		ORDER BY id
+		`, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []middleware.Event
+	for rows.Next() {
+		var ev middleware.Event
+		err = rows.Scan(&ev.ActorID, &ev.ImpersonatedID, &ev.TableRowID, &ev.Table, &ev.Action, &ev.OldValues, &ev.NewValues,
+			&ev.HTTPMethod, &ev.URL, &ev.IPAddress, &ev.UserAgent, &ev.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *repo) SaveAudit(ctx context.Context, ev middleware.Event) error {
+	return middleware.SaveEvent(ctx, r.db, ev)
+}
+
+// ListUsers returns a page of users matching the filter, along with the
+// number of users matching it across all pages.
+func (r *repo) ListUsers(ctx context.Context, f *UserFilter) ([]*gen.User, int, error) {
+	var predicates []predicate.User
+	if f.Email != "" {
+		predicates = append(predicates, user.EmailContainsFold(f.Email))
+	}
+	if f.Name != "" {
+		predicates = append(predicates, user.Or(
+			user.FirstNameContainsFold(f.Name),
+			user.MiddleNameContainsFold(f.Name),
+			user.LastNameContainsFold(f.Name),
+		))
+	}
+	if f.Disabled != nil {
+		if *f.Disabled {
+			predicates = append(predicates, user.DisabledAtNotNil())
+		} else {
+			predicates = append(predicates, user.DisabledAtIsNil())
+		}
+	}
+
+	total, err := r.ent.User.Query().Where(predicates...).Count(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get total user records: %w", err)
+	}
+
+	users, err := r.ent.User.Query().
+		Where(predicates...).
+		Limit(f.Base.Limit).
+		Offset(f.Base.Offset).
+		Order(userOrder(f.Base.Sort)...).
+		All(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get user records: %w", err)
+	}
+
+	return users, total, nil
+}
+
+func userOrder(sorts map[string]string) []user.OrderOption {
+	var orderFunc []user.OrderOption
+	for col, ord := range sorts {
+		direction := entsql.OrderAsc()
+		if ord == "DESC" {
+			direction = entsql.OrderDesc()
+		}
+
+		switch col {
+		case user.FieldEmail:
+			orderFunc = append(orderFunc, user.ByEmail(direction))
+		case user.FieldFirstName:
+			orderFunc = append(orderFunc, user.ByFirstName(direction))
+		case user.FieldLastName:
+			orderFunc = append(orderFunc, user.ByLastName(direction))
+		}
+	}
+
+	// Keeps pages stable when sorting by a column with duplicate values.
+	return append(orderFunc, user.ByID())
+}
+
+// DisableUser stops a user from logging in. Every session is logged out and
+// refresh tokens are revoked so that the user is out straight away. API keys
+// are kept, and work again once the user is enabled.
+func (r *repo) DisableUser(ctx context.Context, userID uint64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users SET disabled_at = COALESCE(disabled_at, current_timestamp) WHERE id = $1
+		`, userID)
+	if err != nil {
+		return fmt.Errorf("disabling user: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	err = revokeRefreshTokens(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return r.deleteSessions(ctx, userID)
+}
+
+func (r *repo) EnableUser(ctx context.Context, userID uint64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET disabled_at = NULL WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	return userAffected(res)
+}
+
+// MarkVerified verifies a user's email address without a token, for when
+// support has confirmed it some other way.
+func (r *repo) MarkVerified(ctx context.Context, userID uint64) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users SET verified_at = COALESCE(verified_at, current_timestamp) WHERE id = $1
+		`, userID)
+	if err != nil {
+		return err
+	}
+
+	return userAffected(res)
+}
+
+// IsDisabled implements middleware.AccountChecker. Unknown users are not
+// disabled; they are refused for not existing elsewhere.
+func (r *repo) IsDisabled(ctx context.Context, userID uint64) (bool, error) {
+	var disabled bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT disabled_at IS NOT NULL FROM users WHERE id = $1
+		`, userID).Scan(&disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return disabled, err
+}
+
+func userAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// LinkIdentity returns the user an OIDC identity belongs to. An identity seen
+// for the first time is linked to the user with the same email address, or to
+// a new user if there is none. Provider must have verified the email address
+// before calling this.
+func (r *repo) LinkIdentity(ctx context.Context, identity Identity) (*gen.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var userID uint64
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id FROM user_identities WHERE issuer = $1 AND subject = $2
+		`, identity.Issuer, identity.Subject).Scan(&userID)
+	if err == nil {
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+		return r.ent.User.Get(ctx, userID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("finding identity: %w", err)
+	}
+
+	var (
+		verifiedAt sql.NullTime
+		takenOver  bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, verified_at FROM users WHERE lower(email) = lower($1)
+		`, identity.Email).Scan(&userID, &verifiedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Password is left empty so that this user can only log in through
+		// the provider until they reset it.
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO users (first_name, last_name, email, password, verified_at)
+			VALUES ($1, $2, $3, '', current_timestamp)
+			RETURNING id
+			`, identity.FirstName, identity.LastName, identity.Email).Scan(&userID)
+		if err != nil {
+			return nil, fmt.Errorf("creating user: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("finding user by email: %w", err)
+	case !verifiedAt.Valid:
+		// Anyone could have registered an unverified account with this
+		// address. Its password and every way of staying logged in are
+		// discarded so that whoever registered it cannot get into the
+		// account once it is linked.
+		_, err = tx.ExecContext(ctx, `
+			UPDATE users SET password = '', verified_at = current_timestamp WHERE id = $1
+			`, userID)
+		if err != nil {
+			return nil, fmt.Errorf("verifying user: %w", err)
+		}
+		takenOver = true
+		_, err = tx.ExecContext(ctx, `DELETE FROM api_keys WHERE user_id = $1`, userID)
+		if err != nil {
+			return nil, fmt.Errorf("deleting api keys: %w", err)
+		}
+		err = revokeRefreshTokens(ctx, tx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_identities (user_id, issuer, subject, email) VALUES ($1, $2, $3, $4)
+		`, userID, identity.Issuer, identity.Subject, identity.Email)
+	if err != nil {
+		return nil, fmt.Errorf("linking identity: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if takenOver {
+		if err = r.deleteSessions(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.ent.User.Get(ctx, userID)
+}
+
+// FindByToken returns the owner of a valid token without consuming it.
+func (r *repo) FindByToken(ctx context.Context, token, purpose string) (*gen.User, error) {
+	var userID uint64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id FROM user_tokens
+		WHERE token = $1
+		  AND purpose = $2
+		  AND current_timestamp < expiry
+		`, hashToken(token), purpose).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return r.ent.User.Get(ctx, userID)
+}
+
+// consumeToken deletes a valid token, making it unusable for a second time,
+// and returns its owner.
+func consumeToken(ctx context.Context, tx *sql.Tx, token, purpose string) (uint64, error) {
+	var userID uint64
+	err := tx.QueryRowContext(ctx, `
+		DELETE FROM user_tokens
+		WHERE token = $1
+		  AND purpose = $2
+		  AND current_timestamp < expiry
+		RETURNING user_id
+		`, hashToken(token), purpose).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrInvalidToken
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// NewRepo panics if manager's CtxStore is not a sessionstore.Store, since
+// sessions could not be listed or revoked otherwise.
 func NewRepo(ent *gen.Client, db *sql.DB, manager *scs.SessionManager) *repo {
+	sessions, ok := manager.CtxStore.(sessionstore.Store)
+	if !ok {
+		panic("authentication: session manager must use a sessionstore.Store")
+	}
+
 	return &repo{
-		ent:     ent,
-		db:      db,
-		session: manager,
+		ent:      ent,
+		db:       db,
+		session:  manager,
+		sessions: sessions,
 	}
 }
 
@@ -124,3 +1120,8 @@ func generateToken() (string, error) {
 	}
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
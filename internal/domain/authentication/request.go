@@ -1,5 +1,7 @@
 package authentication
 
+import "time"
+
 type RegisterRequest struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
@@ -10,4 +12,69 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string
 	Password string
+	// RememberMe keeps user logged in for longer, even after the browser is
+	// closed.
+	RememberMe bool `json:"remember_me"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword     string `json:"current_password"`
+	NewPassword         string `json:"new_password"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions"`
+}
+
+// UpdateProfileRequest only changes fields that are present. An empty
+// `middle_name` removes it.
+type UpdateProfileRequest struct {
+	FirstName  *string `json:"first_name"`
+	MiddleName *string `json:"middle_name"`
+	LastName   *string `json:"last_name"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type TokenRequest struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt defaults to config.Authentication.APIKeyExpiry from now.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type TwoFactorLoginRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
 }
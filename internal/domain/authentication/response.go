@@ -1,5 +1,165 @@
 package authentication
 
+import (
+	"time"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	"github.com/gmhafiz/go8/internal/middleware"
+)
+
 type RespondCsrf struct {
 	CsrfToken string `json:"csrf_token"`
 }
+
+// TokenResponse follows the shape of an OAuth 2.0 token response.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TwoFactorRequiredResponse is returned by Login when the password is
+// correct but a second factor is still needed to complete the login.
+type TwoFactorRequiredResponse struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type APIKeyResponse struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse is only returned on creation because it is the one
+// time the plain key is known.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func APIKeyResource(k *gen.APIKey) *APIKeyResponse {
+	scopes := k.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func APIKeyResources(keys []*gen.APIKey) []*APIKeyResponse {
+	resources := make([]*APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		resources = append(resources, APIKeyResource(k))
+	}
+	return resources
+}
+
+type SessionResponse struct {
+	ID           string    `json:"id"`
+	Current      bool      `json:"current"`
+	Impersonated bool      `json:"impersonated"`
+	IPAddress    string    `json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func SessionResources(sessions []*Session) []*SessionResponse {
+	resources := make([]*SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resources = append(resources, &SessionResponse{
+			ID:           s.ID,
+			Current:      s.Current,
+			Impersonated: s.Impersonated,
+			IPAddress:    s.IPAddress,
+			UserAgent:    s.UserAgent,
+			CreatedAt:    s.CreatedAt,
+			LastSeenAt:   s.LastSeenAt,
+			ExpiresAt:    s.Expiry,
+		})
+	}
+	return resources
+}
+
+type ProfileResponse struct {
+	ID               uint64     `json:"id"`
+	FirstName        string     `json:"first_name"`
+	MiddleName       string     `json:"middle_name"`
+	LastName         string     `json:"last_name"`
+	Email            string     `json:"email"`
+	VerifiedAt       *time.Time `json:"verified_at"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	// DeleteAfter is set while the account is scheduled for deletion.
+	DeleteAfter *time.Time `json:"delete_after"`
+}
+
+func ProfileResource(u *gen.User) *ProfileResponse {
+	return &ProfileResponse{
+		ID:               u.ID,
+		FirstName:        u.FirstName,
+		MiddleName:       u.MiddleName,
+		LastName:         u.LastName,
+		Email:            u.Email,
+		VerifiedAt:       u.VerifiedAt,
+		TwoFactorEnabled: u.TotpEnabledAt != nil,
+		DeleteAfter:      u.DeleteAfter,
+	}
+}
+
+type AccountDeletionResponse struct {
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+// ExportResponse is everything kept about a user, as returned by the data
+// export.
+type ExportResponse struct {
+	ExportedAt time.Time          `json:"exported_at"`
+	User       *ProfileResponse   `json:"user"`
+	Sessions   []*SessionResponse `json:"sessions"`
+	Audits     []middleware.Event `json:"audits"`
+}
+
+// UserResponse is a user as seen by admins.
+type UserResponse struct {
+	ProfileResponse
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+func UserResource(u *gen.User) *UserResponse {
+	return &UserResponse{
+		ProfileResponse: *ProfileResource(u),
+		DisabledAt:      u.DisabledAt,
+	}
+}
+
+func UserResources(users []*gen.User) []*UserResponse {
+	resources := make([]*UserResponse, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, UserResource(u))
+	}
+	return resources
+}
@@ -0,0 +1,185 @@
+package authentication
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gmhafiz/go8/config"
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/request"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+	"github.com/gmhafiz/go8/internal/utility/token"
+)
+
+var (
+	ErrRefreshTokenRequired = errors.New("refresh_token is required")
+	ErrTwoFactorRequired    = errors.New("two-factor code is required")
+)
+
+// TokenHandler logs users in with access and refresh tokens instead of a
+// session cookie. Password and second factor are checked the same way as
+// Handler.Login, but both are sent in one request since there is no session
+// to remember a pending login.
+type TokenHandler struct {
+	*Handler
+	cfg    config.Token
+	issuer *token.Issuer
+}
+
+func NewTokenHandler(h *Handler, cfg config.Token, issuer *token.Issuer) *TokenHandler {
+	return &TokenHandler{
+		Handler: h,
+		cfg:     cfg,
+		issuer:  issuer,
+	}
+}
+
+// Token issues an access token and a refresh token in exchange for email and
+// password, plus a TOTP or recovery code if two-factor authentication is
+// enabled.
+func (h *TokenHandler) Token(w http.ResponseWriter, r *http.Request) {
+	var req TokenRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	user, ok := h.checkPassword(w, r, req.Email, req.Password)
+	if !ok {
+		return
+	}
+
+	if user.TotpEnabledAt != nil {
+		if req.Code == "" && req.RecoveryCode == "" {
+			respond.Error(w, http.StatusUnauthorized, ErrTwoFactorRequired)
+			return
+		}
+
+		valid, err := h.checkSecondFactor(ctx, user.ID, TwoFactorLoginRequest{
+			Code:         req.Code,
+			RecoveryCode: req.RecoveryCode,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "checking second factor", "error", err)
+			respond.Error(w, http.StatusInternalServerError, nil)
+			return
+		}
+		if !valid {
+			// Without a pending session to count attempts against, wrong
+			// codes count towards login lockout instead.
+			wait, err := h.lockout.Fail(ctx, req.Email, middleware.ClientIP(r))
+			if err != nil {
+				slog.ErrorContext(ctx, "recording failed login", "error", err)
+			}
+			if wait > 0 {
+				retryAfter(w, wait)
+			}
+			respond.Error(w, http.StatusUnauthorized, ErrInvalidCode)
+			return
+		}
+	}
+
+	if err := h.lockout.Succeed(ctx, req.Email); err != nil {
+		slog.ErrorContext(ctx, "resetting failed logins", "error", err)
+	}
+
+	refreshToken, familyID, err := h.repo.CreateRefreshToken(ctx, user.ID, h.cfg.RefreshExpiry)
+	if err != nil {
+		slog.ErrorContext(ctx, "creating refresh token", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	h.respondTokens(w, r, user.ID, familyID, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new pair of tokens. Each refresh
+// token can only be used once.
+func (h *TokenHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		respond.Error(w, http.StatusBadRequest, ErrRefreshTokenRequired)
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, familyID, refreshToken, err := h.repo.RotateRefreshToken(ctx, req.RefreshToken, h.cfg.RefreshExpiry)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRefreshTokenReused):
+			slog.WarnContext(ctx, "refresh token reused, revoked its family",
+				"event", "refresh_token_reuse",
+				"user_id", userID,
+				"family_id", familyID,
+				"ip_address", middleware.ClientIP(r),
+			)
+			respond.Error(w, http.StatusUnauthorized, ErrInvalidToken)
+		case errors.Is(err, ErrInvalidToken):
+			respond.Error(w, http.StatusUnauthorized, err)
+		default:
+			slog.ErrorContext(ctx, "rotating refresh token", "error", err)
+			respond.Error(w, http.StatusInternalServerError, nil)
+		}
+		return
+	}
+
+	h.respondTokens(w, r, userID, familyID, refreshToken)
+}
+
+// Revoke logs out the device holding a refresh token. Access tokens already
+// issued stay valid until they expire. Like RFC 7009, an unknown token is not
+// an error.
+func (h *TokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		respond.Error(w, http.StatusBadRequest, ErrRefreshTokenRequired)
+		return
+	}
+
+	if err := h.repo.RevokeRefreshToken(r.Context(), req.RefreshToken); err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+// JWKS publishes public keys that access tokens can be verified with.
+func (h *TokenHandler) JWKS(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respond.JSON(w, http.StatusOK, h.issuer.JWKS())
+}
+
+func (h *TokenHandler) respondTokens(w http.ResponseWriter, r *http.Request, userID uint64, familyID, refreshToken string) {
+	accessToken, _, err := h.issuer.Sign(userID, familyID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "signing access token", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respond.JSON(w, http.StatusOK, &TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.cfg.AccessExpiry.Seconds()),
+		RefreshToken: refreshToken,
+	})
+}
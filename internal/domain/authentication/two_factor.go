@@ -0,0 +1,432 @@
+package authentication
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gmhafiz/scs/v2"
+
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/request"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+	"github.com/gmhafiz/go8/internal/utility/totp"
+)
+
+const (
+	sessionPendingUserID   = "pending_user_id"
+	sessionPendingExpiry   = "pending_expiry"
+	sessionPendingAttempts = "pending_attempts"
+	sessionPendingRemember = "pending_remember_me"
+
+	// maxTwoFactorAttempts is how many wrong codes are tolerated before the
+	// pending login is thrown away and password has to be entered again.
+	maxTwoFactorAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+var (
+	ErrTwoFactorUnavailable = errors.New("two-factor authentication is not configured")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication has not been enrolled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrNoPendingLogin       = errors.New("no login is waiting for a second factor, or it has expired")
+	ErrInvalidCode          = errors.New("code is invalid")
+	ErrCodeRequired         = errors.New("code is required")
+	ErrWrongPassword        = errors.New("password is incorrect")
+	ErrSessionRequired      = errors.New("this action requires logging in with a password")
+)
+
+// startTwoFactor parks a user whose password is correct in a pending state.
+// Session is not logged in until LoginTwoFactor receives a valid code.
+func (h *Handler) startTwoFactor(w http.ResponseWriter, r *http.Request, userID uint64, rememberMe bool) {
+	expiresAt := putPendingLogin(r.Context(), h.session, h.cfg.TwoFactorPendingExpiry, userID, rememberMe)
+
+	respond.JSON(w, http.StatusAccepted, &TwoFactorRequiredResponse{
+		TwoFactorRequired: true,
+		ExpiresAt:         expiresAt,
+	})
+}
+
+// putPendingLogin marks session as waiting for a second factor of userID and
+// returns when the wait expires. Whether to remember the user is kept until
+// the login completes.
+func putPendingLogin(ctx context.Context, session *scs.SessionManager, ttl time.Duration, userID uint64, rememberMe bool) time.Time {
+	expiresAt := time.Now().Add(ttl)
+
+	// Whoever the session was acting as before is not logged in anymore.
+	session.Remove(ctx, string(middleware.KeyImpersonatorID))
+	session.Put(ctx, sessionPendingUserID, userID)
+	session.Put(ctx, sessionPendingExpiry, expiresAt.Unix())
+	session.Put(ctx, sessionPendingAttempts, 0)
+	session.Put(ctx, sessionPendingRemember, rememberMe)
+
+	return expiresAt
+}
+
+// LoginTwoFactor completes a login started by Login using either a TOTP code
+// or one of the recovery codes.
+func (h *Handler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req TwoFactorLoginRequest
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, ok := h.session.Get(ctx, sessionPendingUserID).(uint64)
+	if !ok {
+		respond.Error(w, http.StatusUnauthorized, ErrNoPendingLogin)
+		return
+	}
+
+	if time.Now().Unix() > h.session.GetInt64(ctx, sessionPendingExpiry) {
+		h.clearPending(ctx)
+		respond.Error(w, http.StatusUnauthorized, ErrNoPendingLogin)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	// A pending login does not outlive a lockout that began after it.
+	ip := middleware.ClientIP(r)
+	wait, err := h.lockout.Wait(ctx, u.Email, ip)
+	if err != nil {
+		slog.ErrorContext(ctx, "checking login lockout", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if wait > 0 {
+		retryAfter(w, wait)
+		respond.Error(w, http.StatusTooManyRequests, ErrTooManyAttempts)
+		return
+	}
+
+	valid, err := h.checkSecondFactor(ctx, userID, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "checking second factor", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if !valid {
+		attempts := h.session.GetInt(ctx, sessionPendingAttempts) + 1
+		if attempts >= maxTwoFactorAttempts {
+			h.clearPending(ctx)
+		} else {
+			h.session.Put(ctx, sessionPendingAttempts, attempts)
+		}
+
+		// Wrong codes count towards login lockout too, so that entering
+		// the password again does not give a fresh set of guesses.
+		wait, err := h.lockout.Fail(ctx, u.Email, ip)
+		if err != nil {
+			slog.ErrorContext(ctx, "recording failed login", "error", err)
+		}
+		if wait > 0 {
+			retryAfter(w, wait)
+		}
+		respond.Error(w, http.StatusUnauthorized, ErrInvalidCode)
+		return
+	}
+
+	if err := h.lockout.Succeed(ctx, u.Email); err != nil {
+		slog.ErrorContext(ctx, "resetting failed logins", "error", err)
+	}
+
+	if err := h.session.RenewToken(ctx); err != nil {
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	rememberMe := h.session.GetBool(ctx, sessionPendingRemember)
+	h.clearPending(ctx)
+	startSession(ctx, h.session, userID, rememberMe)
+
+	respond.Status(w, http.StatusOK)
+}
+
+// EnrollTwoFactor generates a new TOTP secret for current user. It is not
+// used for login until confirmed with ConfirmTwoFactor.
+func (h *Handler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	if h.cipher == nil {
+		respond.Error(w, http.StatusServiceUnavailable, ErrTwoFactorUnavailable)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	encrypted, err := h.cipher.Encrypt(secret)
+	if err != nil {
+		slog.ErrorContext(ctx, "encrypting totp secret", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	err = h.repo.SetTOTPSecret(ctx, userID, encrypted)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorEnabled) {
+			respond.Error(w, http.StatusConflict, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, &TwoFactorEnrollResponse{
+		Secret: totp.Encode(secret),
+		URI:    totp.URI(h.cfg.TOTPIssuer, u.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor enables two-factor authentication once user proves their
+// authenticator app produces valid codes. Recovery codes are returned only
+// in this response.
+func (h *Handler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req.Code = strings.Trim(req.Code, " ")
+	if req.Code == "" {
+		respond.Error(w, http.StatusBadRequest, ErrCodeRequired)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if u.TotpEnabledAt != nil {
+		respond.Error(w, http.StatusConflict, ErrTwoFactorEnabled)
+		return
+	}
+	if u.TotpSecret == nil {
+		respond.Error(w, http.StatusBadRequest, ErrTwoFactorNotEnrolled)
+		return
+	}
+
+	secret, err := h.decryptSecret(u.TotpSecret)
+	if err != nil {
+		slog.ErrorContext(ctx, "decrypting totp secret", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	step, ok := totp.Validate(secret, req.Code, time.Now())
+	if !ok {
+		respond.Error(w, http.StatusBadRequest, ErrInvalidCode)
+		return
+	}
+
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	normalised := make([]string, 0, len(codes))
+	for _, code := range codes {
+		normalised = append(normalised, normaliseRecoveryCode(code))
+	}
+
+	err = h.repo.EnableTOTP(ctx, userID, step, normalised)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorEnabled) {
+			respond.Error(w, http.StatusConflict, err)
+			return
+		}
+		slog.ErrorContext(ctx, "enabling totp", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, &RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor turns off two-factor authentication. Current password is
+// required so that an unattended logged-in browser is not enough, and wrong
+// guesses count towards login lockout.
+func (h *Handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		respond.Error(w, http.StatusForbidden, err)
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	u, err := h.repo.FindByID(ctx, userID)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+	if u.TotpEnabledAt == nil {
+		respond.Error(w, http.StatusBadRequest, ErrTwoFactorNotEnabled)
+		return
+	}
+
+	if !h.confirmPassword(w, r, u, req.Password) {
+		return
+	}
+	h.rehash(ctx, u, req.Password)
+
+	if err := h.repo.DisableTOTP(ctx, userID); err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusOK)
+}
+
+func (h *Handler) checkSecondFactor(ctx context.Context, userID uint64, req TwoFactorLoginRequest) (bool, error) {
+	req.Code = strings.Trim(req.Code, " ")
+	req.RecoveryCode = normaliseRecoveryCode(req.RecoveryCode)
+
+	switch {
+	case req.Code != "":
+		u, err := h.repo.FindByID(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		if u.TotpEnabledAt == nil || u.TotpSecret == nil {
+			return false, nil
+		}
+
+		secret, err := h.decryptSecret(u.TotpSecret)
+		if err != nil {
+			return false, err
+		}
+
+		step, ok := totp.Validate(secret, req.Code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		return h.repo.UseTOTPStep(ctx, userID, step)
+	case req.RecoveryCode != "":
+		return h.repo.UseRecoveryCode(ctx, userID, req.RecoveryCode)
+	default:
+		return false, nil
+	}
+}
+
+func (h *Handler) decryptSecret(encrypted []byte) ([]byte, error) {
+	if h.cipher == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+	return h.cipher.Decrypt(encrypted)
+}
+
+func (h *Handler) clearPending(ctx context.Context) {
+	h.session.Remove(ctx, sessionPendingUserID)
+	h.session.Remove(ctx, sessionPendingExpiry)
+	h.session.Remove(ctx, sessionPendingAttempts)
+	h.session.Remove(ctx, sessionPendingRemember)
+}
+
+// logIn marks session as logged in by userID, ending any impersonation.
+func logIn(ctx context.Context, session *scs.SessionManager, userID uint64) {
+	session.Remove(ctx, string(middleware.KeyImpersonatorID))
+	session.Put(ctx, string(middleware.KeyID), userID)
+}
+
+// startSession logs userID in with a session of its own deadline. Users who
+// ask to be remembered get a longer one and a cookie that outlives the
+// browser.
+func startSession(ctx context.Context, session *scs.SessionManager, userID uint64, rememberMe bool) {
+	session.RememberMe(ctx, rememberMe)
+	session.Remove(ctx, string(middleware.KeySessionDeadline))
+	logIn(ctx, session, userID)
+}
+
+// sessionUserID returns the ID of user logged in with a session cookie.
+// Security settings cannot be changed using an API key, nor by an admin
+// impersonating the user.
+func sessionUserID(ctx context.Context) (uint64, error) {
+	if middleware.IsAPIKey(ctx) {
+		return 0, ErrSessionRequired
+	}
+	if _, ok := middleware.ImpersonatorID(ctx); ok {
+		return 0, ErrImpersonating
+	}
+
+	userID, ok := ctx.Value(middleware.KeyID).(uint64)
+	if !ok {
+		return 0, ErrNotLoggedIn
+	}
+
+	return userID, nil
+}
+
+// generateRecoveryCodes returns codes formatted as `xxxxx-xxxxx` for
+// readability.
+func generateRecoveryCodes() ([]string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(enc.EncodeToString(b))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+
+	return codes, nil
+}
+
+// normaliseRecoveryCode lets users type recovery codes without the dash and
+// in any case.
+func normaliseRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -2,6 +2,7 @@ package author
 
 import (
 	"net/url"
+	"strings"
 
 	"github.com/gmhafiz/go8/internal/utility/filter"
 )
@@ -12,11 +13,14 @@ type Filter struct {
 	FirstName  string `json:"first_name"`
 	MiddleName string `json:"middle_name"`
 	LastName   string `json:"last_name"`
+
+	// Query is a name to search for across first, middle and last names.
+	Query string `json:"q"`
 }
 
 func Filters(queries url.Values) *Filter {
 	f := filter.New(queries)
-	if queries.Has("first_name") || queries.Has("last_name") {
+	if queries.Has("q") || queries.Has("first_name") || queries.Has("last_name") {
 		f.Search = true
 	}
 	return &Filter{
@@ -25,5 +29,23 @@ func Filters(queries url.Values) *Filter {
 		FirstName:  queries.Get("first_name"),
 		MiddleName: queries.Get("middle_name"),
 		LastName:   queries.Get("last_name"),
+
+		Query: queries.Get("q"),
+	}
+}
+
+// SearchQuery returns the full name to search for. Without Query, it is made
+// of the names given separately.
+func (f *Filter) SearchQuery() string {
+	if f.Query != "" {
+		return strings.TrimSpace(f.Query)
+	}
+
+	var names []string
+	for _, name := range []string{f.FirstName, f.MiddleName, f.LastName} {
+		if name != "" {
+			names = append(names, name)
+		}
 	}
+	return strings.Join(names, " ")
 }
@@ -12,11 +12,15 @@ import (
 	"github.com/go-playground/validator/v10"
 	"go.opentelemetry.io/otel"
 
+	"github.com/gmhafiz/go8/ent/gen"
 	"github.com/gmhafiz/go8/internal/domain/author"
 	"github.com/gmhafiz/go8/internal/domain/author/usecase"
 	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/etag"
+	"github.com/gmhafiz/go8/internal/utility/filter"
 	"github.com/gmhafiz/go8/internal/utility/message"
 	"github.com/gmhafiz/go8/internal/utility/param"
+	"github.com/gmhafiz/go8/internal/utility/request"
 	"github.com/gmhafiz/go8/internal/utility/respond"
 	"github.com/gmhafiz/go8/internal/utility/validate"
 )
@@ -79,10 +83,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 // @Param page query string false "page number"
 // @Param limit query string false "limit of result"
 // @Param offset query string false "result offset"
+// @Param q query string false "search by full name, tolerating typos"
 // @Param first_name query string false "search by first_name"
 // @Param last_name query string false "search by last_name"
 // @Param sort query string false "sort by fields name. E.g. first_name,asc"
+// @Param after query string false "next_cursor of the previous page, not for search"
+// @Param before query string false "prev_cursor of the next page, not for search"
 // @Success 200 {object} respond.Standard
+// @Failure 400 {string} Bad Request
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/author [get]
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
@@ -93,6 +101,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	slog.InfoContext(ctx, "listing authors")
 
 	filters := author.Filters(r.URL.Query())
+	if err := filters.Base.Err(); err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if filters.Base.Search && filters.Base.HasCursor() {
+		respond.Error(w, http.StatusBadRequest, filter.ErrSearchCursor)
+		return
+	}
 
 	authors, total, err := h.useCase.List(ctx, filters)
 	if err != nil {
@@ -101,22 +117,39 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	meta := respond.Meta{
+		Size:  len(authors),
+		Total: total,
+	}
+	if filters.Base.Search {
+		meta.Scores = make(map[uint64]float64, len(authors))
+		for _, a := range authors {
+			meta.Scores[a.ID] = a.Score
+		}
+	} else {
+		// Search results are ranked, so only plain lists page by cursor.
+		orders := filters.Base.Orders(author.SortKeys)
+		meta.NextCursor, meta.PrevCursor = filters.Base.Cursors(len(authors), func(i int) filter.Cursor {
+			return authors[i].Cursor(orders)
+		})
+		respond.Links(w, r, meta)
+	}
+
 	respond.JSON(w, http.StatusOK, respond.Standard{
 		Data: author.Resources(authors),
-		Meta: respond.Meta{
-			Size:  len(authors),
-			Total: total,
-		},
+		Meta: meta,
 	})
 }
 
 // Get an author by its ID
 // @Summary Get an Author
-// @Description Get an author by its id.
+// @Description Get an author by its id. Its ETag can be sent back in If-None-Match to only get the author if it has changed, or in If-Match to only change it if it has not.
 // @Accept json
 // @Produce json
 // @Param id path int true "author ID"
+// @Param If-None-Match header string false "ETag of the author already held"
 // @Success 200 {object} gen.Author
+// @Success 304 "Not Modified"
 // @Failure 400 {string} Bad Request
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/author/{id} [get]
@@ -136,6 +169,11 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if etag.NotModified(w, r, res.Revision()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	respond.JSON(w, http.StatusOK, author.Resource(res))
 }
 
@@ -145,8 +183,11 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param Author body author.UpdateRequest true "Author Request"
+// @Param If-Match header string false "ETag the author must still have"
 // @Success 200 {object} gen.Author
 // @Failure 400 {string} Bad Request
+// @Failure 412 {string} Precondition Failed
+// @Failure 428 {string} Precondition Required
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/author/{id} [put]
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
@@ -166,23 +207,119 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	req.ID = id
 
+	ctx, err = h.precondition(ctx, r, id)
+	if err != nil {
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	updated, err := h.useCase.Update(ctx, &req)
 	if err != nil {
 		log.Println(err)
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
 		respond.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	etag.Set(w, updated.Revision())
 	respond.JSON(w, http.StatusOK, author.Resource(updated))
 }
 
+// Patch an author
+// @Summary Patch an Author
+// @Description Change some fields of an author with a JSON merge patch (RFC 7396). Fields left out keep their value, and middle_name may be set to null to remove it.
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "author ID"
+// @Param Author body author.PatchRequest true "Fields to change"
+// @Param If-Match header string false "ETag the author must still have"
+// @Success 200 {object} author.GetResponse
+// @Failure 400 {string} Bad Request
+// @Failure 404 {string} Not Found
+// @Failure 412 {string} Precondition Failed
+// @Failure 415 {string} Unsupported Media Type
+// @Failure 428 {string} Precondition Required
+// @Failure 500 {string} Internal Server Error
+// @router /api/v1/author/{id} [patch]
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := param.UInt64(r, "id")
+	if id == 0 || err != nil {
+		respond.Error(w, http.StatusBadRequest, errors.New("id is required"))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), middleware.CacheURL, r.URL.String())
+
+	current, err := h.useCase.Read(ctx, id)
+	if err != nil {
+		if gen.IsNotFound(err) {
+			respond.Error(w, http.StatusNotFound, message.ErrNoRecord)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx, ok := etag.IfMatch(ctx, r, current.Revision())
+	if !ok {
+		respond.Error(w, http.StatusPreconditionFailed, message.ErrPreconditionFailed)
+		return
+	}
+
+	before := author.Patchable(current)
+	var req author.PatchRequest
+	err = request.DecodeMergePatch(w, r, before, &req)
+	if err != nil {
+		if errors.Is(err, request.ErrUnsupportedPatch) {
+			respond.Error(w, http.StatusUnsupportedMediaType, err)
+			return
+		}
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	req.ID = id
+
+	errs := validate.Validate(h.validate, req)
+	if errs != nil {
+		respond.Errors(w, http.StatusBadRequest, errs)
+		return
+	}
+	req.Diff(before)
+
+	patched, err := h.useCase.Patch(ctx, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, message.ErrNoRecord):
+			respond.Error(w, http.StatusNotFound, err)
+		case errors.Is(err, message.ErrPreconditionFailed):
+			respond.Error(w, http.StatusPreconditionFailed, err)
+		default:
+			respond.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	etag.Set(w, patched.Revision())
+	respond.JSON(w, http.StatusOK, author.Resource(patched))
+}
+
 // Delete an author by its ID
 // @Summary Delete an Author
 // @Description Delete an author by its id.
 // @Accept json
 // @Produce json
 // @Param id path int true "author ID"
+// @Param If-Match header string false "ETag the author must still have"
 // @Success 200 "Ok"
+// @Failure 412 {string} Precondition Failed
+// @Failure 428 {string} Precondition Required
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/author/{id} [delete]
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -194,6 +331,16 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	ctx := context.WithValue(r.Context(), middleware.CacheURL, r.URL.String())
 
+	ctx, err = h.precondition(ctx, r, id)
+	if err != nil {
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	err = h.useCase.Delete(ctx, id)
 	if err != nil {
 		log.Println(err)
@@ -201,7 +348,116 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 			respond.Error(w, http.StatusBadRequest, err)
 			return
 		}
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+// Trash lists deleted authors
+// @Summary Shows deleted authors
+// @Description Lists deleted authors that have not been purged yet, most recently deleted first.
+// @Accept json
+// @Produce json
+// @Param page query string false "page number"
+// @Param limit query string false "limit of result"
+// @Param offset query string false "result offset"
+// @Param after query string false "next_cursor of the previous page"
+// @Param before query string false "prev_cursor of the next page"
+// @Success 200 {object} respond.Standard
+// @Failure 400 {string} Bad Request
+// @Failure 500 {string} Internal Server Error
+// @router /api/v1/author/trash [get]
+func (h *Handler) Trash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filters := author.Filters(r.URL.Query())
+	if err := filters.Base.Err(); err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	authors, total, err := h.useCase.Trash(ctx, filters)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	meta := respond.Meta{
+		Size:  len(authors),
+		Total: total,
+	}
+	orders := []filter.Order{author.TrashOrder}
+	meta.NextCursor, meta.PrevCursor = filters.Base.Cursors(len(authors), func(i int) filter.Cursor {
+		return authors[i].Cursor(orders)
+	})
+	respond.Links(w, r, meta)
+
+	respond.JSON(w, http.StatusOK, respond.Standard{
+		Data: author.Resources(authors),
+		Meta: meta,
+	})
+}
+
+// Restore a deleted author by its ID
+// @Summary Restore an Author
+// @Description Restore a deleted author by its id.
+// @Accept json
+// @Produce json
+// @Param id path int true "author ID"
+// @Success 200 {object} author.GetResponse
+// @Failure 400 {string} Bad Request
+// @Failure 404 {string} Not Found
+// @Failure 500 {string} Internal Server Error
+// @router /api/v1/author/{id}/restore [post]
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := param.UInt64(r, "id")
+	if id == 0 || err != nil {
+		respond.Error(w, http.StatusBadRequest, errors.New("id is required"))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), middleware.CacheURL, r.URL.String())
+
+	restored, err := h.useCase.Restore(ctx, id)
+	if err != nil {
+		if errors.Is(err, message.ErrNoRecord) {
+			respond.Error(w, http.StatusNotFound, err)
+			return
+		}
+		slog.ErrorContext(ctx, err.Error())
 		respond.Error(w, http.StatusInternalServerError, err)
 		return
 	}
+
+	respond.JSON(w, http.StatusOK, author.Resource(restored))
+}
+
+// precondition checks the If-Match header of r, if any, against the author
+// as it is now. The returned context only lets the write through if the
+// author has not changed again by the time it is made.
+func (h *Handler) precondition(ctx context.Context, r *http.Request, authorID uint64) (context.Context, error) {
+	if r.Header.Get("If-Match") == "" {
+		return ctx, nil
+	}
+
+	current, err := h.useCase.Read(ctx, authorID)
+	if err != nil {
+		if gen.IsNotFound(err) {
+			// There is no author left to match.
+			return nil, message.ErrPreconditionFailed
+		}
+		return nil, err
+	}
+
+	ctx, ok := etag.IfMatch(ctx, r, current.Revision())
+	if !ok {
+		return nil, message.ErrPreconditionFailed
+	}
+
+	return ctx, nil
 }
@@ -17,10 +17,13 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/gmhafiz/go8/ent/gen"
 	"github.com/gmhafiz/go8/internal/domain/author"
 	"github.com/gmhafiz/go8/internal/domain/author/usecase"
 	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/etag"
 	"github.com/gmhafiz/go8/internal/utility/message"
+	"github.com/gmhafiz/go8/internal/utility/request"
 	"github.com/gmhafiz/go8/internal/utility/respond"
 )
 
@@ -272,6 +275,7 @@ func TestHandler_List(t *testing.T) {
 		status int
 		size   int
 		total  int
+		scores map[uint64]float64
 		error
 	}
 
@@ -335,6 +339,35 @@ func TestHandler_List(t *testing.T) {
 				total:  1,
 			},
 		},
+		{
+			name: "search returns scores",
+			args: args{
+				uri: "/api/v1/author?q=Jhon+Smtih",
+			},
+			want: want{
+				usecase: struct {
+					authors []*author.Schema
+					total   int
+					error
+				}{
+					authors: []*author.Schema{
+						{
+							ID:        1,
+							FirstName: "John",
+							LastName:  "Smith",
+							Score:     0.16,
+						},
+					},
+					total: 1,
+					error: nil,
+				},
+				status: http.StatusOK,
+				error:  nil,
+				size:   1,
+				total:  1,
+				scores: map[uint64]float64{1: 0.16},
+			},
+		},
 		{
 			name: "simulate lower layer error",
 			args: args{
@@ -387,6 +420,7 @@ func TestHandler_List(t *testing.T) {
 
 				assert.Equal(t, test.want.size, got.Meta.Size)
 				assert.Equal(t, test.want.total, got.Meta.Total)
+				assert.Equal(t, test.want.scores, got.Meta.Scores)
 			} else {
 				b, err := io.ReadAll(ww.Body)
 				assert.Nil(t, err)
@@ -666,6 +700,103 @@ func TestHandler_Update(t *testing.T) {
 	}
 }
 
+func TestHandler_Patch(t *testing.T) {
+	stored := &author.Schema{
+		ID:         1,
+		FirstName:  "First",
+		MiddleName: "Middle",
+		LastName:   "Last",
+	}
+	middleName := stored.MiddleName
+
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		readErr     error
+		// patched tells if the usecase is asked to write the author, and
+		// changed which of its columns.
+		patched    bool
+		changed    []string
+		middleName *string
+		status     int
+	}{
+		{
+			name:        "keeps middle name when left out",
+			contentType: request.MergePatchContentType,
+			patch:       `{"first_name":"Changed"}`,
+			patched:     true,
+			changed:     []string{"first_name"},
+			middleName:  &middleName,
+			status:      http.StatusOK,
+		},
+		{
+			name:        "null clears middle name",
+			contentType: request.MergePatchContentType,
+			patch:       `{"middle_name":null}`,
+			patched:     true,
+			changed:     []string{"middle_name"},
+			status:      http.StatusOK,
+		},
+		{
+			name:        "merged author is validated",
+			contentType: request.MergePatchContentType,
+			patch:       `{"last_name":null}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported patch format",
+			contentType: "text/plain",
+			patch:       `{"first_name":"Changed"}`,
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "no such author",
+			contentType: request.MergePatchContentType,
+			patch:       `{"first_name":"Changed"}`,
+			readErr:     &gen.NotFoundError{},
+			status:      http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRequest(http.MethodPatch, "/api/v1/author/1", bytes.NewBufferString(tt.patch))
+			rr.Header.Set("Content-Type", tt.contentType)
+			ww := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			rr = rr.WithContext(context.WithValue(rr.Context(), chi.RouteCtxKey, rctx))
+
+			patched := false
+			uc := &usecase.AuthorMock{
+				ReadFunc: func(ctx context.Context, authorID uint64) (*author.Schema, error) {
+					if tt.readErr != nil {
+						return nil, tt.readErr
+					}
+					a := *stored
+					return &a, nil
+				},
+				PatchFunc: func(ctx context.Context, req *author.PatchRequest) (*author.Schema, error) {
+					patched = true
+					assert.Equal(t, uint64(1), req.ID)
+					assert.Equal(t, tt.changed, req.Changed)
+					assert.Equal(t, tt.middleName, req.MiddleName)
+					return stored, nil
+				},
+			}
+
+			h := RegisterHTTPEndPoints(chi.NewRouter(), validator.New(), uc)
+
+			h.Patch(ww, rr)
+
+			assert.Equal(t, tt.status, ww.Code)
+			assert.Equal(t, tt.patched, patched)
+		})
+	}
+}
+
 func TestHandler_Delete(t *testing.T) {
 	type args struct {
 		authorID int
@@ -752,3 +883,198 @@ func TestHandler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_Restore(t *testing.T) {
+	type args struct {
+		authorID int
+	}
+
+	type want struct {
+		error
+		status int
+	}
+
+	type test struct {
+		name string
+		args
+		want
+	}
+
+	tests := []test{
+		{
+			name: "simple",
+			args: args{
+				authorID: 1,
+			},
+			want: want{
+				error:  nil,
+				status: http.StatusOK,
+			},
+		},
+		{
+			name: "paramAuthorID not provided",
+			args: args{
+				authorID: 0,
+			},
+			want: want{
+				error:  nil,
+				status: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "not in trash",
+			args: args{
+				authorID: 999,
+			},
+			want: want{
+				error:  message.ErrNoRecord,
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "Catch-all other errors",
+			args: args{
+				authorID: 1,
+			},
+			want: want{
+				error:  errors.New("all other errors"),
+				status: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			rr := httptest.NewRequest(http.MethodPost, "/api/v1/author/{id}/restore", nil)
+			ww := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", strconv.Itoa(test.args.authorID))
+
+			rr = rr.WithContext(context.WithValue(rr.Context(), chi.RouteCtxKey, rctx))
+
+			router := chi.NewRouter()
+			val := validator.New()
+
+			uc := &usecase.AuthorMock{
+				RestoreFunc: func(ctx context.Context, authorID uint64) (*author.Schema, error) {
+					if test.want.error != nil {
+						return nil, test.want.error
+					}
+					return &author.Schema{ID: authorID}, nil
+				},
+			}
+
+			h := RegisterHTTPEndPoints(router, val, uc)
+			h.Restore(ww, rr)
+
+			assert.Equal(t, test.status, ww.Code)
+		})
+	}
+}
+
+func TestHandler_Preconditions(t *testing.T) {
+	stored := &author.Schema{
+		ID:         1,
+		FirstName:  "First",
+		MiddleName: "Middle",
+		LastName:   "Last",
+		UpdatedAt:  time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Version:    2,
+	}
+	current := stored.Revision().Tag()
+	stale := etag.Revision{UpdatedAt: stored.UpdatedAt, Version: 1}.Tag()
+
+	update := `{"first_name":"Changed","middle_name":"Middle","last_name":"Last"}`
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		header  string
+		value   string
+		readErr error
+		// writeErr is what the usecase returns if the write is let through,
+		// which must then carry the revision it expects.
+		writeErr error
+		written  bool
+		status   int
+	}{
+		{name: "get not modified", method: http.MethodGet, header: "If-None-Match", value: current, status: http.StatusNotModified},
+		{name: "get modified since", method: http.MethodGet, header: "If-None-Match", value: stale, status: http.StatusOK},
+		{name: "put current", method: http.MethodPut, body: update, header: "If-Match", value: current, written: true, status: http.StatusOK},
+		{name: "put stale", method: http.MethodPut, body: update, header: "If-Match", value: stale, status: http.StatusPreconditionFailed},
+		{name: "put lost race", method: http.MethodPut, body: update, header: "If-Match", value: current, writeErr: message.ErrPreconditionFailed, written: true, status: http.StatusPreconditionFailed},
+		{name: "put deleted author", method: http.MethodPut, body: update, header: "If-Match", value: current, readErr: &gen.NotFoundError{}, status: http.StatusPreconditionFailed},
+		{name: "patch stale", method: http.MethodPatch, body: `{"first_name":"Changed"}`, header: "If-Match", value: stale, status: http.StatusPreconditionFailed},
+		{name: "delete current", method: http.MethodDelete, header: "If-Match", value: current, written: true, status: http.StatusOK},
+		{name: "delete stale", method: http.MethodDelete, header: "If-Match", value: stale, status: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRequest(tt.method, "/api/v1/author/1", bytes.NewBufferString(tt.body))
+			if tt.header != "" {
+				rr.Header.Set(tt.header, tt.value)
+			}
+			ww := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			rr = rr.WithContext(context.WithValue(rr.Context(), chi.RouteCtxKey, rctx))
+
+			written := false
+			write := func(ctx context.Context) error {
+				written = true
+				rev, ok := etag.FromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, stored.Revision(), rev)
+				return tt.writeErr
+			}
+
+			uc := &usecase.AuthorMock{
+				ReadFunc: func(ctx context.Context, authorID uint64) (*author.Schema, error) {
+					if tt.readErr != nil {
+						return nil, tt.readErr
+					}
+					return stored, nil
+				},
+				UpdateFunc: func(ctx context.Context, req *author.UpdateRequest) (*author.Schema, error) {
+					if err := write(ctx); err != nil {
+						return nil, err
+					}
+					return stored, nil
+				},
+				PatchFunc: func(ctx context.Context, req *author.PatchRequest) (*author.Schema, error) {
+					if err := write(ctx); err != nil {
+						return nil, err
+					}
+					return stored, nil
+				},
+				DeleteFunc: func(ctx context.Context, authorID uint64) error {
+					return write(ctx)
+				},
+			}
+
+			h := RegisterHTTPEndPoints(chi.NewRouter(), validator.New(), uc)
+
+			switch tt.method {
+			case http.MethodGet:
+				h.Get(ww, rr)
+			case http.MethodPut:
+				h.Update(ww, rr)
+			case http.MethodPatch:
+				h.Patch(ww, rr)
+			case http.MethodDelete:
+				h.Delete(ww, rr)
+			}
+
+			assert.Equal(t, tt.status, ww.Code)
+			assert.Equal(t, tt.written, written)
+			if tt.method == http.MethodGet {
+				assert.Equal(t, current, ww.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -12,15 +12,21 @@ func RegisterHTTPEndPoints(router *chi.Mux, validate *validator.Validate, useCas
 	h := NewHandler(useCase, validate)
 
 	router.Route("/api/v1/author", func(router chi.Router) {
-		router.Post("/", h.Create)
-
 		cacheGroup := router.Group(nil)
 		cacheGroup.Use(middleware.CacheByURL)
 		cacheGroup.Get("/", h.List)
 
 		router.Get("/{id}", h.Get)
-		router.Put("/{id}", h.Update)
-		router.Delete("/{id}", h.Delete)
+
+		writeGroup := router.Group(nil)
+		writeGroup.Use(middleware.RequirePermission("authors:write"))
+		writeGroup.Post("/", h.Create)
+		writeGroup.Put("/{id}", h.Update)
+		writeGroup.Patch("/{id}", h.Patch)
+		writeGroup.Delete("/{id}", h.Delete)
+		writeGroup.Post("/{id}/restore", h.Restore)
+
+		router.With(middleware.RequirePermission("trash:read")).Get("/trash", h.Trash)
 	})
 
 	return h
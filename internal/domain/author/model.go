@@ -4,8 +4,17 @@ import (
 	"time"
 
 	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/etag"
+	"github.com/gmhafiz/go8/internal/utility/filter"
 )
 
+// SortKeys are what authors can be sorted by. Each is also its column.
+// Without any, authors are listed by ID.
+var SortKeys = []string{"first_name", "last_name", "created_at", "updated_at"}
+
+// TrashOrder lists most recently deleted authors first.
+var TrashOrder = filter.Order{Key: "deleted_at", Desc: true}
+
 type Schema struct {
 	ID         uint64
 	FirstName  string
@@ -14,5 +23,44 @@ type Schema struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 	DeletedAt  *time.Time
+	Version    int64
 	Books      []*book.Schema
+
+	// Score is how well the author matches a search. Only set by searchers
+	// that rank their results.
+	Score float64
+}
+
+// Revision tells this version of a apart from others.
+func (a *Schema) Revision() etag.Revision {
+	return etag.Revision{UpdatedAt: a.UpdatedAt, Version: a.Version}
+}
+
+// SortValue returns the value of a for one of SortKeys, or TrashOrder, as
+// kept in cursors.
+func (a *Schema) SortValue(key string) string {
+	switch key {
+	case "first_name":
+		return a.FirstName
+	case "last_name":
+		return a.LastName
+	case "created_at":
+		return a.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return a.UpdatedAt.Format(time.RFC3339Nano)
+	case "deleted_at":
+		if a.DeletedAt != nil {
+			return a.DeletedAt.Format(time.RFC3339Nano)
+		}
+	}
+	return ""
+}
+
+// Cursor marks a in a list sorted by orders.
+func (a *Schema) Cursor(orders []filter.Order) filter.Cursor {
+	values := make([]string, 0, len(orders))
+	for _, o := range orders {
+		values = append(values, a.SortValue(o.Key))
+	}
+	return filter.Cursor{Values: values, ID: a.ID}
 }
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"entgo.io/ent"
+	"go.opentelemetry.io/otel"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	entAuthor "github.com/gmhafiz/go8/ent/gen/author"
+	"github.com/gmhafiz/go8/internal/domain/author"
+	"github.com/gmhafiz/go8/third_party/elasticsearch"
+)
+
+// AuthorIndex is the Elasticsearch index authors are searched in.
+const AuthorIndex = "authors"
+
+// AuthorMapping is the mapping of AuthorIndex. Names are searched through
+// full_name so that a name may be given in any order.
+var AuthorMapping = []byte(`{
+  "mappings": {
+    "properties": {
+      "id": {"type": "long"},
+      "first_name": {"type": "text"},
+      "middle_name": {"type": "text"},
+      "last_name": {"type": "text"},
+      "full_name": {"type": "text"},
+      "created_at": {"type": "date"},
+      "updated_at": {"type": "date"}
+    }
+  }
+}`)
+
+type authorDocument struct {
+	ID         uint64    `json:"id"`
+	FirstName  string    `json:"first_name"`
+	MiddleName string    `json:"middle_name"`
+	LastName   string    `json:"last_name"`
+	FullName   string    `json:"full_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// authorAction indexes a, or removes it from the index once it is deleted.
+func authorAction(id uint64, a *gen.Author) elasticsearch.Action {
+	action := elasticsearch.Action{ID: strconv.FormatUint(id, 10)}
+	if a == nil || a.DeletedAt != nil {
+		return action
+	}
+
+	action.Document = &authorDocument{
+		ID:         a.ID,
+		FirstName:  a.FirstName,
+		MiddleName: a.MiddleName,
+		LastName:   a.LastName,
+		FullName:   fullNameOf(a),
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+	return action
+}
+
+type elasticSearch struct {
+	es       *elasticsearch.Client
+	fallback Searcher
+}
+
+// NewElasticSearch searches authors in Elasticsearch. Searches are served by
+// fallback while Elasticsearch is unavailable.
+func NewElasticSearch(es *elasticsearch.Client, fallback Searcher) *elasticSearch {
+	return &elasticSearch{
+		es:       es,
+		fallback: fallback,
+	}
+}
+
+// Search tolerates typos of a couple of letters in each name. Each author
+// carries its relevance in Score.
+func (r *elasticSearch) Search(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+	tracer := otel.Tracer("")
+	ctx, span := tracer.Start(ctx, "AuthorElasticSearch")
+	defer span.End()
+
+	resp, err := r.es.Search(ctx, AuthorIndex, map[string]any{
+		"from":             f.Base.Offset,
+		"size":             f.Base.Limit,
+		"track_total_hits": true,
+		"query": map[string]any{
+			"match": map[string]any{
+				"full_name": map[string]any{
+					"query":     f.SearchQuery(),
+					"fuzziness": "AUTO",
+					"operator":  "and",
+				},
+			},
+		},
+	})
+	if errors.Is(err, elasticsearch.ErrUnavailable) {
+		slog.WarnContext(ctx, "searching authors in postgres instead", "error", err)
+		return r.fallback.Search(ctx, f)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("searching authors: %w", err)
+	}
+
+	authors := make([]*author.Schema, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		var doc authorDocument
+		if err = json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, 0, fmt.Errorf("decoding author %s: %w", hit.ID, err)
+		}
+
+		authors = append(authors, &author.Schema{
+			ID:         doc.ID,
+			FirstName:  doc.FirstName,
+			MiddleName: doc.MiddleName,
+			LastName:   doc.LastName,
+			CreatedAt:  doc.CreatedAt,
+			UpdatedAt:  doc.UpdatedAt,
+			Score:      hit.Score,
+		})
+	}
+
+	return authors, resp.Hits.Total.Value, nil
+}
+
+type elasticIndexer struct {
+	es *elasticsearch.Client
+}
+
+func (i elasticIndexer) index(ctx context.Context, authors map[uint64]*gen.Author) error {
+	actions := make([]elasticsearch.Action, 0, len(authors))
+	for id, a := range authors {
+		actions = append(actions, authorAction(id, a))
+	}
+	return i.es.Bulk(ctx, AuthorIndex, actions)
+}
+
+// ElasticSyncHook keeps AuthorIndex up to date with authors written through
+// ent. Authors written in a transaction are indexed once it commits. The
+// index falls behind if Elasticsearch is unavailable during a write, until
+// authors are reindexed.
+func ElasticSyncHook(client *gen.Client, es *elasticsearch.Client) ent.Hook {
+	return syncHook(client, elasticIndexer{es: es})
+}
+
+// Reindex writes every author that is not deleted to AuthorIndex, batchSize
+// at a time, and returns how many were written. With recreate, the index is
+// emptied first so that authors deleted meanwhile and mapping changes are
+// taken into account.
+func Reindex(ctx context.Context, client *gen.Client, es *elasticsearch.Client, batchSize int, recreate bool) (int, error) {
+	if recreate {
+		if err := es.DeleteIndex(ctx, AuthorIndex); err != nil {
+			return 0, err
+		}
+	}
+	if err := es.CreateIndex(ctx, AuthorIndex, AuthorMapping); err != nil {
+		return 0, err
+	}
+
+	var (
+		total  int
+		lastID uint64
+	)
+	for {
+		authors, err := client.Author.Query().
+			Where(entAuthor.IDGT(lastID), entAuthor.DeletedAtIsNil()).
+			Order(entAuthor.ByID()).
+			Limit(batchSize).
+			All(ctx)
+		if err != nil {
+			return total, err
+		}
+		if len(authors) == 0 {
+			return total, nil
+		}
+
+		actions := make([]elasticsearch.Action, 0, len(authors))
+		for _, a := range authors {
+			actions = append(actions, authorAction(a.ID, a))
+		}
+		if err = es.Bulk(ctx, AuthorIndex, actions); err != nil {
+			return total, err
+		}
+
+		total += len(authors)
+		lastID = authors[len(authors)-1].ID
+	}
+}
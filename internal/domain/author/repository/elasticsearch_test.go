@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gmhafiz/go8/internal/domain/author"
+	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/third_party/elasticsearch"
+	"github.com/gmhafiz/go8/third_party/elasticsearch/elasticsearchtest"
+)
+
+func TestElasticSearch_Search(t *testing.T) {
+	ctx := context.Background()
+	f := &author.Filter{
+		Base:  filter.Filter{Limit: 10, Search: true},
+		Query: "Jhon Smtih",
+	}
+
+	t.Run("maps hits", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		srv.OnSearch(func(index string, body map[string]any) any {
+			assert.Equal(t, AuthorIndex, index)
+			return map[string]any{
+				"hits": map[string]any{
+					"total": map[string]int{"value": 11},
+					"hits": []map[string]any{
+						{
+							"_id":    "3",
+							"_score": 4.2,
+							"_source": authorDocument{
+								ID:        3,
+								FirstName: "John",
+								LastName:  "Smith",
+								FullName:  "John Smith",
+							},
+						},
+					},
+				},
+			}
+		})
+		searcher := NewElasticSearch(elasticsearch.New(srv.Elasticsearch()), &SearcherMock{})
+
+		got, total, err := searcher.Search(ctx, f)
+		assert.Nil(t, err)
+		assert.Equal(t, 11, total)
+		assert.Equal(t, []*author.Schema{
+			{
+				ID:        3,
+				FirstName: "John",
+				LastName:  "Smith",
+				Score:     4.2,
+			},
+		}, got)
+
+		searches := srv.Searches()
+		assert.Len(t, searches, 1)
+		match := searches[0]["query"].(map[string]any)["match"].(map[string]any)["full_name"].(map[string]any)
+		assert.Equal(t, "Jhon Smtih", match["query"])
+		assert.Equal(t, "AUTO", match["fuzziness"])
+	})
+
+	t.Run("falls back when unavailable", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		srv.Close()
+
+		fallback := &SearcherMock{
+			SearchFunc: func(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+				return []*author.Schema{{ID: 1}}, 1, nil
+			},
+		}
+		searcher := NewElasticSearch(elasticsearch.New(srv.Elasticsearch()), fallback)
+
+		got, total, err := searcher.Search(ctx, f)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []*author.Schema{{ID: 1}}, got)
+	})
+
+	t.Run("bad query is not hidden", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		srv.FailWith(http.StatusBadRequest)
+		searcher := NewElasticSearch(elasticsearch.New(srv.Elasticsearch()), &SearcherMock{})
+
+		_, _, err := searcher.Search(ctx, f)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestElasticSyncHook(t *testing.T) {
+	ctx := context.Background()
+	srv := elasticsearchtest.New(t)
+	es := elasticsearch.New(srv.Elasticsearch())
+
+	client := dbClient()
+	client.Author.Use(ElasticSyncHook(client, es))
+	repo := New(client)
+
+	created, err := repo.Create(ctx, &author.CreateRequest{FirstName: "Ada", MiddleName: "King", LastName: "Lovelace"})
+	assert.Nil(t, err)
+	id := strconv.FormatUint(created.ID, 10)
+
+	var doc authorDocument
+	assert.Nil(t, json.Unmarshal(srv.Documents(AuthorIndex)[id], &doc))
+	assert.Equal(t, "Ada King Lovelace", doc.FullName)
+
+	_, err = repo.Update(ctx, &author.UpdateRequest{ID: created.ID, FirstName: "Augusta", LastName: "Lovelace"})
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(srv.Documents(AuthorIndex)[id], &doc))
+	assert.Equal(t, "Augusta", doc.FirstName)
+
+	assert.Nil(t, repo.Delete(ctx, created.ID))
+	_, ok := srv.Documents(AuthorIndex)[id]
+	assert.False(t, ok)
+
+	assert.Nil(t, repo.Restore(ctx, created.ID))
+	_, ok = srv.Documents(AuthorIndex)[id]
+	assert.True(t, ok)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"entgo.io/ent"
+	"go.opentelemetry.io/otel"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	entAuthor "github.com/gmhafiz/go8/ent/gen/author"
+	"github.com/gmhafiz/go8/internal/domain/author"
+	"github.com/gmhafiz/go8/internal/utility/searchindex"
+)
+
+// AuthorFields are the fields authors are indexed by.
+var AuthorFields = []searchindex.Field{
+	{Name: "name", Boost: 1},
+}
+
+func authorFields(a *gen.Author) map[string]string {
+	return map[string]string{"name": fullNameOf(a)}
+}
+
+type embeddedSearch struct {
+	idx      *searchindex.Index
+	ent      *gen.Client
+	fallback Searcher
+}
+
+// NewEmbeddedSearch searches authors in idx and loads them from db.
+// Searches are served by fallback until idx is ready.
+func NewEmbeddedSearch(idx *searchindex.Index, db *gen.Client, fallback Searcher) *embeddedSearch {
+	return &embeddedSearch{
+		idx:      idx,
+		ent:      db,
+		fallback: fallback,
+	}
+}
+
+// Search returns authors having every name in f, best match first. A name
+// ending with * matches every name starting with it. Each author carries
+// its relevance in Score.
+func (r *embeddedSearch) Search(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+	tracer := otel.Tracer("")
+	ctx, span := tracer.Start(ctx, "AuthorEmbeddedSearch")
+	defer span.End()
+
+	if !r.idx.Ready() {
+		return r.fallback.Search(ctx, f)
+	}
+
+	hits, total := r.idx.Search(f.SearchQuery(), f.Base.Offset, f.Base.Limit)
+	if len(hits) == 0 {
+		return []*author.Schema{}, total, nil
+	}
+
+	ids := make([]uint64, 0, len(hits))
+	for _, hit := range hits {
+		id, err := strconv.ParseUint(hit.ID, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("author id %q in search index: %w", hit.ID, err)
+		}
+		ids = append(ids, id)
+	}
+
+	authors, err := r.ent.Author.Query().
+		Where(entAuthor.IDIn(ids...), entAuthor.DeletedAtIsNil()).
+		All(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving Author list: %w", err)
+	}
+
+	byID := make(map[uint64]*gen.Author, len(authors))
+	for _, a := range authors {
+		byID[a.ID] = a
+	}
+
+	// An author deleted since it was indexed is left out.
+	resp := make([]*author.Schema, 0, len(ids))
+	for n, id := range ids {
+		a, ok := byID[id]
+		if !ok {
+			continue
+		}
+		resp = append(resp, &author.Schema{
+			ID:         a.ID,
+			FirstName:  a.FirstName,
+			MiddleName: a.MiddleName,
+			LastName:   a.LastName,
+			CreatedAt:  a.CreatedAt,
+			UpdatedAt:  a.UpdatedAt,
+			Version:    a.Version,
+			Score:      hits[n].Score,
+		})
+	}
+
+	return resp, total, nil
+}
+
+type embeddedIndexer struct {
+	idx *searchindex.Index
+}
+
+func (i embeddedIndexer) index(_ context.Context, authors map[uint64]*gen.Author) error {
+	for id, a := range authors {
+		if a == nil || a.DeletedAt != nil {
+			i.idx.Delete(strconv.FormatUint(id, 10))
+			continue
+		}
+		i.idx.Put(strconv.FormatUint(id, 10), authorFields(a))
+	}
+	return nil
+}
+
+// EmbeddedSyncHook keeps idx up to date with authors written through ent.
+func EmbeddedSyncHook(client *gen.Client, idx *searchindex.Index) ent.Hook {
+	return syncHook(client, embeddedIndexer{idx: idx})
+}
+
+// RebuildEmbedded replaces the content of idx with every author that is not
+// deleted, read batchSize at a time.
+func RebuildEmbedded(ctx context.Context, client *gen.Client, idx *searchindex.Index, batchSize int) error {
+	return idx.Rebuild(func(put func(id string, doc map[string]string)) error {
+		var lastID uint64
+		for {
+			authors, err := client.Author.Query().
+				Where(entAuthor.IDGT(lastID), entAuthor.DeletedAtIsNil()).
+				Order(entAuthor.ByID()).
+				Limit(batchSize).
+				All(ctx)
+			if err != nil {
+				return err
+			}
+			if len(authors) == 0 {
+				return nil
+			}
+
+			for _, a := range authors {
+				put(strconv.FormatUint(a.ID, 10), authorFields(a))
+			}
+			lastID = authors[len(authors)-1].ID
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gmhafiz/go8/internal/domain/author"
+	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/internal/utility/searchindex"
+)
+
+func TestEmbeddedSearch(t *testing.T) {
+	ctx := context.Background()
+	idx, err := searchindex.Open(filepath.Join(t.TempDir(), "authors.idx"), "english", 0, AuthorFields...)
+	assert.Nil(t, err)
+
+	client := dbClient()
+	repo := New(client)
+	fellBack := 0
+	fallback := &SearcherMock{
+		SearchFunc: func(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+			fellBack++
+			return []*author.Schema{}, 0, nil
+		},
+	}
+	searcher := NewEmbeddedSearch(idx, client, fallback)
+	search := func(query string) []*author.Schema {
+		found, _, err := searcher.Search(ctx, &author.Filter{
+			Base:  filter.Filter{Limit: 10, Search: true},
+			Query: query,
+		})
+		assert.Nil(t, err)
+		return found
+	}
+
+	existing, err := repo.Create(ctx, &author.CreateRequest{FirstName: "Grace", LastName: "Hopper"})
+	assert.Nil(t, err)
+
+	// Not ready until rebuilt.
+	assert.Empty(t, search("Hopper"))
+	assert.Equal(t, 1, fellBack)
+
+	assert.Nil(t, RebuildEmbedded(ctx, client, idx, 100))
+	found := search("hopper")
+	assert.Len(t, found, 1)
+	assert.Equal(t, existing.ID, found[0].ID)
+	assert.Greater(t, found[0].Score, 0.0)
+
+	client.Author.Use(EmbeddedSyncHook(client, idx))
+	created, err := repo.Create(ctx, &author.CreateRequest{FirstName: "Ada", MiddleName: "King", LastName: "Lovelace"})
+	assert.Nil(t, err)
+	found = search("love*")
+	assert.Len(t, found, 1)
+	assert.Equal(t, created.ID, found[0].ID)
+
+	assert.Nil(t, repo.Delete(ctx, created.ID))
+	assert.Empty(t, search("Lovelace"))
+
+	assert.Nil(t, repo.Restore(ctx, created.ID))
+	assert.Len(t, search("Ada Lovelace"), 1)
+}
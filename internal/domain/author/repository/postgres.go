@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"entgo.io/ent/dialect/sql"
@@ -11,9 +12,13 @@ import (
 
 	"github.com/gmhafiz/go8/ent/gen"
 	entAuthor "github.com/gmhafiz/go8/ent/gen/author"
+	entBook "github.com/gmhafiz/go8/ent/gen/book"
 	"github.com/gmhafiz/go8/ent/gen/predicate"
 	"github.com/gmhafiz/go8/internal/domain/author"
 	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/etag"
+	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/internal/utility/message"
 	parseTime "github.com/gmhafiz/go8/internal/utility/time"
 )
 
@@ -27,7 +32,11 @@ type Author interface {
 	List(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
 	Read(ctx context.Context, id uint64) (*author.Schema, error)
 	Update(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error)
+	Patch(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error)
 	Delete(ctx context.Context, authorID uint64) error
+	Trash(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
+	Restore(ctx context.Context, authorID uint64) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 type Searcher interface {
@@ -85,6 +94,7 @@ func (r *repository) Create(ctx context.Context, request *author.CreateRequest)
 			Description:   i.Description,
 			CreatedAt:     i.CreatedAt,
 			UpdatedAt:     i.UpdatedAt,
+			Version:       i.Version,
 			//DeletedAt:     sql.NullTime{Time: *i.DeletedAt, Valid: true},
 		})
 	}
@@ -96,6 +106,7 @@ func (r *repository) Create(ctx context.Context, request *author.CreateRequest)
 		LastName:   created.LastName,
 		CreatedAt:  created.CreatedAt,
 		UpdatedAt:  created.UpdatedAt,
+		Version:    created.Version,
 		DeletedAt:  created.DeletedAt,
 		Books:      b,
 	}
@@ -120,9 +131,6 @@ func (r *repository) List(ctx context.Context, f *author.Filter) ([]*author.Sche
 		predicateUser = append(predicateUser, entAuthor.LastNameContainsFold(f.LastName))
 	}
 
-	// sort by field
-	orderFunc := authorOrder(f.Base.Sort)
-
 	total, err := r.ent.Author.Query().
 		Where(entAuthor.DeletedAtIsNil()).
 		Count(ctx)
@@ -130,17 +138,37 @@ func (r *repository) List(ctx context.Context, f *author.Filter) ([]*author.Sche
 		return nil, 0, fmt.Errorf("get total author records: %w", err)
 	}
 
-	authors, err := r.ent.Author.Query().
-		WithBooks().
+	// Pages start after or end before the cursor, if any, rather than at
+	// the offset.
+	orders := f.Base.Orders(author.SortKeys)
+	cursor, backwards := f.Base.After, false
+	if f.Base.Before != nil {
+		cursor, backwards = f.Base.Before, true
+	}
+
+	query := r.ent.Author.Query().
+		WithBooks(withoutDeletedBooks).
 		Where(predicateUser...).
 		Where(entAuthor.DeletedAtIsNil()).
 		Limit(f.Base.Limit).
-		Offset(f.Base.Offset).
-		Order(orderFunc...).
-		All(ctx)
+		Order(authorOrder(orders, backwards)...)
+	if cursor != nil {
+		after, err := keyset(orders, cursor, backwards)
+		if err != nil {
+			return nil, 0, err
+		}
+		query.Where(after)
+	} else {
+		query.Offset(f.Base.Offset)
+	}
+
+	authors, err := query.All(ctx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("get author records: %w", err)
 	}
+	if backwards {
+		slices.Reverse(authors)
+	}
 
 	resp := make([]*author.Schema, 0)
 
@@ -156,6 +184,7 @@ func (r *repository) List(ctx context.Context, f *author.Filter) ([]*author.Sche
 				Description:   b.Description,
 				CreatedAt:     b.CreatedAt,
 				UpdatedAt:     b.UpdatedAt,
+				Version:       b.Version,
 			})
 		}
 
@@ -166,6 +195,7 @@ func (r *repository) List(ctx context.Context, f *author.Filter) ([]*author.Sche
 			LastName:   a.LastName,
 			CreatedAt:  a.CreatedAt,
 			UpdatedAt:  a.UpdatedAt,
+			Version:    a.Version,
 			DeletedAt:  a.DeletedAt,
 			Books:      books,
 		})
@@ -176,7 +206,7 @@ func (r *repository) List(ctx context.Context, f *author.Filter) ([]*author.Sche
 
 func (r *repository) Read(ctx context.Context, id uint64) (*author.Schema, error) {
 	found, err := r.ent.Author.Query().
-		WithBooks().
+		WithBooks(withoutDeletedBooks).
 		Where(entAuthor.ID(id)).
 		Where(entAuthor.DeletedAtIsNil()).
 		First(ctx)
@@ -195,6 +225,7 @@ func (r *repository) Read(ctx context.Context, id uint64) (*author.Schema, error
 			Description:   b.Description,
 			CreatedAt:     b.CreatedAt,
 			UpdatedAt:     b.UpdatedAt,
+			Version:       b.Version,
 		})
 	}
 
@@ -205,18 +236,26 @@ func (r *repository) Read(ctx context.Context, id uint64) (*author.Schema, error
 		LastName:   found.LastName,
 		CreatedAt:  found.CreatedAt,
 		UpdatedAt:  found.UpdatedAt,
+		Version:    found.Version,
 		DeletedAt:  found.DeletedAt,
 		Books:      books,
 	}, err
 }
 
+// Update writes every column of the author. If ctx carries a revision, the
+// author must still be at it, or message.ErrPreconditionFailed is returned.
 func (r *repository) Update(ctx context.Context, a *author.UpdateRequest) (*author.Schema, error) {
-	updated, err := r.ent.Author.UpdateOneID(a.ID).
+	update := r.ent.Author.UpdateOneID(a.ID).
 		SetFirstName(a.FirstName).
 		SetMiddleName(a.MiddleName).
-		SetLastName(a.LastName).
-		Save(ctx)
+		SetLastName(a.LastName)
+	guarded := unchanged(ctx, update)
+
+	updated, err := update.Save(ctx)
 	if err != nil {
+		if guarded && gen.IsNotFound(err) {
+			return nil, message.ErrPreconditionFailed
+		}
 		return nil, err
 	}
 
@@ -230,6 +269,7 @@ func (r *repository) Update(ctx context.Context, a *author.UpdateRequest) (*auth
 			Description:   b.Description,
 			CreatedAt:     b.CreatedAt,
 			UpdatedAt:     b.UpdatedAt,
+			Version:       b.Version,
 		})
 	}
 
@@ -240,49 +280,251 @@ func (r *repository) Update(ctx context.Context, a *author.UpdateRequest) (*auth
 		LastName:   updated.LastName,
 		CreatedAt:  updated.CreatedAt,
 		UpdatedAt:  updated.UpdatedAt,
+		Version:    updated.Version,
 		DeletedAt:  updated.DeletedAt,
 		Books:      books,
 	}, nil
 }
 
+// Patch writes the columns listed in a.Changed, and nothing if there are
+// none. message.ErrNoRecord is returned when there is no such author. If
+// ctx carries a revision, the author must still be at it, or
+// message.ErrPreconditionFailed is returned.
+func (r *repository) Patch(ctx context.Context, a *author.PatchRequest) (*author.Schema, error) {
+	if len(a.Changed) > 0 {
+		update := r.ent.Author.UpdateOneID(a.ID).
+			Where(entAuthor.DeletedAtIsNil())
+		for _, column := range a.Changed {
+			switch column {
+			case entAuthor.FieldFirstName:
+				update.SetFirstName(a.FirstName)
+			case entAuthor.FieldMiddleName:
+				if a.MiddleName == nil {
+					update.ClearMiddleName()
+				} else {
+					update.SetMiddleName(*a.MiddleName)
+				}
+			case entAuthor.FieldLastName:
+				update.SetLastName(a.LastName)
+			default:
+				return nil, fmt.Errorf("author has no column %q to patch", column)
+			}
+		}
+
+		guarded := unchanged(ctx, update)
+
+		err := update.Exec(ctx)
+		if err != nil {
+			if gen.IsNotFound(err) {
+				if guarded {
+					return nil, message.ErrPreconditionFailed
+				}
+				return nil, message.ErrNoRecord
+			}
+			return nil, err
+		}
+	}
+
+	return r.Read(ctx, a.ID)
+}
+
+// Delete moves an author to the trash. If ctx carries a revision, the author
+// must still be at it, or message.ErrPreconditionFailed is returned.
 func (r *repository) Delete(ctx context.Context, authorID uint64) error {
-	_, err := r.ent.Author.UpdateOneID(authorID).
-		SetDeletedAt(time.Now()).
-		Save(ctx)
+	update := r.ent.Author.UpdateOneID(authorID).
+		SetDeletedAt(time.Now())
+	guarded := unchanged(ctx, update)
+
+	_, err := update.Save(ctx)
+	if guarded && gen.IsNotFound(err) {
+		return message.ErrPreconditionFailed
+	}
 
 	return err
 }
 
-func authorOrder(sorts map[string]string) []entAuthor.OrderOption {
-	var orderFunc []entAuthor.OrderOption
-	for col, ord := range sorts {
-		switch col {
+// unchanged only lets update through if the author is still at the
+// revision in ctx, if any, and reports if there is one.
+func unchanged(ctx context.Context, update *gen.AuthorUpdateOne) bool {
+	rev, ok := etag.FromContext(ctx)
+	if ok {
+		update.Where(entAuthor.UpdatedAt(rev.UpdatedAt), entAuthor.Version(rev.Version))
+	}
+
+	return ok
+}
+
+// Trash lists soft-deleted authors, most recently deleted first. Like List,
+// it pages by cursor when f has one.
+func (r *repository) Trash(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+	total, err := r.ent.Author.Query().
+		Where(entAuthor.DeletedAtNotNil()).
+		Count(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get total deleted author records: %w", err)
+	}
+
+	orders := []filter.Order{author.TrashOrder}
+	cursor, backwards := f.Base.After, false
+	if f.Base.Before != nil {
+		cursor, backwards = f.Base.Before, true
+	}
+
+	query := r.ent.Author.Query().
+		WithBooks(withoutDeletedBooks).
+		Where(entAuthor.DeletedAtNotNil()).
+		Limit(f.Base.Limit).
+		Order(authorOrder(orders, backwards)...)
+	if cursor != nil {
+		after, err := keyset(orders, cursor, backwards)
+		if err != nil {
+			return nil, 0, err
+		}
+		query.Where(after)
+	} else {
+		query.Offset(f.Base.Offset)
+	}
+
+	authors, err := query.All(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get deleted author records: %w", err)
+	}
+	if backwards {
+		slices.Reverse(authors)
+	}
+
+	resp := make([]*author.Schema, 0)
+	for _, a := range authors {
+		books := make([]*book.Schema, 0)
+		for _, b := range a.Edges.Books {
+			books = append(books, &book.Schema{
+				ID:            b.ID,
+				Title:         b.Title,
+				PublishedDate: b.PublishedDate,
+				ImageURL:      b.ImageURL,
+				Description:   b.Description,
+				CreatedAt:     b.CreatedAt,
+				UpdatedAt:     b.UpdatedAt,
+				Version:       b.Version,
+			})
+		}
+
+		resp = append(resp, &author.Schema{
+			ID:         a.ID,
+			FirstName:  a.FirstName,
+			MiddleName: a.MiddleName,
+			LastName:   a.LastName,
+			CreatedAt:  a.CreatedAt,
+			UpdatedAt:  a.UpdatedAt,
+			Version:    a.Version,
+			DeletedAt:  a.DeletedAt,
+			Books:      books,
+		})
+	}
+
+	return resp, total, nil
+}
+
+// Restore brings back a soft-deleted author. message.ErrNoRecord is
+// returned when there is no such author in the trash.
+func (r *repository) Restore(ctx context.Context, authorID uint64) error {
+	restored, err := r.ent.Author.Update().
+		Where(entAuthor.ID(authorID), entAuthor.DeletedAtNotNil()).
+		ClearDeletedAt().
+		Save(ctx)
+	if err != nil {
+		return err
+	}
+	if restored == 0 {
+		return message.ErrNoRecord
+	}
+
+	return nil
+}
+
+// Purge hard-deletes authors soft-deleted before deletedBefore and returns
+// how many were removed. Their books are kept.
+func (r *repository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	purged, err := r.ent.Author.Delete().
+		Where(entAuthor.DeletedAtLT(deletedBefore)).
+		Exec(ctx)
+
+	return int64(purged), err
+}
+
+// withoutDeletedBooks leaves books in the trash out of an author's books.
+func withoutDeletedBooks(q *gen.BookQuery) {
+	q.Where(entBook.DeletedAtIsNil())
+}
+
+// authorOrder sorts by orders, then by ID so that pages are stable when
+// sorting by a column with duplicate values. Backwards reverses every
+// direction to read the rows before a cursor.
+func authorOrder(orders []filter.Order, backwards bool) []entAuthor.OrderOption {
+	orderFunc := make([]entAuthor.OrderOption, 0, len(orders)+1)
+	for _, o := range orders {
+		direction := sql.OrderAsc()
+		if o.Desc != backwards {
+			direction = sql.OrderDesc()
+		}
+
+		switch o.Key {
 		case entAuthor.FieldFirstName:
-			if ord == "ASC" {
-				orderFunc = append(orderFunc, entAuthor.ByFirstName(sql.OrderAsc()))
-			} else {
-				orderFunc = append(orderFunc, entAuthor.ByFirstName(sql.OrderDesc()))
-			}
+			orderFunc = append(orderFunc, entAuthor.ByFirstName(direction))
 		case entAuthor.FieldLastName:
-			if ord == "ASC" {
-				orderFunc = append(orderFunc, entAuthor.ByLastName(sql.OrderAsc()))
-			} else {
-				orderFunc = append(orderFunc, entAuthor.ByLastName(sql.OrderDesc()))
-			}
+			orderFunc = append(orderFunc, entAuthor.ByLastName(direction))
 		case entAuthor.FieldCreatedAt:
-			if ord == "ASC" {
-				orderFunc = append(orderFunc, entAuthor.ByCreatedAt(sql.OrderAsc()))
-			} else {
-				orderFunc = append(orderFunc, entAuthor.ByCreatedAt(sql.OrderDesc()))
-			}
+			orderFunc = append(orderFunc, entAuthor.ByCreatedAt(direction))
 		case entAuthor.FieldUpdatedAt:
-			if ord == "ASC" {
-				orderFunc = append(orderFunc, entAuthor.ByUpdatedAt(sql.OrderAsc()))
-			} else {
-				orderFunc = append(orderFunc, entAuthor.ByUpdatedAt(sql.OrderDesc()))
-			}
+			orderFunc = append(orderFunc, entAuthor.ByUpdatedAt(direction))
+		case entAuthor.FieldDeletedAt:
+			orderFunc = append(orderFunc, entAuthor.ByDeletedAt(direction))
 		}
 	}
 
-	return orderFunc
+	direction := sql.OrderAsc()
+	if backwards {
+		direction = sql.OrderDesc()
+	}
+	return append(orderFunc, entAuthor.ByID(direction))
+}
+
+// keyset keeps authors sorted after cursor, or before it if backwards, by
+// orders then ID.
+//
+// For (a ASC, b DESC, id), rows after (1, 2, 3) are those where
+// a > 1 OR (a = 1 AND b < 2) OR (a = 1 AND b = 2 AND id > 3).
+func keyset(orders []filter.Order, cursor *filter.Cursor, backwards bool) (predicate.Author, error) {
+	if len(cursor.Values) != len(orders) {
+		return nil, filter.ErrInvalidCursor
+	}
+
+	columns := make([]string, 0, len(orders)+1)
+	descs := make([]bool, 0, len(orders)+1)
+	values := make([]any, 0, len(orders)+1)
+	for i, o := range orders {
+		columns = append(columns, o.Key)
+		descs = append(descs, o.Desc)
+		values = append(values, cursor.Values[i])
+	}
+	columns = append(columns, entAuthor.FieldID)
+	descs = append(descs, false)
+	values = append(values, cursor.ID)
+
+	return func(s *sql.Selector) {
+		disjuncts := make([]*sql.Predicate, 0, len(columns))
+		for i := range columns {
+			conjuncts := make([]*sql.Predicate, 0, i+1)
+			for j := 0; j < i; j++ {
+				conjuncts = append(conjuncts, sql.EQ(s.C(columns[j]), values[j]))
+			}
+			if descs[i] != backwards {
+				conjuncts = append(conjuncts, sql.LT(s.C(columns[i]), values[i]))
+			} else {
+				conjuncts = append(conjuncts, sql.GT(s.C(columns[i]), values[i]))
+			}
+			disjuncts = append(disjuncts, sql.And(conjuncts...))
+		}
+		s.Where(sql.Or(disjuncts...))
+	}, nil
 }
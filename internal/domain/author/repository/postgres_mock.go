@@ -6,15 +6,20 @@ package repository
 import (
 	"context"
 	"github.com/gmhafiz/go8/internal/domain/author"
+	"time"
 )
 
 // AuthorMock is a mock implementation of Author.
 type AuthorMock struct {
-	CreateFunc func(ctx context.Context, a *author.CreateRequest) (*author.Schema, error)
-	DeleteFunc func(ctx context.Context, authorID uint64) error
-	ListFunc   func(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
-	ReadFunc   func(ctx context.Context, id uint64) (*author.Schema, error)
-	UpdateFunc func(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error)
+	CreateFunc  func(ctx context.Context, a *author.CreateRequest) (*author.Schema, error)
+	DeleteFunc  func(ctx context.Context, authorID uint64) error
+	ListFunc    func(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
+	PatchFunc   func(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error)
+	PurgeFunc   func(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ReadFunc    func(ctx context.Context, id uint64) (*author.Schema, error)
+	RestoreFunc func(ctx context.Context, authorID uint64) error
+	TrashFunc   func(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
+	UpdateFunc  func(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error)
 }
 
 func (m *AuthorMock) Create(ctx context.Context, a *author.CreateRequest) (*author.Schema, error) {
@@ -29,10 +34,26 @@ func (m *AuthorMock) List(ctx context.Context, f *author.Filter) ([]*author.Sche
 	return m.ListFunc(ctx, f)
 }
 
+func (m *AuthorMock) Patch(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error) {
+	return m.PatchFunc(ctx, toAuthor)
+}
+
+func (m *AuthorMock) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return m.PurgeFunc(ctx, deletedBefore)
+}
+
 func (m *AuthorMock) Read(ctx context.Context, id uint64) (*author.Schema, error) {
 	return m.ReadFunc(ctx, id)
 }
 
+func (m *AuthorMock) Restore(ctx context.Context, authorID uint64) error {
+	return m.RestoreFunc(ctx, authorID)
+}
+
+func (m *AuthorMock) Trash(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+	return m.TrashFunc(ctx, f)
+}
+
 func (m *AuthorMock) Update(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error) {
 	return m.UpdateFunc(ctx, toAuthor)
 }
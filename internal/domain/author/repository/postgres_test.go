@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -20,7 +21,9 @@ import (
 	"github.com/gmhafiz/go8/ent/gen"
 	"github.com/gmhafiz/go8/internal/domain/author"
 	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/etag"
 	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/internal/utility/message"
 	parseTime "github.com/gmhafiz/go8/internal/utility/time"
 )
 
@@ -543,6 +546,100 @@ func TestRepository_Update(t *testing.T) {
 	}
 }
 
+func TestRepository_Patch(t *testing.T) {
+	client := dbClient()
+	repo := New(client)
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, &author.CreateRequest{
+		FirstName:  "Patched",
+		MiddleName: "Middle",
+		LastName:   "Author",
+	})
+	assert.Nil(t, err)
+
+	t.Run("writes changed columns only", func(t *testing.T) {
+		req := author.Patchable(created)
+		req.FirstName = "Changed"
+		req.LastName = "ignored as unchanged"
+		req.Changed = []string{"first_name"}
+
+		got, err := repo.Patch(ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, "Changed", got.FirstName)
+		assert.Equal(t, "Middle", got.MiddleName)
+		assert.Equal(t, "Author", got.LastName)
+	})
+
+	t.Run("clears middle name", func(t *testing.T) {
+		req := author.Patchable(created)
+		req.MiddleName = nil
+		req.Changed = []string{"middle_name"}
+
+		got, err := repo.Patch(ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, "", got.MiddleName)
+	})
+
+	t.Run("no such author", func(t *testing.T) {
+		_, err := repo.Patch(ctx, &author.PatchRequest{
+			ID:        math.MaxInt32,
+			FirstName: "Nobody",
+			Changed:   []string{"first_name"},
+		})
+		assert.ErrorIs(t, err, message.ErrNoRecord)
+	})
+}
+
+func TestRepository_UpdateIfUnchanged(t *testing.T) {
+	client := dbClient()
+	repo := New(client)
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, &author.CreateRequest{
+		FirstName: "Guarded",
+		LastName:  "Author",
+	})
+	assert.Nil(t, err)
+
+	read, err := repo.Read(ctx, created.ID)
+	assert.Nil(t, err)
+	stale := etag.NewContext(ctx, read.Revision())
+
+	updated, err := repo.Update(stale, &author.UpdateRequest{
+		ID:        created.ID,
+		FirstName: "First Editor",
+		LastName:  "Author",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, read.Version+1, updated.Version)
+
+	// A second editor still holding the first revision must not overwrite
+	// the first one.
+	_, err = repo.Update(stale, &author.UpdateRequest{
+		ID:        created.ID,
+		FirstName: "Second Editor",
+		LastName:  "Author",
+	})
+	assert.ErrorIs(t, err, message.ErrPreconditionFailed)
+
+	patch := author.Patchable(read)
+	patch.FirstName = "Second Editor"
+	patch.Changed = []string{"first_name"}
+	_, err = repo.Patch(stale, patch)
+	assert.ErrorIs(t, err, message.ErrPreconditionFailed)
+
+	err = repo.Delete(stale, created.ID)
+	assert.ErrorIs(t, err, message.ErrPreconditionFailed)
+
+	got, err := repo.Read(ctx, created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "First Editor", got.FirstName)
+
+	err = repo.Delete(etag.NewContext(ctx, got.Revision()), created.ID)
+	assert.Nil(t, err)
+}
+
 func TestRepository_Delete(t *testing.T) {
 	client := dbClient()
 	repo := New(client)
@@ -595,8 +692,103 @@ func TestRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestRepository_ListByCursor(t *testing.T) {
+	client := dbClient()
+	repo := New(client)
+	ctx := context.Background()
+
+	var created []*author.Schema
+	for _, name := range []string{"Bea", "Cara", "Bea"} {
+		a, err := repo.Create(ctx, &author.CreateRequest{FirstName: name, LastName: "Keyset"})
+		assert.Nil(t, err)
+		created = append(created, a)
+	}
+
+	base := filter.Filter{
+		Limit:  2,
+		Sort:   map[string]string{"first_name": "DESC"},
+		SortBy: []string{"first_name"},
+	}
+	orders := base.Orders(author.SortKeys)
+	page := func(after, before *author.Schema) []uint64 {
+		f := &author.Filter{Base: base, LastName: "Keyset"}
+		if after != nil {
+			c := after.Cursor(orders)
+			f.Base.After = &c
+		}
+		if before != nil {
+			c := before.Cursor(orders)
+			f.Base.Before = &c
+		}
+		got, _, err := repo.List(ctx, f)
+		assert.Nil(t, err)
+
+		var ids []uint64
+		for _, a := range got {
+			ids = append(ids, a.ID)
+		}
+		return ids
+	}
+
+	// Both Beas have the same first name, so their IDs tell them apart.
+	assert.Equal(t, []uint64{created[1].ID, created[0].ID}, page(nil, nil))
+	assert.Equal(t, []uint64{created[2].ID}, page(created[0], nil))
+	assert.Equal(t, []uint64{created[1].ID, created[0].ID}, page(nil, created[2]))
+}
+
 func TestRepository_Search(t *testing.T) {}
 
+func TestTrigramSearch_Search(t *testing.T) {
+	client := dbClient()
+	repo := New(client)
+	searcher := NewTrigramSearch(client, 0.12)
+	ctx := context.Background()
+
+	john, err := repo.Create(ctx, &author.CreateRequest{FirstName: "John", LastName: "Smith"})
+	assert.Nil(t, err)
+	_, err = repo.Create(ctx, &author.CreateRequest{FirstName: "Nakamura", LastName: "Hiroshi"})
+	assert.Nil(t, err)
+	deleted, err := repo.Create(ctx, &author.CreateRequest{FirstName: "Jon", LastName: "Smith"})
+	assert.Nil(t, err)
+	assert.Nil(t, repo.Delete(ctx, deleted.ID))
+
+	tests := []struct {
+		name   string
+		filter *author.Filter
+		want   []uint64
+	}{
+		{
+			name:   "misspelt full name",
+			filter: &author.Filter{Base: filter.Filter{Limit: 10, Search: true}, Query: "Jhon Smtih"},
+			want:   []uint64{john.ID},
+		},
+		{
+			name:   "separate names",
+			filter: &author.Filter{Base: filter.Filter{Limit: 10, Search: true}, FirstName: "john", LastName: "smith"},
+			want:   []uint64{john.ID},
+		},
+		{
+			name:   "nothing alike",
+			filter: &author.Filter{Base: filter.Filter{Limit: 10, Search: true}, Query: "Xavier Quigley"},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := searcher.Search(ctx, tt.filter)
+			assert.Nil(t, err)
+			assert.Equal(t, len(tt.want), total)
+
+			var ids []uint64
+			for _, a := range got {
+				ids = append(ids, a.ID)
+				assert.True(t, a.Score >= 0.12 && a.Score <= 1)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
 func dbClient() *gen.Client {
 	drv := entsql.OpenDB(DBDriver, migrator.DB)
 	return gen.NewClient(gen.Driver(drv))
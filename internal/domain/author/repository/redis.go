@@ -23,7 +23,9 @@ type Cache struct {
 type AuthorRedisService interface {
 	List(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
 	Update(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error)
+	Patch(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error)
 	Delete(ctx context.Context, id uint64) error
+	Restore(ctx context.Context, id uint64) error
 }
 
 func NewRedisCache(service Author, cache *redis.Client) *Cache {
@@ -87,12 +89,24 @@ func (c *Cache) Update(ctx context.Context, toAuthor *author.UpdateRequest) (*au
 	return c.service.Update(ctx, toAuthor)
 }
 
+func (c *Cache) Patch(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error) {
+	c.invalidate(ctx)
+
+	return c.service.Patch(ctx, toAuthor)
+}
+
 func (c *Cache) Delete(ctx context.Context, id uint64) error {
 	c.invalidate(ctx)
 
 	return c.service.Delete(ctx, id)
 }
 
+func (c *Cache) Restore(ctx context.Context, id uint64) error {
+	c.invalidate(ctx)
+
+	return c.service.Restore(ctx, id)
+}
+
 func (c *Cache) invalidate(ctx context.Context) {
 	url, ok := ctx.Value(middleware.CacheURL).(string)
 	if !ok {
@@ -10,9 +10,11 @@ import (
 
 // AuthorRedisServiceMock is a mock implementation of AuthorRedisService.
 type AuthorRedisServiceMock struct {
-	DeleteFunc func(ctx context.Context, id uint64) error
-	ListFunc   func(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
-	UpdateFunc func(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error)
+	DeleteFunc  func(ctx context.Context, id uint64) error
+	ListFunc    func(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
+	PatchFunc   func(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error)
+	RestoreFunc func(ctx context.Context, id uint64) error
+	UpdateFunc  func(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error)
 }
 
 func (m *AuthorRedisServiceMock) Delete(ctx context.Context, id uint64) error {
@@ -23,6 +25,14 @@ func (m *AuthorRedisServiceMock) List(ctx context.Context, f *author.Filter) ([]
 	return m.ListFunc(ctx, f)
 }
 
+func (m *AuthorRedisServiceMock) Patch(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error) {
+	return m.PatchFunc(ctx, toAuthor)
+}
+
+func (m *AuthorRedisServiceMock) Restore(ctx context.Context, id uint64) error {
+	return m.RestoreFunc(ctx, id)
+}
+
 func (m *AuthorRedisServiceMock) Update(ctx context.Context, toAuthor *author.UpdateRequest) (*author.Schema, error) {
 	return m.UpdateFunc(ctx, toAuthor)
 }
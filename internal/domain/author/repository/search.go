@@ -59,12 +59,12 @@ func (r *repository) Search(ctx context.Context, f *author.Filter) ([]*author.Sc
 	// Also, may use term frequency-inverted index search (tf-idf) like
 	// elasticsearch or bleve.
 	authors, err := r.ent.Author.Query().
-		WithBooks().
+		WithBooks(withoutDeletedBooks).
 		Where(predicateUser...).
 		Where(entAuthor.DeletedAtIsNil()).
 		Limit(f.Base.Limit).
 		Offset(f.Base.Offset).
-		Order(authorOrder(f.Base.Sort)...).
+		Order(authorOrder(f.Base.Orders(author.SortKeys), false)...).
 		All(ctx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error retrieving Author list: %w", err)
@@ -80,6 +80,7 @@ func (r *repository) Search(ctx context.Context, f *author.Filter) ([]*author.Sc
 			LastName:   a.LastName,
 			CreatedAt:  a.CreatedAt,
 			UpdatedAt:  a.UpdatedAt,
+			Version:    a.Version,
 			DeletedAt:  a.DeletedAt,
 			Books:      nil,
 		})
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"entgo.io/ent"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	entAuthor "github.com/gmhafiz/go8/ent/gen/author"
+	"github.com/gmhafiz/go8/ent/gen/hook"
+)
+
+// indexer writes authors to a search index. An author that is nil or
+// deleted is removed from it.
+type indexer interface {
+	index(ctx context.Context, authors map[uint64]*gen.Author) error
+}
+
+// syncHook indexes authors written through ent. Authors written in a
+// transaction are indexed once it commits.
+func syncHook(client *gen.Client, indexer indexer) ent.Hook {
+	return hook.On(func(next ent.Mutator) ent.Mutator {
+		return hook.AuthorFunc(func(ctx context.Context, m *gen.AuthorMutation) (ent.Value, error) {
+			var ids []uint64
+			if !m.Op().Is(ent.OpCreate) {
+				var err error
+				if ids, err = m.IDs(ctx); err != nil {
+					return nil, err
+				}
+			}
+
+			v, err := next.Mutate(ctx, m)
+			if err != nil {
+				return v, err
+			}
+			if a, ok := v.(*gen.Author); ok && m.Op().Is(ent.OpCreate) {
+				ids = []uint64{a.ID}
+			}
+
+			sync := func(ctx context.Context) {
+				if err := syncAuthors(ctx, client, indexer, ids); err != nil {
+					slog.WarnContext(ctx, "indexing authors", "ids", ids, "error", err)
+				}
+			}
+			if tx, err := m.Tx(); err == nil {
+				tx.OnCommit(func(next gen.Committer) gen.Committer {
+					return gen.CommitFunc(func(ctx context.Context, tx *gen.Tx) error {
+						if err := next.Commit(ctx, tx); err != nil {
+							return err
+						}
+						sync(ctx)
+						return nil
+					})
+				})
+			} else {
+				sync(ctx)
+			}
+
+			return v, nil
+		})
+	}, ent.OpCreate|ent.OpUpdate|ent.OpUpdateOne|ent.OpDelete|ent.OpDeleteOne)
+}
+
+func syncAuthors(ctx context.Context, client *gen.Client, indexer indexer, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	authors, err := client.Author.Query().
+		Where(entAuthor.IDIn(ids...)).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[uint64]*gen.Author, len(ids))
+	for _, id := range ids {
+		found[id] = nil
+	}
+	for _, a := range authors {
+		found[a.ID] = a
+	}
+
+	return indexer.index(ctx, found)
+}
+
+// fullNameOf joins the names a has, in order.
+func fullNameOf(a *gen.Author) string {
+	var names []string
+	for _, name := range []string{a.FirstName, a.MiddleName, a.LastName} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " ")
+}
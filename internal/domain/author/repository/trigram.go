@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	entAuthor "github.com/gmhafiz/go8/ent/gen/author"
+	"github.com/gmhafiz/go8/internal/domain/author"
+)
+
+// fullName must stay the same as the expression of authors_full_name_trgm_idx
+// for the index to be used.
+const fullName = "(first_name || ' ' || coalesce(middle_name, '') || ' ' || last_name)"
+
+const (
+	setSimilarityThreshold = "SELECT set_config('pg_trgm.similarity_threshold', $1, true)"
+	countSimilarAuthors    = "SELECT count(*) FROM authors WHERE " + fullName + " % $1 AND deleted_at IS NULL"
+	selectSimilarAuthors   = "SELECT id, similarity(" + fullName + ", $1) AS score FROM authors WHERE " + fullName + " % $1 AND deleted_at IS NULL ORDER BY score DESC, id LIMIT $2 OFFSET $3"
+)
+
+type trigramSearch struct {
+	ent       *gen.Client
+	threshold float64
+}
+
+// NewTrigramSearch finds authors whose full name looks like the one searched
+// for, even if misspelt. Names are compared by the trigrams they share, and
+// only authors at least as similar as threshold, between 0 and 1, are
+// returned.
+func NewTrigramSearch(db *gen.Client, threshold float64) *trigramSearch {
+	return &trigramSearch{
+		ent:       db,
+		threshold: threshold,
+	}
+}
+
+// Search returns authors most similar to the names in f first. Each author
+// carries its similarity in Score.
+func (r *trigramSearch) Search(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+	tracer := otel.Tracer("")
+	ctx, span := tracer.Start(ctx, "AuthorTrigramSearch")
+	defer span.End()
+
+	name := f.SearchQuery()
+	if name == "" {
+		return []*author.Schema{}, 0, nil
+	}
+
+	tx, err := r.ent.Tx(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// The % operator can use the trigram index but takes its threshold from
+	// this setting, which only lasts until the end of the transaction.
+	_, err = tx.ExecContext(ctx, setSimilarityThreshold, strconv.FormatFloat(r.threshold, 'f', -1, 64))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error setting similarity threshold: %w", err)
+	}
+
+	var total int
+	rows, err := tx.QueryContext(ctx, countSimilarAuthors, name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting similar authors: %w", err)
+	}
+	for rows.Next() {
+		if err = rows.Scan(&total); err != nil {
+			_ = rows.Close()
+			return nil, 0, err
+		}
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error counting similar authors: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, selectSimilarAuthors, name, f.Base.Limit, f.Base.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching similar authors: %w", err)
+	}
+
+	var ids []uint64
+	scores := make(map[uint64]float64)
+	for rows.Next() {
+		var (
+			id    uint64
+			score float64
+		)
+		if err = rows.Scan(&id, &score); err != nil {
+			_ = rows.Close()
+			return nil, 0, err
+		}
+		ids = append(ids, id)
+		scores[id] = score
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	authors, err := tx.Author.Query().
+		Where(entAuthor.IDIn(ids...)).
+		All(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving Author list: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[uint64]*gen.Author, len(authors))
+	for _, a := range authors {
+		byID[a.ID] = a
+	}
+
+	resp := make([]*author.Schema, 0, len(ids))
+	for _, id := range ids {
+		a, ok := byID[id]
+		if !ok {
+			continue
+		}
+		resp = append(resp, &author.Schema{
+			ID:         a.ID,
+			FirstName:  a.FirstName,
+			MiddleName: a.MiddleName,
+			LastName:   a.LastName,
+			CreatedAt:  a.CreatedAt,
+			UpdatedAt:  a.UpdatedAt,
+			Version:    a.Version,
+			DeletedAt:  a.DeletedAt,
+			Score:      scores[id],
+		})
+	}
+
+	return resp, total, nil
+}
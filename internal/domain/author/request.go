@@ -20,3 +20,52 @@ type UpdateRequest struct {
 	MiddleName string `json:"middle_name,omitempty"`
 	LastName   string `json:"last_name"`
 }
+
+// PatchRequest is an author as JSON merge patches see it. Patches are
+// applied to the stored author, so that the result is validated in full,
+// but only the columns listed in Changed are written.
+type PatchRequest struct {
+	ID         uint64   `json:"-"`
+	FirstName  string   `json:"first_name" validate:"required"`
+	MiddleName *string  `json:"middle_name"`
+	LastName   string   `json:"last_name" validate:"required"`
+	Changed    []string `json:"-"`
+}
+
+// Patchable returns a as the document merge patches are applied to. An
+// author without a middle name has a null middle_name.
+func Patchable(a *Schema) *PatchRequest {
+	p := &PatchRequest{
+		ID:        a.ID,
+		FirstName: a.FirstName,
+		LastName:  a.LastName,
+	}
+	if a.MiddleName != "" {
+		middleName := a.MiddleName
+		p.MiddleName = &middleName
+	}
+
+	return p
+}
+
+// Diff sets Changed to the columns p gives a value other than the one in
+// before.
+func (p *PatchRequest) Diff(before *PatchRequest) {
+	p.Changed = nil
+	if p.FirstName != before.FirstName {
+		p.Changed = append(p.Changed, "first_name")
+	}
+	if !equal(p.MiddleName, before.MiddleName) {
+		p.Changed = append(p.Changed, "middle_name")
+	}
+	if p.LastName != before.LastName {
+		p.Changed = append(p.Changed, "last_name")
+	}
+}
+
+func equal(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -1,6 +1,8 @@
 package author
 
 import (
+	"time"
+
 	"github.com/gmhafiz/go8/internal/domain/book"
 )
 
@@ -10,6 +12,8 @@ type GetResponse struct {
 	MiddleName string         `json:"middle_name"`
 	LastName   string         `json:"last_name"`
 	Books      []*book.Schema `json:"books"`
+	// DeletedAt is only set for authors in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func Resource(a *Schema) *GetResponse {
@@ -23,6 +27,7 @@ func Resource(a *Schema) *GetResponse {
 		MiddleName: a.MiddleName,
 		LastName:   a.LastName,
 		Books:      a.Books,
+		DeletedAt:  a.DeletedAt,
 	}
 }
 
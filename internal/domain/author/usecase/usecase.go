@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.opentelemetry.io/otel"
 
@@ -27,7 +28,11 @@ type Author interface {
 	List(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
 	Read(ctx context.Context, authorID uint64) (*author.Schema, error)
 	Update(ctx context.Context, author *author.UpdateRequest) (*author.Schema, error)
+	Patch(ctx context.Context, author *author.PatchRequest) (*author.Schema, error)
 	Delete(ctx context.Context, authorID uint64) error
+	Trash(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error)
+	Restore(ctx context.Context, authorID uint64) (*author.Schema, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 func New(c config.Cache, repo repository.Author, searcher repository.Searcher, cache repository.AuthorLRUService, redisCache repository.AuthorRedisService) *AuthorUseCase {
@@ -79,6 +84,15 @@ func (u *AuthorUseCase) Update(ctx context.Context, author *author.UpdateRequest
 	return u.repo.Update(ctx, author)
 }
 
+func (u *AuthorUseCase) Patch(ctx context.Context, author *author.PatchRequest) (*author.Schema, error) {
+	if u.cfg.Enable {
+		// As above
+		return u.cacheRedis.Patch(ctx, author)
+	}
+
+	return u.repo.Patch(ctx, author)
+}
+
 func (u *AuthorUseCase) Delete(ctx context.Context, authorID uint64) error {
 	if authorID <= 0 {
 		return errors.New("ID cannot be 0 or less")
@@ -91,3 +105,30 @@ func (u *AuthorUseCase) Delete(ctx context.Context, authorID uint64) error {
 
 	return u.repo.Delete(ctx, authorID)
 }
+
+func (u *AuthorUseCase) Trash(ctx context.Context, f *author.Filter) ([]*author.Schema, int, error) {
+	return u.repo.Trash(ctx, f)
+}
+
+func (u *AuthorUseCase) Restore(ctx context.Context, authorID uint64) (*author.Schema, error) {
+	if authorID == 0 {
+		return nil, errors.New("ID cannot be 0")
+	}
+
+	var err error
+	if u.cfg.Enable {
+		// Restored author shows up in lists again, so cached ones are stale.
+		err = u.cacheRedis.Restore(ctx, authorID)
+	} else {
+		err = u.repo.Restore(ctx, authorID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return u.repo.Read(ctx, authorID)
+}
+
+func (u *AuthorUseCase) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return u.repo.Purge(ctx, deletedBefore)
+}
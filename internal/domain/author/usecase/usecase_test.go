@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/gmhafiz/go8/internal/domain/book"
 	"testing"
 	"time"
@@ -347,7 +348,7 @@ func TestAuthorUseCase_Update(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			repoAuthor := &AuthorMock{
+			repoAuthor := &repository.AuthorMock{
 				UpdateFunc: func(ctx context.Context, authorMiripParam *author.UpdateRequest) (*author.Schema, error) {
 					return test.want.repo.Schema, test.want.repo.error
 				},
@@ -375,6 +376,45 @@ func TestAuthorUseCase_Update(t *testing.T) {
 	}
 }
 
+func TestAuthorUseCase_Patch(t *testing.T) {
+	req := &author.PatchRequest{
+		ID:        1,
+		FirstName: "Changed",
+		LastName:  "Last",
+		Changed:   []string{"first_name"},
+	}
+	patched := &author.Schema{ID: 1, FirstName: "Changed", LastName: "Last"}
+
+	for _, enable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("cache enabled %t", enable), func(t *testing.T) {
+			var called string
+			repoAuthor := &repository.AuthorMock{
+				PatchFunc: func(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error) {
+					called = "repository"
+					return patched, nil
+				},
+			}
+			cacheMock := &repository.AuthorRedisServiceMock{
+				PatchFunc: func(ctx context.Context, toAuthor *author.PatchRequest) (*author.Schema, error) {
+					called = "cache"
+					return patched, nil
+				},
+			}
+
+			uc := New(config.Cache{Enable: enable}, repoAuthor, nil, nil, cacheMock)
+
+			got, err := uc.Patch(context.Background(), req)
+			assert.Nil(t, err)
+			assert.Equal(t, patched, got)
+			if enable {
+				assert.Equal(t, "cache", called)
+			} else {
+				assert.Equal(t, "repository", called)
+			}
+		})
+	}
+}
+
 func TestAuthorUseCase_Delete(t *testing.T) {
 	type args struct {
 		context.Context
@@ -415,7 +455,7 @@ func TestAuthorUseCase_Delete(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 
-			repoAuthor := &AuthorMock{
+			repoAuthor := &repository.AuthorMock{
 				DeleteFunc: func(ctx context.Context, authorID uint64) error {
 					return test.want.error
 				},
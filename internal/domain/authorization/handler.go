@@ -0,0 +1,110 @@
+package authorization
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gmhafiz/go8/internal/utility/param"
+	"github.com/gmhafiz/go8/internal/utility/request"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+var (
+	ErrRoleRequired = errors.New("role is required")
+)
+
+type Handler struct {
+	repo Repo
+}
+
+func NewHandler(repo Repo) *Handler {
+	return &Handler{
+		repo: repo,
+	}
+}
+
+// Roles lists all available roles along with their permissions.
+func (h *Handler) Roles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.repo.Roles(r.Context())
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, Resources(roles))
+}
+
+// UserRoles lists roles granted to a user.
+func (h *Handler) UserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, err := param.UInt64(r, "userID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	roles, err := h.repo.UserRoles(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			respond.Error(w, http.StatusNotFound, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, Resources(roles))
+}
+
+// Grant assigns a role to a user.
+func (h *Handler) Grant(w http.ResponseWriter, r *http.Request) {
+	userID, err := param.UInt64(r, "userID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req GrantRequest
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req.Role = strings.Trim(req.Role, " ")
+	if req.Role == "" {
+		respond.Error(w, http.StatusBadRequest, ErrRoleRequired)
+		return
+	}
+
+	err = h.repo.Grant(r.Context(), userID, req.Role)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrRoleNotFound) {
+			respond.Error(w, http.StatusNotFound, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
+
+// Revoke removes a role from a user.
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, err := param.UInt64(r, "userID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.repo.Revoke(r.Context(), userID, chi.URLParam(r, "role"))
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	respond.Status(w, http.StatusNoContent)
+}
@@ -0,0 +1,257 @@
+package authorization
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/lib/pq"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gmhafiz/go8/database"
+	"github.com/gmhafiz/go8/ent/gen"
+)
+
+const (
+	DBDriver = "postgres"
+)
+
+var (
+	migrator *database.Migrate
+)
+
+func TestMain(m *testing.M) {
+	// uses a sensible default on windows (tcp/http) and linux/osx (socket)
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	// pulls an image, creates a container based on it and runs it
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "17",
+		Env: []string{
+			"POSTGRES_PASSWORD=secret",
+			"POSTGRES_USER=user_name",
+			"POSTGRES_DB=dbname",
+			"listen_addresses = '*'",
+		},
+	}, func(config *docker.HostConfig) {
+		// set AutoRemove to true so that stopped container goes away by itself
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+	hostAndPort := resource.GetHostPort("5432/tcp")
+	databaseURL := fmt.Sprintf("postgres://user_name:secret@%s/dbname?sslmode=disable", hostAndPort)
+
+	_ = resource.Expire(120) // Tell docker to hard kill the container in 120 seconds
+
+	var db *sql.DB
+
+	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
+	pool.MaxWait = 120 * time.Second
+	if err = pool.Retry(func() error {
+		db, err = sql.Open(DBDriver, databaseURL)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		return db.Ping()
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	migrator = database.Migrator(db, database.WithDSN(databaseURL))
+	migrator.Up()
+
+	code := m.Run()
+
+	// You can't defer this because os.Exit doesn't care for defer
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestRepo_HasPermissionIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type args struct {
+		grant      string
+		permission string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "no role",
+			args: args{
+				permission: "books:write",
+			},
+			want: false,
+		},
+		{
+			name: "editor can write books",
+			args: args{
+				grant:      "editor",
+				permission: "books:write",
+			},
+			want: true,
+		},
+		{
+			name: "editor cannot manage roles",
+			args: args{
+				grant:      "editor",
+				permission: "roles:write",
+			},
+			want: false,
+		},
+		{
+			name: "admin can manage roles",
+			args: args{
+				grant:      "admin",
+				permission: "roles:write",
+			},
+			want: true,
+		},
+	}
+
+	repo := NewRepo(dbClient())
+	ctx := context.Background()
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := createUser(t, fmt.Sprintf("permission%d@example.com", i))
+
+			if tt.args.grant != "" {
+				err := repo.Grant(ctx, userID, tt.args.grant)
+				assert.Nil(t, err)
+			}
+
+			got, err := repo.HasPermission(ctx, userID, tt.args.permission)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandler_GrantRevokeIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	type want struct {
+		grantStatus  int
+		rolesAfter   []string
+		revokeStatus int
+	}
+	tests := []struct {
+		name string
+		role string
+		want want
+	}{
+		{
+			name: "grant and revoke editor",
+			role: "editor",
+			want: want{
+				grantStatus:  http.StatusNoContent,
+				rolesAfter:   []string{"editor"},
+				revokeStatus: http.StatusNoContent,
+			},
+		},
+		{
+			name: "unknown role",
+			role: "janitor",
+			want: want{
+				grantStatus:  http.StatusNotFound,
+				rolesAfter:   []string{},
+				revokeStatus: http.StatusNoContent,
+			},
+		},
+	}
+
+	repo := NewRepo(dbClient())
+	h := NewHandler(repo)
+
+	// Authentication and permission guards are tested in authentication domain.
+	router := chi.NewRouter()
+	router.Get("/api/v1/admin/users/{userID}/roles", h.UserRoles)
+	router.Post("/api/v1/admin/users/{userID}/roles", h.Grant)
+	router.Delete("/api/v1/admin/users/{userID}/roles/{role}", h.Revoke)
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := createUser(t, fmt.Sprintf("grant%d@example.com", i))
+
+			var buf bytes.Buffer
+			err := json.NewEncoder(&buf).Encode(&GrantRequest{Role: tt.role})
+			assert.Nil(t, err)
+
+			path := fmt.Sprintf("/api/v1/admin/users/%d/roles", userID)
+
+			ww := httptest.NewRecorder()
+			router.ServeHTTP(ww, httptest.NewRequest(http.MethodPost, path, &buf))
+			assert.Equal(t, tt.want.grantStatus, ww.Code)
+
+			ww = httptest.NewRecorder()
+			router.ServeHTTP(ww, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, http.StatusOK, ww.Code)
+
+			var roles []*RoleResponse
+			err = json.NewDecoder(ww.Body).Decode(&roles)
+			assert.Nil(t, err)
+
+			got := make([]string, 0)
+			for _, r := range roles {
+				got = append(got, r.Name)
+			}
+			assert.Equal(t, tt.want.rolesAfter, got)
+
+			ww = httptest.NewRecorder()
+			router.ServeHTTP(ww, httptest.NewRequest(http.MethodDelete, path+"/"+tt.role, nil))
+			assert.Equal(t, tt.want.revokeStatus, ww.Code)
+
+			has, err := repo.HasPermission(context.Background(), userID, "books:write")
+			assert.Nil(t, err)
+			assert.False(t, has)
+		})
+	}
+}
+
+func dbClient() *gen.Client {
+	drv := entsql.OpenDB(DBDriver, migrator.DB)
+	return gen.NewClient(gen.Driver(drv))
+}
+
+func createUser(t *testing.T, email string) uint64 {
+	t.Helper()
+
+	var userID uint64
+	err := migrator.DB.QueryRowContext(context.Background(), `
+		INSERT INTO users (email, password) VALUES ($1, 'unused') RETURNING id
+		`, email).Scan(&userID)
+	assert.Nil(t, err)
+
+	return userID
+}
@@ -0,0 +1,22 @@
+package authorization
+
+import (
+	"github.com/gmhafiz/scs/v2"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gmhafiz/go8/internal/middleware"
+)
+
+func RegisterHTTPEndPoints(router *chi.Mux, session *scs.SessionManager, repo Repo) {
+	h := NewHandler(repo)
+
+	router.Group(func(router chi.Router) {
+		router.Use(middleware.Authenticate(session))
+		router.Use(middleware.RequirePermission("roles:write"))
+
+		router.Get("/api/v1/admin/roles", h.Roles)
+		router.Get("/api/v1/admin/users/{userID}/roles", h.UserRoles)
+		router.Post("/api/v1/admin/users/{userID}/roles", h.Grant)
+		router.Delete("/api/v1/admin/users/{userID}/roles/{role}", h.Revoke)
+	})
+}
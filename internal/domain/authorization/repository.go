@@ -0,0 +1,117 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	"github.com/gmhafiz/go8/ent/gen/permission"
+	"github.com/gmhafiz/go8/ent/gen/role"
+	"github.com/gmhafiz/go8/ent/gen/user"
+	"github.com/gmhafiz/go8/ent/gen/userrole"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+type Repo interface {
+	HasPermission(ctx context.Context, userID uint64, permission string) (bool, error)
+	Roles(ctx context.Context) ([]*gen.Role, error)
+	UserRoles(ctx context.Context, userID uint64) ([]*gen.Role, error)
+	Grant(ctx context.Context, userID uint64, roleName string) error
+	Revoke(ctx context.Context, userID uint64, roleName string) error
+}
+
+type repo struct {
+	ent *gen.Client
+}
+
+func NewRepo(ent *gen.Client) *repo {
+	return &repo{
+		ent: ent,
+	}
+}
+
+// HasPermission checks if any of the user's roles carries the permission.
+func (r *repo) HasPermission(ctx context.Context, userID uint64, name string) (bool, error) {
+	return r.ent.Permission.Query().
+		Where(
+			permission.Name(name),
+			permission.HasRolesWith(role.HasUsersWith(user.ID(userID))),
+		).
+		Exist(ctx)
+}
+
+func (r *repo) Roles(ctx context.Context) ([]*gen.Role, error) {
+	return r.ent.Role.Query().
+		WithPermissions().
+		Order(role.ByID()).
+		All(ctx)
+}
+
+func (r *repo) UserRoles(ctx context.Context, userID uint64) ([]*gen.Role, error) {
+	exists, err := r.ent.User.Query().Where(user.ID(userID)).Exist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	return r.ent.Role.Query().
+		Where(role.HasUsersWith(user.ID(userID))).
+		WithPermissions().
+		Order(role.ByID()).
+		All(ctx)
+}
+
+// Grant assigns a role to a user. Granting a role the user already has is a
+// no-op.
+func (r *repo) Grant(ctx context.Context, userID uint64, roleName string) error {
+	exists, err := r.ent.User.Query().Where(user.ID(userID)).Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	ro, err := r.ent.Role.Query().Where(role.Name(roleName)).Only(ctx)
+	if err != nil {
+		if gen.IsNotFound(err) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	err = r.ent.UserRole.Create().
+		SetUserID(userID).
+		SetRoleID(ro.ID).
+		Exec(ctx)
+	if err != nil {
+		// Primary key violation means the role was already granted.
+		if gen.IsConstraintError(err) {
+			return nil
+		}
+		return fmt.Errorf("granting role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repo) Revoke(ctx context.Context, userID uint64, roleName string) error {
+	_, err := r.ent.UserRole.Delete().
+		Where(
+			userrole.UserID(userID),
+			userrole.HasRoleWith(role.Name(roleName)),
+		).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("revoking role: %w", err)
+	}
+
+	return nil
+}
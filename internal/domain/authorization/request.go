@@ -0,0 +1,5 @@
+package authorization
+
+type GrantRequest struct {
+	Role string `json:"role"`
+}
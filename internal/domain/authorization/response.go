@@ -0,0 +1,34 @@
+package authorization
+
+import (
+	"github.com/gmhafiz/go8/ent/gen"
+)
+
+type RoleResponse struct {
+	ID          uint64   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+func Resource(r *gen.Role) *RoleResponse {
+	permissions := make([]string, 0, len(r.Edges.Permissions))
+	for _, p := range r.Edges.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+
+	return &RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions,
+	}
+}
+
+func Resources(roles []*gen.Role) []*RoleResponse {
+	resources := make([]*RoleResponse, 0, len(roles))
+	for _, r := range roles {
+		resources = append(resources, Resource(r))
+	}
+	return resources
+}
@@ -1,7 +1,10 @@
 package book
 
 import (
+	"fmt"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gmhafiz/go8/internal/utility/filter"
 )
@@ -11,11 +14,20 @@ type Filter struct {
 	Title         string `json:"title"`
 	Description   string `json:"description"`
 	PublishedDate string `json:"published_date"`
+	// Query is a full-text search over both title and description.
+	Query string `json:"q"`
+
+	// PublishedFrom and PublishedUntil restrict books to those published in
+	// [PublishedFrom, PublishedUntil). Zero values leave either end open.
+	PublishedFrom  time.Time `json:"published_from"`
+	PublishedUntil time.Time `json:"published_until"`
 }
 
 func Filters(queries url.Values) *Filter {
 	f := filter.New(queries)
 	switch {
+	case queries.Has("q"):
+		fallthrough
 	case queries.Has("title"):
 		fallthrough
 	case queries.Has("description"):
@@ -27,5 +39,38 @@ func Filters(queries url.Values) *Filter {
 		Title:         queries.Get("title"),
 		Description:   queries.Get("description"),
 		PublishedDate: queries.Get("published_date"),
+		Query:         queries.Get("q"),
+	}
+}
+
+// ListFilters is like Filters, with the published_from and published_to
+// dates, both included, that only the v2 list understands.
+func ListFilters(queries url.Values) (*Filter, error) {
+	f := Filters(queries)
+
+	if from := queries.Get("published_from"); from != "" {
+		date, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return nil, fmt.Errorf("published_from must be a date such as %s", time.DateOnly)
+		}
+		f.PublishedFrom = date
+	}
+	if to := queries.Get("published_to"); to != "" {
+		date, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return nil, fmt.Errorf("published_to must be a date such as %s", time.DateOnly)
+		}
+		f.PublishedUntil = date.AddDate(0, 0, 1)
+	}
+
+	return f, nil
+}
+
+// SearchQuery returns what to search books for. Title and description are
+// kept for older clients and are searched together like Query.
+func (f *Filter) SearchQuery() string {
+	if f.Query != "" {
+		return f.Query
 	}
+	return strings.TrimSpace(f.Title + " " + f.Description)
 }
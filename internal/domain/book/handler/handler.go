@@ -11,8 +11,11 @@ import (
 
 	"github.com/gmhafiz/go8/internal/domain/book"
 	"github.com/gmhafiz/go8/internal/domain/book/usecase"
+	"github.com/gmhafiz/go8/internal/utility/etag"
+	"github.com/gmhafiz/go8/internal/utility/filter"
 	"github.com/gmhafiz/go8/internal/utility/message"
 	"github.com/gmhafiz/go8/internal/utility/param"
+	"github.com/gmhafiz/go8/internal/utility/request"
 	"github.com/gmhafiz/go8/internal/utility/respond"
 	"github.com/gmhafiz/go8/internal/utility/validate"
 )
@@ -70,11 +73,13 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 // Get a book by its ID
 // @Summary Get a Book
-// @Description Get a book by its id.
+// @Description Get a book by its id. Its ETag can be sent back in If-None-Match to only get the book if it has changed, or in If-Match to only change it if it has not.
 // @Accept json
 // @Produce json
 // @Param bookID path int true "book ID"
+// @Param If-None-Match header string false "ETag of the book already held"
 // @Success 200 {object} book.Res
+// @Success 304 "Not Modified"
 // @Failure 400 {string} Bad book.CreateRequest
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/book/{bookID} [get]
@@ -94,6 +99,10 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 		respond.Error(w, http.StatusInternalServerError, nil)
 		return
 	}
+	if etag.NotModified(w, r, b.Revision()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	list := book.Resource(b)
 
 	respond.JSON(w, http.StatusOK, list)
@@ -106,13 +115,25 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param page query string false "page number"
 // @Param size query string false "size of result"
+// @Param q query string false "full-text search over title and description, ordered by relevance"
 // @Param title query string false "search by title"
 // @Param description query string false "search by description"
+// @Param after query string false "cursor from the next link of the previous page, not for search"
+// @Param before query string false "cursor from the prev link of the next page, not for search"
 // @Success 200 {object} []book.Res
+// @Failure 400 {string} Bad Request
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/book [get]
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	filters := book.Filters(r.URL.Query())
+	if err := filters.Base.Err(); err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if filters.Base.Search && filters.Base.HasCursor() {
+		respond.Error(w, http.StatusBadRequest, filter.ErrSearchCursor)
+		return
+	}
 
 	var books []*book.Schema
 	ctx := r.Context()
@@ -140,6 +161,10 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		books = resp
+
+		// The body is a bare list, so pages around this one are only
+		// linked to.
+		pageLinks(w, r, &filters.Base, books, book.DefaultOrder)
 	}
 
 	list, err := book.Resources(books)
@@ -151,14 +176,72 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	respond.JSON(w, http.StatusOK, list)
 }
 
+// ListV2 lists books in the standard envelope with their total
+// @Summary Shows all books
+// @Description Lists books sorted and filtered by publication date, with how many there are across all pages. By default, it gets the first page of 30 books, newest first. Use v1 to search.
+// @Accept json
+// @Produce json
+// @Param page query string false "page number"
+// @Param limit query string false "limit of result"
+// @Param sort query string false "sort by title, published_date, created_at or updated_at, in the order given. E.g. published_date,desc"
+// @Param published_from query string false "only books published on or after this date, e.g. 2020-01-31"
+// @Param published_to query string false "only books published on or before this date, e.g. 2020-12-31"
+// @Param after query string false "next_cursor of the previous page"
+// @Param before query string false "prev_cursor of the next page"
+// @Success 200 {object} respond.Standard
+// @Failure 400 {string} Bad Request
+// @Failure 500 {string} Internal Server Error
+// @router /api/v2/book [get]
+func (h *Handler) ListV2(w http.ResponseWriter, r *http.Request) {
+	filters, err := book.ListFilters(r.URL.Query())
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = filters.Base.Err(); err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	books, total, err := h.useCase.ListWithTotal(r.Context(), filters)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	list, err := book.Resources(books)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, message.ErrFormingResponse)
+		return
+	}
+
+	meta := respond.Meta{
+		Size:  len(list),
+		Total: total,
+	}
+	orders := filters.Base.Orders(book.SortKeys, book.DefaultOrder)
+	meta.NextCursor, meta.PrevCursor = filters.Base.Cursors(len(books), func(i int) filter.Cursor {
+		return books[i].Cursor(orders)
+	})
+	respond.Links(w, r, meta)
+
+	respond.JSON(w, http.StatusOK, respond.Standard{
+		Data: list,
+		Meta: meta,
+	})
+}
+
 // Update a book
 // @Summary Update a Book
 // @Description Update a book by its model.
 // @Accept json
 // @Produce json
 // @Param Book body book.UpdateRequest true "Book UpdateRequest"
+// @Param If-Match header string false "ETag the book must still have"
 // @Success 200 {object} book.Res
 // @Failure 400 {string} Bad Request
+// @Failure 412 {string} Precondition Failed
+// @Failure 428 {string} Precondition Required
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/book/{bookID} [put]
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
@@ -182,24 +265,118 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.useCase.Update(r.Context(), &req)
+	ctx, err := h.precondition(r, bookID)
+	if err != nil {
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, message.ErrInternalError)
+		return
+	}
+
+	resp, err := h.useCase.Update(ctx, &req)
 	if err != nil {
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
 		respond.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	etag.Set(w, resp.Revision())
 	res := book.Resource(resp)
 
 	respond.JSON(w, http.StatusOK, res)
 }
 
+// Patch a book
+// @Summary Patch a Book
+// @Description Change some fields of a book with a JSON merge patch (RFC 7396). Fields left out keep their value, and image_url may be set to null to remove it.
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param bookID path int true "book ID"
+// @Param Book body book.PatchRequest true "Fields to change"
+// @Param If-Match header string false "ETag the book must still have"
+// @Success 200 {object} book.Res
+// @Failure 400 {string} Bad Request
+// @Failure 404 {string} Not Found
+// @Failure 412 {string} Precondition Failed
+// @Failure 415 {string} Unsupported Media Type
+// @Failure 428 {string} Precondition Required
+// @Failure 500 {string} Internal Server Error
+// @router /api/v1/book/{bookID} [patch]
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	bookID, err := param.UInt64(r, "bookID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, message.ErrBadRequest)
+		return
+	}
+
+	current, err := h.useCase.Read(r.Context(), bookID)
+	if err != nil {
+		if errors.Is(err, message.ErrBadRequest) {
+			respond.Error(w, http.StatusNotFound, errors.New("no book is found for this ID"))
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, message.ErrInternalError)
+		return
+	}
+
+	ctx, ok := etag.IfMatch(r.Context(), r, current.Revision())
+	if !ok {
+		respond.Error(w, http.StatusPreconditionFailed, message.ErrPreconditionFailed)
+		return
+	}
+
+	before := book.Patchable(current)
+	var req book.PatchRequest
+	err = request.DecodeMergePatch(w, r, before, &req)
+	if err != nil {
+		if errors.Is(err, request.ErrUnsupportedPatch) {
+			respond.Error(w, http.StatusUnsupportedMediaType, err)
+			return
+		}
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	req.ID = bookID
+
+	errs := validate.Validate(h.validate, req)
+	if errs != nil {
+		respond.Errors(w, http.StatusBadRequest, errs)
+		return
+	}
+	req.Diff(before)
+
+	resp, err := h.useCase.Patch(ctx, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, message.ErrNoRecord):
+			respond.Error(w, http.StatusNotFound, err)
+		case errors.Is(err, message.ErrPreconditionFailed):
+			respond.Error(w, http.StatusPreconditionFailed, err)
+		default:
+			respond.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	etag.Set(w, resp.Revision())
+	respond.JSON(w, http.StatusOK, book.Resource(resp))
+}
+
 // Delete a book by its ID
 // @Summary Delete a Book
 // @Description Delete a book by its id.
 // @Accept json
 // @Produce json
 // @Param id path int true "book ID"
+// @Param If-Match header string false "ETag the book must still have"
 // @Success 200 "Ok"
+// @Failure 412 {string} Precondition Failed
+// @Failure 428 {string} Precondition Required
 // @Failure 500 {string} Internal Server Error
 // @router /api/v1/book/{bookID} [delete]
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -209,11 +386,130 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.useCase.Delete(r.Context(), bookID)
+	ctx, err := h.precondition(r, bookID)
 	if err != nil {
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, message.ErrInternalError)
+		return
+	}
+
+	err = h.useCase.Delete(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, message.ErrPreconditionFailed) {
+			respond.Error(w, http.StatusPreconditionFailed, err)
+			return
+		}
 		respond.Error(w, http.StatusInternalServerError, message.ErrInternalError)
 		return
 	}
 
 	respond.JSON(w, http.StatusOK, nil)
 }
+
+// Trash lists deleted books
+// @Summary Shows deleted books
+// @Description Lists deleted books that have not been purged yet, most recently deleted first.
+// @Accept json
+// @Produce json
+// @Param page query string false "page number"
+// @Param size query string false "size of result"
+// @Param after query string false "cursor from the next link of the previous page"
+// @Param before query string false "cursor from the prev link of the next page"
+// @Success 200 {object} []book.Res
+// @Failure 400 {string} Bad Request
+// @Failure 500 {string} Internal Server Error
+// @router /api/v1/book/trash [get]
+func (h *Handler) Trash(w http.ResponseWriter, r *http.Request) {
+	filters := book.Filters(r.URL.Query())
+	if err := filters.Base.Err(); err != nil {
+		respond.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	books, err := h.useCase.Trash(r.Context(), filters)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	pageLinks(w, r, &filters.Base, books, book.TrashOrder)
+
+	list, err := book.Resources(books)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, message.ErrFormingResponse)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, list)
+}
+
+// Restore a deleted book by its ID
+// @Summary Restore a Book
+// @Description Restore a deleted book by its id.
+// @Accept json
+// @Produce json
+// @Param bookID path int true "book ID"
+// @Success 200 {object} book.Res
+// @Failure 400 {string} Bad Request
+// @Failure 404 {string} Not Found
+// @Failure 500 {string} Internal Server Error
+// @router /api/v1/book/{bookID}/restore [post]
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	bookID, err := param.UInt64(r, "bookID")
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, message.ErrBadRequest)
+		return
+	}
+
+	b, err := h.useCase.Restore(r.Context(), bookID)
+	if err != nil {
+		if errors.Is(err, message.ErrNoRecord) {
+			respond.Error(w, http.StatusNotFound, err)
+			return
+		}
+		respond.Error(w, http.StatusInternalServerError, message.ErrInternalError)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, book.Resource(b))
+}
+
+// pageLinks sets the Link header to the pages around books, which are sorted
+// by order.
+func pageLinks(w http.ResponseWriter, r *http.Request, f *filter.Filter, books []*book.Schema, order filter.Order) {
+	orders := []filter.Order{order}
+
+	var meta respond.Meta
+	meta.NextCursor, meta.PrevCursor = f.Cursors(len(books), func(i int) filter.Cursor {
+		return books[i].Cursor(orders)
+	})
+	respond.Links(w, r, meta)
+}
+
+// precondition checks the If-Match header of r, if any, against the book as
+// it is now. The returned context only lets the write through if the book
+// has not changed again by the time it is made.
+func (h *Handler) precondition(r *http.Request, bookID uint64) (context.Context, error) {
+	ctx := r.Context()
+	if r.Header.Get("If-Match") == "" {
+		return ctx, nil
+	}
+
+	current, err := h.useCase.Read(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, message.ErrBadRequest) {
+			// There is no book left to match.
+			return nil, message.ErrPreconditionFailed
+		}
+		return nil, err
+	}
+
+	ctx, ok := etag.IfMatch(ctx, r, current.Revision())
+	if !ok {
+		return nil, message.ErrPreconditionFailed
+	}
+
+	return ctx, nil
+}
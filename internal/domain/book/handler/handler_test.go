@@ -10,7 +10,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,7 +22,11 @@ import (
 
 	"github.com/gmhafiz/go8/internal/domain/book"
 	"github.com/gmhafiz/go8/internal/domain/book/usecase"
+	"github.com/gmhafiz/go8/internal/utility/etag"
+	"github.com/gmhafiz/go8/internal/utility/filter"
 	"github.com/gmhafiz/go8/internal/utility/message"
+	"github.com/gmhafiz/go8/internal/utility/request"
+	"github.com/gmhafiz/go8/internal/utility/respond"
 )
 
 type Errs struct {
@@ -577,6 +583,224 @@ func TestHandler_List(t *testing.T) {
 	}
 }
 
+func TestHandler_ListV2(t *testing.T) {
+	published := time.Date(2020, 1, 1, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		query  string
+		books  []*book.Schema
+		total  int
+		err    error
+		filter func(t *testing.T, f *book.Filter)
+		// link is the expected Link header, with the next and previous
+		// cursors in place of %s.
+		link   string
+		status int
+	}{
+		{
+			name:  "simple",
+			query: "",
+			books: []*book.Schema{{ID: 1, Title: "test title", PublishedDate: published}},
+			total: 11,
+			filter: func(t *testing.T, f *book.Filter) {
+				assert.Empty(t, f.Base.SortBy)
+				assert.True(t, f.PublishedFrom.IsZero())
+				assert.True(t, f.PublishedUntil.IsZero())
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:  "sorted and within dates",
+			query: "?sort=published_date,desc&sort=title&published_from=2020-01-01&published_to=2020-12-31",
+			books: []*book.Schema{},
+			filter: func(t *testing.T, f *book.Filter) {
+				assert.Equal(t, []string{"published_date", "title"}, f.Base.SortBy)
+				assert.Equal(t, map[string]string{"published_date": "DESC", "title": "ASC"}, f.Base.Sort)
+				assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), f.PublishedFrom)
+				assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), f.PublishedUntil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "full page links to the next",
+			query:  "?limit=1&page=2&sort=title",
+			books:  []*book.Schema{{ID: 2, Title: "b"}},
+			total:  3,
+			link:   `</api/v2/book?after=%s&limit=1&sort=title>; rel="next", </api/v2/book?before=%s&limit=1&sort=title>; rel="prev"`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "bad date",
+			query:  "?published_from=yesterday",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "bad cursor",
+			query:  "?after=forged",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "error fetching books",
+			err:    message.ErrFetchingBook,
+			status: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRequest(http.MethodGet, "/api/v2/book"+tt.query, nil)
+			ww := httptest.NewRecorder()
+
+			uc := &usecase.BookMock{
+				ListWithTotalFunc: func(ctx context.Context, f *book.Filter) ([]*book.Schema, int, error) {
+					if tt.filter != nil {
+						tt.filter(t, f)
+					}
+					return tt.books, tt.total, tt.err
+				},
+			}
+
+			h := RegisterHTTPEndPoints(chi.NewRouter(), validator.New(), uc)
+
+			h.ListV2(ww, rr)
+
+			assert.Equal(t, tt.status, ww.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Data []*book.Res `json:"data"`
+				Meta struct {
+					Size  int `json:"size"`
+					Total int `json:"total"`
+				} `json:"meta"`
+			}
+			body := ww.Body.Bytes()
+			assert.Nil(t, json.Unmarshal(body, &got))
+			assert.Len(t, got.Data, len(tt.books))
+			assert.Equal(t, len(tt.books), got.Meta.Size)
+			assert.Equal(t, tt.total, got.Meta.Total)
+			for i, b := range tt.books {
+				assert.Equal(t, b.ID, got.Data[i].ID)
+				assert.Equal(t, b.Title, got.Data[i].Title)
+			}
+
+			if tt.link == "" {
+				assert.Empty(t, ww.Header().Get("Link"))
+				return
+			}
+			var meta struct {
+				Meta respond.Meta `json:"meta"`
+			}
+			assert.Nil(t, json.Unmarshal(body, &meta))
+			assert.NotEmpty(t, meta.Meta.NextCursor)
+			assert.NotEmpty(t, meta.Meta.PrevCursor)
+			assert.Equal(t, fmt.Sprintf(tt.link, meta.Meta.NextCursor, meta.Meta.PrevCursor), ww.Header().Get("Link"))
+		})
+	}
+}
+
+func TestHandler_ListByCursor(t *testing.T) {
+	deleted := sql.NullTime{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	cursor := filter.Cursor{Values: []string{deleted.Time.Format(time.RFC3339Nano)}, ID: 1}.Encode()
+
+	tests := []struct {
+		name   string
+		path   string
+		books  []*book.Schema
+		cursor func(t *testing.T, f *book.Filter)
+		// link is the expected Link header, with the next cursor in place
+		// of %s.
+		link   string
+		status int
+	}{
+		{
+			name:   "v1 full page links to the next",
+			path:   "/api/v1/book?limit=1",
+			books:  []*book.Schema{{ID: 2, Title: "b"}},
+			link:   `</api/v1/book?after=%s&limit=1>; rel="next"`,
+			status: http.StatusOK,
+		},
+		{
+			name:  "v1 starts after the cursor",
+			path:  "/api/v1/book?after=" + cursor,
+			books: []*book.Schema{},
+			cursor: func(t *testing.T, f *book.Filter) {
+				assert.NotNil(t, f.Base.After)
+				assert.Equal(t, uint64(1), f.Base.After.ID)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "v1 tampered cursor",
+			path:   "/api/v1/book?after=" + cursor + "x",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "v1 search cannot page by cursor",
+			path:   "/api/v1/book?q=go&after=" + cursor,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "trash full page links to the next",
+			path:   "/api/v1/book/trash?limit=1",
+			books:  []*book.Schema{{ID: 2, Title: "b", DeletedAt: deleted}},
+			link:   `</api/v1/book/trash?after=%s&limit=1>; rel="next"`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "trash tampered cursor",
+			path:   "/api/v1/book/trash?before=forged",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			ww := httptest.NewRecorder()
+
+			list := func(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
+				if tt.cursor != nil {
+					tt.cursor(t, f)
+				}
+				return tt.books, nil
+			}
+			uc := &usecase.BookMock{
+				ListFunc:  list,
+				TrashFunc: list,
+			}
+
+			h := RegisterHTTPEndPoints(chi.NewRouter(), validator.New(), uc)
+
+			if strings.HasPrefix(tt.path, "/api/v1/book/trash") {
+				h.Trash(ww, rr)
+			} else {
+				h.List(ww, rr)
+			}
+
+			assert.Equal(t, tt.status, ww.Code)
+			if tt.link == "" {
+				assert.Empty(t, ww.Header().Get("Link"))
+				return
+			}
+
+			link := ww.Header().Get("Link")
+			next, _, _ := strings.Cut(strings.TrimPrefix(link, "<"), ">")
+			u, err := url.Parse(next)
+			assert.Nil(t, err)
+			after := u.Query().Get("after")
+			assert.NotEmpty(t, after)
+			assert.Equal(t, fmt.Sprintf(tt.link, after), link)
+
+			// The cursor marks the last book of the page.
+			c, err := filter.DecodeCursor(after)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.books[len(tt.books)-1].ID, c.ID)
+		})
+	}
+}
+
 func TestHandler_Update(t *testing.T) {
 	parsedTime, err := time.Parse(time.RFC3339, "2022-03-09T00:00:00Z")
 	assert.Nil(t, err)
@@ -798,6 +1022,140 @@ func TestHandler_Update(t *testing.T) {
 	}
 }
 
+func TestHandler_Patch(t *testing.T) {
+	published := time.Date(2022, 3, 9, 0, 0, 0, 0, time.UTC)
+	stored := &book.Schema{
+		ID:            1,
+		Title:         "mock title",
+		PublishedDate: published,
+		ImageURL:      "https://example.com/image.png",
+		Description:   "mock description",
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		readErr     error
+		patchErr    error
+		// patched tells if the usecase is asked to write the book, and
+		// changed which of its columns.
+		patched  bool
+		changed  []string
+		imageURL *string
+		status   int
+	}{
+		{
+			name:        "changes fields present only",
+			contentType: request.MergePatchContentType,
+			patch:       `{"title":"new title"}`,
+			patched:     true,
+			changed:     []string{"title"},
+			imageURL:    &stored.ImageURL,
+			status:      http.StatusOK,
+		},
+		{
+			name:        "null clears image",
+			contentType: request.MergePatchContentType,
+			patch:       `{"image_url":null}`,
+			patched:     true,
+			changed:     []string{"image_url"},
+			status:      http.StatusOK,
+		},
+		{
+			name:        "same values write nothing",
+			contentType: "application/json",
+			patch:       `{"title":"mock title"}`,
+			patched:     true,
+			imageURL:    &stored.ImageURL,
+			status:      http.StatusOK,
+		},
+		{
+			name:        "merged book is validated",
+			contentType: request.MergePatchContentType,
+			patch:       `{"description":null}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "invalid image",
+			contentType: request.MergePatchContentType,
+			patch:       `{"image_url":"not a url"}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "unknown field",
+			contentType: request.MergePatchContentType,
+			patch:       `{"author":"someone"}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported patch format",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op":"remove","path":"/image_url"}]`,
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "no such book",
+			contentType: request.MergePatchContentType,
+			patch:       `{"title":"new title"}`,
+			readErr:     message.ErrBadRequest,
+			status:      http.StatusNotFound,
+		},
+		{
+			name:        "deleted while patching",
+			contentType: request.MergePatchContentType,
+			patch:       `{"title":"new title"}`,
+			patchErr:    message.ErrNoRecord,
+			patched:     true,
+			changed:     []string{"title"},
+			imageURL:    &stored.ImageURL,
+			status:      http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRequest(http.MethodPatch, "/api/v1/book/1", bytes.NewBufferString(tt.patch))
+			rr.Header.Set("Content-Type", tt.contentType)
+			ww := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("bookID", "1")
+			rr = rr.WithContext(context.WithValue(rr.Context(), chi.RouteCtxKey, rctx))
+
+			patched := false
+			uc := &usecase.BookMock{
+				ReadFunc: func(ctx context.Context, bookID uint64) (*book.Schema, error) {
+					if tt.readErr != nil {
+						return nil, tt.readErr
+					}
+					b := *stored
+					return &b, nil
+				},
+				PatchFunc: func(ctx context.Context, req *book.PatchRequest) (*book.Schema, error) {
+					patched = true
+					assert.Equal(t, uint64(1), req.ID)
+					assert.Equal(t, tt.changed, req.Changed)
+					assert.Equal(t, tt.imageURL, req.ImageURL)
+					if tt.patchErr != nil {
+						return nil, tt.patchErr
+					}
+					b := *stored
+					b.Title = req.Title
+					return &b, nil
+				},
+			}
+
+			h := RegisterHTTPEndPoints(chi.NewRouter(), validator.New(), uc)
+
+			h.Patch(ww, rr)
+
+			assert.Equal(t, tt.status, ww.Code)
+			assert.Equal(t, tt.patched, patched)
+		})
+	}
+}
+
 func TestHandler_Delete(t *testing.T) {
 	type args struct {
 		bookID int
@@ -874,3 +1232,210 @@ func TestHandler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_Restore(t *testing.T) {
+	type args struct {
+		bookID int
+		param  string
+	}
+	type want struct {
+		status int
+		error
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "ok",
+			args: args{
+				bookID: 1,
+				param:  "bookID",
+			},
+			want: want{
+				status: http.StatusOK,
+				error:  nil,
+			},
+		},
+		{
+			name: "wrong query param",
+			args: args{
+				bookID: 1,
+				param:  "id",
+			},
+			want: want{
+				status: http.StatusBadRequest,
+				error:  nil,
+			},
+		},
+		{
+			name: "not in trash",
+			args: args{
+				bookID: 1,
+				param:  "bookID",
+			},
+			want: want{
+				status: http.StatusNotFound,
+				error:  message.ErrNoRecord,
+			},
+		},
+		{
+			name: "some internal error",
+			args: args{
+				bookID: 1,
+				param:  "bookID",
+			},
+			want: want{
+				status: http.StatusInternalServerError,
+				error:  errors.New("some internal error"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			rr := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/book/{%s}/restore", tt.args.param), nil)
+			ww := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add(tt.args.param, strconv.Itoa(tt.args.bookID))
+
+			rr = rr.WithContext(context.WithValue(rr.Context(), chi.RouteCtxKey, rctx))
+
+			router := chi.NewRouter()
+			val := validator.New()
+
+			uc := &usecase.BookMock{
+				RestoreFunc: func(ctx context.Context, bookID uint64) (*book.Schema, error) {
+					if tt.want.error != nil {
+						return nil, tt.want.error
+					}
+					return &book.Schema{ID: bookID}, nil
+				},
+			}
+
+			h := RegisterHTTPEndPoints(router, val, uc)
+
+			h.Restore(ww, rr)
+
+			assert.Equal(t, tt.want.status, ww.Code)
+		})
+	}
+}
+
+func TestHandler_Preconditions(t *testing.T) {
+	stored := &book.Schema{
+		ID:            1,
+		Title:         "mock title",
+		PublishedDate: time.Date(2022, 3, 9, 0, 0, 0, 0, time.UTC),
+		Description:   "mock description",
+		UpdatedAt:     time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Version:       2,
+	}
+	current := stored.Revision().Tag()
+	stale := etag.Revision{UpdatedAt: stored.UpdatedAt, Version: 1}.Tag()
+
+	update := `{"title":"new title","published_date":"2022-03-09T00:00:00Z","image_url":"https://example.com/image.png","description":"mock description"}`
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		header  string
+		value   string
+		readErr error
+		// writeErr is what the usecase returns if the write is let through,
+		// which must then carry the revision it expects.
+		writeErr error
+		written  bool
+		status   int
+	}{
+		{name: "get sets etag", method: http.MethodGet, status: http.StatusOK},
+		{name: "get not modified", method: http.MethodGet, header: "If-None-Match", value: current, status: http.StatusNotModified},
+		{name: "get modified since", method: http.MethodGet, header: "If-None-Match", value: stale, status: http.StatusOK},
+		{name: "put current", method: http.MethodPut, body: update, header: "If-Match", value: current, written: true, status: http.StatusOK},
+		{name: "put stale", method: http.MethodPut, body: update, header: "If-Match", value: stale, status: http.StatusPreconditionFailed},
+		{name: "put lost race", method: http.MethodPut, body: update, header: "If-Match", value: current, writeErr: message.ErrPreconditionFailed, written: true, status: http.StatusPreconditionFailed},
+		{name: "put deleted book", method: http.MethodPut, body: update, header: "If-Match", value: current, readErr: message.ErrBadRequest, status: http.StatusPreconditionFailed},
+		{name: "patch current", method: http.MethodPatch, body: `{"title":"new title"}`, header: "If-Match", value: current, written: true, status: http.StatusOK},
+		{name: "patch stale", method: http.MethodPatch, body: `{"title":"new title"}`, header: "If-Match", value: stale, status: http.StatusPreconditionFailed},
+		{name: "delete current", method: http.MethodDelete, header: "If-Match", value: current, written: true, status: http.StatusOK},
+		{name: "delete any", method: http.MethodDelete, header: "If-Match", value: "*", written: true, status: http.StatusOK},
+		{name: "delete stale", method: http.MethodDelete, header: "If-Match", value: stale, status: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRequest(tt.method, "/api/v1/book/1", bytes.NewBufferString(tt.body))
+			if tt.header != "" {
+				rr.Header.Set(tt.header, tt.value)
+			}
+			ww := httptest.NewRecorder()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("bookID", "1")
+			rr = rr.WithContext(context.WithValue(rr.Context(), chi.RouteCtxKey, rctx))
+
+			written := false
+			write := func(ctx context.Context) error {
+				written = true
+				rev, ok := etag.FromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, stored.Revision(), rev)
+				return tt.writeErr
+			}
+			updated := *stored
+			updated.Version++
+
+			uc := &usecase.BookMock{
+				ReadFunc: func(ctx context.Context, bookID uint64) (*book.Schema, error) {
+					if tt.readErr != nil {
+						return nil, tt.readErr
+					}
+					return stored, nil
+				},
+				UpdateFunc: func(ctx context.Context, req *book.UpdateRequest) (*book.Schema, error) {
+					if err := write(ctx); err != nil {
+						return nil, err
+					}
+					return &updated, nil
+				},
+				PatchFunc: func(ctx context.Context, req *book.PatchRequest) (*book.Schema, error) {
+					if err := write(ctx); err != nil {
+						return nil, err
+					}
+					return &updated, nil
+				},
+				DeleteFunc: func(ctx context.Context, bookID uint64) error {
+					return write(ctx)
+				},
+			}
+
+			h := RegisterHTTPEndPoints(chi.NewRouter(), validator.New(), uc)
+
+			switch tt.method {
+			case http.MethodGet:
+				h.Get(ww, rr)
+			case http.MethodPut:
+				h.Update(ww, rr)
+			case http.MethodPatch:
+				h.Patch(ww, rr)
+			case http.MethodDelete:
+				h.Delete(ww, rr)
+			}
+
+			assert.Equal(t, tt.status, ww.Code)
+			assert.Equal(t, tt.written, written)
+			switch {
+			case tt.method == http.MethodGet:
+				assert.Equal(t, current, ww.Header().Get("ETag"))
+			case tt.status == http.StatusOK && tt.method != http.MethodDelete:
+				assert.Equal(t, updated.Revision().Tag(), ww.Header().Get("ETag"))
+			}
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, ww.Body.Bytes())
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"github.com/gmhafiz/go8/internal/domain/book/usecase"
+	"github.com/gmhafiz/go8/internal/middleware"
 )
 
 func RegisterHTTPEndPoints(router *chi.Mux, validator *validator.Validate, uc usecase.Book) *Handler {
@@ -13,9 +14,21 @@ func RegisterHTTPEndPoints(router *chi.Mux, validator *validator.Validate, uc us
 	router.Route("/api/v1/book", func(router chi.Router) {
 		router.Get("/", h.List)
 		router.Get("/{bookID}", h.Get)
-		router.Post("/", h.Create)
-		router.Put("/{bookID}", h.Update)
-		router.Delete("/{bookID}", h.Delete)
+
+		router.Group(func(router chi.Router) {
+			router.Use(middleware.RequirePermission("books:write"))
+			router.Post("/", h.Create)
+			router.Put("/{bookID}", h.Update)
+			router.Patch("/{bookID}", h.Patch)
+			router.Delete("/{bookID}", h.Delete)
+			router.Post("/{bookID}/restore", h.Restore)
+		})
+
+		router.With(middleware.RequirePermission("trash:read")).Get("/trash", h.Trash)
 	})
+
+	// v2 lists books in the same envelope as other lists, which v1 cannot
+	// change without breaking its clients.
+	router.Get("/api/v2/book", h.ListV2)
 	return h
 }
@@ -3,8 +3,20 @@ package book
 import (
 	"database/sql"
 	"time"
+
+	"github.com/gmhafiz/go8/internal/utility/etag"
+	"github.com/gmhafiz/go8/internal/utility/filter"
 )
 
+// SortKeys are what books can be sorted by. Each is also its column.
+var SortKeys = []string{"title", "published_date", "created_at", "updated_at"}
+
+// DefaultOrder lists newest books first when no sort is given.
+var DefaultOrder = filter.Order{Key: "created_at", Desc: true}
+
+// TrashOrder lists most recently deleted books first.
+var TrashOrder = filter.Order{Key: "deleted_at", Desc: true}
+
 type Schema struct {
 	ID            uint64       `db:"id"`
 	Title         string       `db:"title"`
@@ -14,4 +26,42 @@ type Schema struct {
 	CreatedAt     time.Time    `db:"created_at"`
 	UpdatedAt     time.Time    `db:"updated_at"`
 	DeletedAt     sql.NullTime `db:"deleted_at" swaggertype:"string"`
+	Version       int64        `db:"version"`
+
+	// Only set by search.
+	Rank                 sql.NullFloat64 `db:"rank"`
+	TitleHighlight       sql.NullString  `db:"title_highlight"`
+	DescriptionHighlight sql.NullString  `db:"description_highlight"`
+}
+
+// Revision tells this version of b apart from others.
+func (b *Schema) Revision() etag.Revision {
+	return etag.Revision{UpdatedAt: b.UpdatedAt, Version: b.Version}
+}
+
+// SortValue returns the value of b for one of SortKeys, or TrashOrder, as
+// kept in cursors.
+func (b *Schema) SortValue(key string) string {
+	switch key {
+	case "title":
+		return b.Title
+	case "published_date":
+		return b.PublishedDate.Format(time.RFC3339Nano)
+	case "created_at":
+		return b.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return b.UpdatedAt.Format(time.RFC3339Nano)
+	case "deleted_at":
+		return b.DeletedAt.Time.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// Cursor marks b in a list sorted by orders.
+func (b *Schema) Cursor(orders []filter.Order) filter.Cursor {
+	values := make([]string, 0, len(orders))
+	for _, o := range orders {
+		values = append(values, b.SortValue(o.Key))
+	}
+	return filter.Cursor{Values: values, ID: b.ID}
 }
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"entgo.io/ent"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/third_party/elasticsearch"
+)
+
+// BookIndex is the Elasticsearch index books are searched in.
+const BookIndex = "books"
+
+const selectBooksAfter = "SELECT " + bookColumns + " FROM books WHERE deleted_at IS NULL AND id > $1 ORDER BY id LIMIT $2"
+
+// BookMapping returns the mapping of BookIndex. Title and description are
+// analysed with the built-in analyser of language, which, for common
+// languages, shares its name with the PostgreSQL text search configuration.
+func BookMapping(language string) []byte {
+	mapping, _ := json.Marshal(map[string]any{
+		"mappings": map[string]any{
+			"properties": map[string]any{
+				"id":             map[string]string{"type": "long"},
+				"title":          map[string]string{"type": "text", "analyzer": language},
+				"description":    map[string]string{"type": "text", "analyzer": language},
+				"image_url":      map[string]any{"type": "keyword", "index": false},
+				"published_date": map[string]string{"type": "date"},
+				"created_at":     map[string]string{"type": "date"},
+				"updated_at":     map[string]string{"type": "date"},
+			},
+		},
+	})
+	return mapping
+}
+
+type bookDocument struct {
+	ID            uint64    `json:"id"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	ImageURL      string    `json:"image_url"`
+	PublishedDate time.Time `json:"published_date"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func newBookDocument(b *book.Schema) *bookDocument {
+	return &bookDocument{
+		ID:            b.ID,
+		Title:         b.Title,
+		Description:   b.Description,
+		ImageURL:      b.ImageURL,
+		PublishedDate: b.PublishedDate,
+		CreatedAt:     b.CreatedAt,
+		UpdatedAt:     b.UpdatedAt,
+	}
+}
+
+// bookAction indexes b, or removes it from the index once it is deleted.
+func bookAction(id uint64, b *book.Schema) elasticsearch.Action {
+	action := elasticsearch.Action{ID: strconv.FormatUint(id, 10)}
+	if b != nil && !b.DeletedAt.Valid {
+		action.Document = newBookDocument(b)
+	}
+	return action
+}
+
+type elasticSearch struct {
+	es       *elasticsearch.Client
+	fallback Searcher
+}
+
+// NewElasticSearch searches books in Elasticsearch. Searches are served by
+// fallback while Elasticsearch is unavailable.
+func NewElasticSearch(es *elasticsearch.Client, fallback Searcher) *elasticSearch {
+	return &elasticSearch{
+		es:       es,
+		fallback: fallback,
+	}
+}
+
+// Search supports the same syntax as the PostgreSQL search: "quoted phrases",
+// OR with |, and -excluded words.
+func (r *elasticSearch) Search(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
+	if f == nil {
+		return nil, errors.New("filter cannot be nil")
+	}
+
+	resp, err := r.es.Search(ctx, BookIndex, map[string]any{
+		"from": f.Base.Offset,
+		"size": f.Base.Limit,
+		"query": map[string]any{
+			"simple_query_string": map[string]any{
+				"query":            f.SearchQuery(),
+				"fields":           []string{"title^2", "description"},
+				"default_operator": "and",
+			},
+		},
+		"highlight": map[string]any{
+			"pre_tags":  []string{"<mark>"},
+			"post_tags": []string{"</mark>"},
+			"fields": map[string]any{
+				"title":       map[string]any{"number_of_fragments": 0, "no_match_size": 255},
+				"description": map[string]any{"number_of_fragments": 2, "fragment_size": 150, "no_match_size": 150},
+			},
+		},
+	})
+	if errors.Is(err, elasticsearch.ErrUnavailable) {
+		slog.WarnContext(ctx, "searching books in postgres instead", "error", err)
+		return r.fallback.Search(ctx, f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searching books: %w", err)
+	}
+
+	books := make([]*book.Schema, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		var doc bookDocument
+		if err = json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, fmt.Errorf("decoding book %s: %w", hit.ID, err)
+		}
+
+		books = append(books, &book.Schema{
+			ID:                   doc.ID,
+			Title:                doc.Title,
+			PublishedDate:        doc.PublishedDate,
+			ImageURL:             doc.ImageURL,
+			Description:          doc.Description,
+			CreatedAt:            doc.CreatedAt,
+			UpdatedAt:            doc.UpdatedAt,
+			Rank:                 sql.NullFloat64{Float64: hit.Score, Valid: true},
+			TitleHighlight:       highlight(hit, "title"),
+			DescriptionHighlight: highlight(hit, "description"),
+		})
+	}
+
+	return books, nil
+}
+
+func highlight(hit elasticsearch.Hit, field string) sql.NullString {
+	fragments, ok := hit.Highlight[field]
+	if !ok {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strings.Join(fragments, " ... "), Valid: true}
+}
+
+type elasticIndexer struct {
+	es *elasticsearch.Client
+}
+
+func (i elasticIndexer) index(ctx context.Context, books map[uint64]*book.Schema) error {
+	actions := make([]elasticsearch.Action, 0, len(books))
+	for id, b := range books {
+		actions = append(actions, bookAction(id, b))
+	}
+	return i.es.Bulk(ctx, BookIndex, actions)
+}
+
+// NewElasticSync keeps BookIndex up to date with the writes made through
+// repo. The index falls behind if Elasticsearch is unavailable during a
+// write, until books are reindexed.
+func NewElasticSync(repo Book, es *elasticsearch.Client) *indexSync {
+	return newIndexSync(repo, elasticIndexer{es: es})
+}
+
+// ElasticSyncHook keeps BookIndex up to date with books written through ent,
+// such as those created along with their author. Books written in a
+// transaction are indexed once it commits.
+func ElasticSyncHook(client *gen.Client, es *elasticsearch.Client) ent.Hook {
+	return syncHook(client, elasticIndexer{es: es})
+}
+
+// Reindex writes every book that is not deleted to BookIndex, batchSize at a
+// time, and returns how many were written. With recreate, the index is
+// emptied first so that books deleted meanwhile and mapping changes are
+// taken into account.
+func Reindex(ctx context.Context, db *sqlx.DB, es *elasticsearch.Client, language string, batchSize int, recreate bool) (int, error) {
+	if recreate {
+		if err := es.DeleteIndex(ctx, BookIndex); err != nil {
+			return 0, err
+		}
+	}
+	if err := es.CreateIndex(ctx, BookIndex, BookMapping(language)); err != nil {
+		return 0, err
+	}
+
+	var (
+		total  int
+		lastID uint64
+	)
+	for {
+		var books []*book.Schema
+		if err := db.SelectContext(ctx, &books, selectBooksAfter, lastID, batchSize); err != nil {
+			return total, err
+		}
+		if len(books) == 0 {
+			return total, nil
+		}
+
+		actions := make([]elasticsearch.Action, 0, len(books))
+		for _, b := range books {
+			actions = append(actions, bookAction(b.ID, b))
+		}
+		if err := es.Bulk(ctx, BookIndex, actions); err != nil {
+			return total, err
+		}
+
+		total += len(books)
+		lastID = books[len(books)-1].ID
+	}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/third_party/elasticsearch"
+	"github.com/gmhafiz/go8/third_party/elasticsearch/elasticsearchtest"
+)
+
+func TestElasticSearch_Search(t *testing.T) {
+	ctx := context.Background()
+	published := time.Date(2020, 1, 1, 15, 4, 5, 0, time.UTC)
+	f := &book.Filter{
+		Base:  filter.Filter{Limit: 10, Offset: 20, Search: true},
+		Query: `"field guide" -sharks`,
+	}
+
+	t.Run("maps hits", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		srv.OnSearch(func(index string, body map[string]any) any {
+			assert.Equal(t, BookIndex, index)
+			return map[string]any{
+				"hits": map[string]any{
+					"hits": []map[string]any{
+						{
+							"_id":    "7",
+							"_score": 2.5,
+							"_source": bookDocument{
+								ID:            7,
+								Title:         "A field guide",
+								Description:   "Spotting gophers",
+								PublishedDate: published,
+							},
+							"highlight": map[string][]string{
+								"title":       {"A <mark>field</mark> <mark>guide</mark>"},
+								"description": {"Spotting", "gophers"},
+							},
+						},
+					},
+				},
+			}
+		})
+		searcher := NewElasticSearch(elasticsearch.New(srv.Elasticsearch()), &SearcherMock{})
+
+		got, err := searcher.Search(ctx, f)
+		assert.Nil(t, err)
+		assert.Equal(t, []*book.Schema{
+			{
+				ID:                   7,
+				Title:                "A field guide",
+				Description:          "Spotting gophers",
+				PublishedDate:        published,
+				Rank:                 sql.NullFloat64{Float64: 2.5, Valid: true},
+				TitleHighlight:       sql.NullString{String: "A <mark>field</mark> <mark>guide</mark>", Valid: true},
+				DescriptionHighlight: sql.NullString{String: "Spotting ... gophers", Valid: true},
+			},
+		}, got)
+
+		searches := srv.Searches()
+		assert.Len(t, searches, 1)
+		assert.EqualValues(t, 20, searches[0]["from"])
+		assert.EqualValues(t, 10, searches[0]["size"])
+		query := searches[0]["query"].(map[string]any)["simple_query_string"].(map[string]any)
+		assert.Equal(t, `"field guide" -sharks`, query["query"])
+	})
+
+	t.Run("falls back when unavailable", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		srv.FailWith(http.StatusServiceUnavailable)
+
+		fallback := &SearcherMock{
+			SearchFunc: func(ctx context.Context, req *book.Filter) ([]*book.Schema, error) {
+				return []*book.Schema{{ID: 1}}, nil
+			},
+		}
+		searcher := NewElasticSearch(elasticsearch.New(srv.Elasticsearch()), fallback)
+
+		got, err := searcher.Search(ctx, f)
+		assert.Nil(t, err)
+		assert.Equal(t, []*book.Schema{{ID: 1}}, got)
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		searcher := NewElasticSearch(elasticsearch.New(srv.Elasticsearch()), &SearcherMock{})
+
+		_, err := searcher.Search(ctx, nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestElasticSync(t *testing.T) {
+	ctx := context.Background()
+	srv := elasticsearchtest.New(t)
+
+	stored := map[uint64]*book.Schema{}
+	repo := NewElasticSync(&BookMock{
+		CreateFunc: func(ctx context.Context, req *book.CreateRequest) (uint64, error) {
+			stored[1] = &book.Schema{ID: 1, Title: req.Title, Description: req.Description}
+			return 1, nil
+		},
+		UpdateFunc: func(ctx context.Context, req *book.UpdateRequest) error {
+			stored[req.ID].Title = req.Title
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, bookID uint64) error {
+			stored[bookID].DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		},
+		RestoreFunc: func(ctx context.Context, bookID uint64) error {
+			stored[bookID].DeletedAt = sql.NullTime{}
+			return nil
+		},
+		ReadFunc: func(ctx context.Context, bookID uint64) (*book.Schema, error) {
+			return stored[bookID], nil
+		},
+	}, elasticsearch.New(srv.Elasticsearch()))
+
+	indexed := func() *bookDocument {
+		raw, ok := srv.Documents(BookIndex)["1"]
+		if !ok {
+			return nil
+		}
+		var doc bookDocument
+		assert.Nil(t, json.Unmarshal(raw, &doc))
+		return &doc
+	}
+
+	_, err := repo.Create(ctx, &book.CreateRequest{Title: "first", Description: "description"})
+	assert.Nil(t, err)
+	assert.Equal(t, "first", indexed().Title)
+
+	err = repo.Update(ctx, &book.UpdateRequest{ID: 1, Title: "second"})
+	assert.Nil(t, err)
+	assert.Equal(t, "second", indexed().Title)
+
+	err = repo.Delete(ctx, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, indexed())
+
+	err = repo.Restore(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "second", indexed().Title)
+
+	// Writes succeed even if they cannot be indexed.
+	srv.FailWith(http.StatusServiceUnavailable)
+	err = repo.Update(ctx, &book.UpdateRequest{ID: 1, Title: "third"})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"entgo.io/ent"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/searchindex"
+)
+
+// BookFields are the fields books are indexed by. Words found in the title
+// weigh twice as much.
+var BookFields = []searchindex.Field{
+	{Name: "title", Boost: 2},
+	{Name: "description", Boost: 1},
+}
+
+const selectBooksByIDs = "SELECT " + bookColumns + " FROM books WHERE id = ANY($1) AND deleted_at IS NULL"
+
+func bookFields(b *book.Schema) map[string]string {
+	return map[string]string{
+		"title":       b.Title,
+		"description": b.Description,
+	}
+}
+
+type embeddedSearch struct {
+	idx      *searchindex.Index
+	db       *sqlx.DB
+	fallback Searcher
+}
+
+// NewEmbeddedSearch searches books in idx and loads them from db. Searches
+// are served by fallback until idx is ready.
+func NewEmbeddedSearch(idx *searchindex.Index, db *sqlx.DB, fallback Searcher) *embeddedSearch {
+	return &embeddedSearch{
+		idx:      idx,
+		db:       db,
+		fallback: fallback,
+	}
+}
+
+// Search finds books having every word of f.Query, most relevant first. A
+// word ending with * matches every word starting with it.
+func (r *embeddedSearch) Search(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
+	if f == nil {
+		return nil, errors.New("filter cannot be nil")
+	}
+	if !r.idx.Ready() {
+		return r.fallback.Search(ctx, f)
+	}
+
+	query := f.SearchQuery()
+	hits, _ := r.idx.Search(query, f.Base.Offset, f.Base.Limit)
+	if len(hits) == 0 {
+		return []*book.Schema{}, nil
+	}
+
+	ids := make([]int64, 0, len(hits))
+	for _, hit := range hits {
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("book id %q in search index: %w", hit.ID, err)
+		}
+		ids = append(ids, id)
+	}
+
+	var found []*book.Schema
+	if err := r.db.SelectContext(ctx, &found, selectBooksByIDs, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("loading books found: %w", err)
+	}
+	byID := make(map[uint64]*book.Schema, len(found))
+	for _, b := range found {
+		byID[b.ID] = b
+	}
+
+	// A book deleted since it was indexed is left out.
+	books := make([]*book.Schema, 0, len(hits))
+	for n, hit := range hits {
+		b, ok := byID[uint64(ids[n])]
+		if !ok {
+			continue
+		}
+		b.Rank = sql.NullFloat64{Float64: hit.Score, Valid: true}
+		b.TitleHighlight = sql.NullString{String: r.idx.Highlight(b.Title, query, 0), Valid: true}
+		b.DescriptionHighlight = sql.NullString{String: r.idx.Highlight(b.Description, query, 20), Valid: true}
+		books = append(books, b)
+	}
+
+	return books, nil
+}
+
+type embeddedIndexer struct {
+	idx *searchindex.Index
+}
+
+func (i embeddedIndexer) index(_ context.Context, books map[uint64]*book.Schema) error {
+	for id, b := range books {
+		if b == nil || b.DeletedAt.Valid {
+			i.idx.Delete(strconv.FormatUint(id, 10))
+			continue
+		}
+		i.idx.Put(strconv.FormatUint(id, 10), bookFields(b))
+	}
+	return nil
+}
+
+// NewEmbeddedSync keeps idx up to date with the writes made through repo.
+func NewEmbeddedSync(repo Book, idx *searchindex.Index) *indexSync {
+	return newIndexSync(repo, embeddedIndexer{idx: idx})
+}
+
+// EmbeddedSyncHook keeps idx up to date with books written through ent.
+func EmbeddedSyncHook(client *gen.Client, idx *searchindex.Index) ent.Hook {
+	return syncHook(client, embeddedIndexer{idx: idx})
+}
+
+// RebuildEmbedded replaces the content of idx with every book that is not
+// deleted, read batchSize at a time.
+func RebuildEmbedded(ctx context.Context, db *sqlx.DB, idx *searchindex.Index, batchSize int) error {
+	return idx.Rebuild(func(put func(id string, doc map[string]string)) error {
+		var lastID uint64
+		for {
+			var books []*book.Schema
+			if err := db.SelectContext(ctx, &books, selectBooksAfter, lastID, batchSize); err != nil {
+				return err
+			}
+			if len(books) == 0 {
+				return nil
+			}
+
+			for _, b := range books {
+				put(strconv.FormatUint(b.ID, 10), bookFields(b))
+			}
+			lastID = books[len(books)-1].ID
+		}
+	})
+}
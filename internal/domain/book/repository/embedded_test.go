@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/internal/utility/searchindex"
+)
+
+func newSearchIndex(t *testing.T) *searchindex.Index {
+	idx, err := searchindex.Open(filepath.Join(t.TempDir(), "books.idx"), "english", 0, BookFields...)
+	assert.Nil(t, err)
+	return idx
+}
+
+func TestEmbeddedSearch_Search(t *testing.T) {
+	ctx := context.Background()
+	f := &book.Filter{
+		Base:  filter.Filter{Limit: 10, Search: true},
+		Query: "gophers",
+	}
+
+	t.Run("falls back until ready", func(t *testing.T) {
+		fallback := &SearcherMock{
+			SearchFunc: func(ctx context.Context, req *book.Filter) ([]*book.Schema, error) {
+				return []*book.Schema{{ID: 1}}, nil
+			},
+		}
+		searcher := NewEmbeddedSearch(newSearchIndex(t), nil, fallback)
+
+		got, err := searcher.Search(ctx, f)
+		assert.Nil(t, err)
+		assert.Equal(t, []*book.Schema{{ID: 1}}, got)
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		idx := newSearchIndex(t)
+		assert.Nil(t, idx.Rebuild(func(put func(id string, doc map[string]string)) error {
+			return nil
+		}))
+		searcher := NewEmbeddedSearch(idx, nil, &SearcherMock{})
+
+		got, err := searcher.Search(ctx, f)
+		assert.Nil(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		searcher := NewEmbeddedSearch(newSearchIndex(t), nil, &SearcherMock{})
+
+		_, err := searcher.Search(ctx, nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestEmbeddedSync(t *testing.T) {
+	ctx := context.Background()
+	idx := newSearchIndex(t)
+
+	stored := map[uint64]*book.Schema{}
+	repo := NewEmbeddedSync(&BookMock{
+		CreateFunc: func(ctx context.Context, req *book.CreateRequest) (uint64, error) {
+			stored[1] = &book.Schema{ID: 1, Title: req.Title, Description: req.Description}
+			return 1, nil
+		},
+		UpdateFunc: func(ctx context.Context, req *book.UpdateRequest) error {
+			stored[req.ID].Title = req.Title
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, bookID uint64) error {
+			stored[bookID].DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		},
+		RestoreFunc: func(ctx context.Context, bookID uint64) error {
+			stored[bookID].DeletedAt = sql.NullTime{}
+			return nil
+		},
+		ReadFunc: func(ctx context.Context, bookID uint64) (*book.Schema, error) {
+			return stored[bookID], nil
+		},
+	}, idx)
+
+	found := func(query string) int {
+		_, total := idx.Search(query, 0, 10)
+		return total
+	}
+
+	_, err := repo.Create(ctx, &book.CreateRequest{Title: "first", Description: "description"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, found("first"))
+
+	err = repo.Update(ctx, &book.UpdateRequest{ID: 1, Title: "second"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, found("first"))
+	assert.Equal(t, 1, found("second"))
+
+	err = repo.Delete(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, found("second"))
+
+	err = repo.Restore(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, found("second"))
+}
@@ -5,39 +5,94 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 
 	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/etag"
+	"github.com/gmhafiz/go8/internal/utility/filter"
 	"github.com/gmhafiz/go8/internal/utility/message"
 )
 
-//go:generate mirip -rm -pkg repository -out repo_mock.go . Book
+//go:generate mirip -rm -pkg repository -out repo_mock.go . Book Searcher
 type Book interface {
 	Create(ctx context.Context, book *book.CreateRequest) (uint64, error)
 	List(ctx context.Context, f *book.Filter) ([]*book.Schema, error)
+	ListWithTotal(ctx context.Context, f *book.Filter) ([]*book.Schema, int, error)
 	Read(ctx context.Context, bookID uint64) (*book.Schema, error)
 	Update(ctx context.Context, book *book.UpdateRequest) error
+	Patch(ctx context.Context, book *book.PatchRequest) error
 	Delete(ctx context.Context, bookID uint64) error
 	Search(ctx context.Context, req *book.Filter) ([]*book.Schema, error)
+	Trash(ctx context.Context, f *book.Filter) ([]*book.Schema, error)
+	Restore(ctx context.Context, bookID uint64) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+type Searcher interface {
+	Search(ctx context.Context, req *book.Filter) ([]*book.Schema, error)
 }
 
 type bookRepository struct {
 	db *sqlx.DB
+
+	searchLanguage string
+}
+
+type Options func(r *bookRepository)
+
+// WithSearchLanguage sets the text search configuration used to parse search
+// queries. It must match the one the books' search column is generated with.
+func WithSearchLanguage(language string) Options {
+	return func(r *bookRepository) {
+		if language != "" {
+			r.searchLanguage = language
+		}
+	}
 }
 
+// bookColumns is listed explicitly because the search column has no place in
+// book.Schema. Images cleared by a patch are NULL, and read as empty.
+const bookColumns = "id, title, published_date, coalesce(image_url, '') AS image_url, description, created_at, updated_at, deleted_at, version"
+
 const (
-	InsertIntoBooks         = "INSERT INTO books (title, published_date, image_url, description) VALUES ($1, $2, $3, $4) RETURNING id"
-	SelectFromBooks         = "SELECT * FROM books ORDER BY created_at DESC"
-	SelectFromBooksPaginate = "SELECT * FROM books ORDER BY created_at DESC LIMIT $1 OFFSET $2"
-	SelectBookByID          = "SELECT * FROM books where id = $1"
-	UpdateBook              = "UPDATE books set title = $1, description = $2, published_date = $3, image_url = $4 where id = $5 RETURNING id"
-	DeleteByID              = "DELETE FROM books where id = ($1) RETURNING id"
-	SearchBooks             = "SELECT * FROM books where title like '%' || $1 || '%' and description like '%'|| $2 || '%' ORDER BY published_date DESC"
-	SearchBooksPaginate     = "SELECT * FROM books where title like '%' || '%' || $1 || '%' || '%' and description like '%'|| $2 || '%' ORDER BY published_date DESC LIMIT $3 OFFSET $4"
+	InsertIntoBooks       = "INSERT INTO books (title, published_date, image_url, description) VALUES ($1, $2, $3, $4) RETURNING id"
+	SelectBookByID        = "SELECT " + bookColumns + " FROM books where id = $1 AND deleted_at IS NULL"
+	UpdateBook            = "UPDATE books set title = $1, description = $2, published_date = $3, image_url = $4 where id = $5 AND deleted_at IS NULL RETURNING id"
+	UpdateBookIfUnchanged = "UPDATE books set title = $1, description = $2, published_date = $3, image_url = $4 where id = $5 AND deleted_at IS NULL AND updated_at = $6 AND version = $7 RETURNING id"
+	DeleteByID            = "UPDATE books SET deleted_at = current_timestamp where id = ($1) AND deleted_at IS NULL RETURNING id"
+	DeleteByIDIfUnchanged = "UPDATE books SET deleted_at = current_timestamp where id = ($1) AND deleted_at IS NULL AND updated_at = $2 AND version = $3 RETURNING id"
+	RestoreByID           = "UPDATE books SET deleted_at = NULL where id = $1 AND deleted_at IS NOT NULL RETURNING id"
+	PurgeDeleted          = "DELETE FROM books WHERE deleted_at < $1"
+
+	// SearchBooksPaginate ranks matches in the inner query so that the
+	// costly ts_headline only runs on books of the requested page.
+	SearchBooksPaginate = `SELECT ` + bookColumns + `, rank,
+       ts_headline($1::regconfig, title, query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>') AS title_highlight,
+       ts_headline($1::regconfig, description, query, 'MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<mark>, StopSel=</mark>') AS description_highlight
+FROM (SELECT ` + bookColumns + `, query, ts_rank_cd(search, query) AS rank
+      FROM books, websearch_to_tsquery($1::regconfig, $2) query
+      WHERE search @@ query
+        AND deleted_at IS NULL
+      ORDER BY rank DESC, id DESC
+      LIMIT $3 OFFSET $4) matches
+ORDER BY rank DESC, id DESC`
 )
 
-func New(db *sqlx.DB) *bookRepository {
-	return &bookRepository{db: db}
+func New(db *sqlx.DB, opts ...Options) *bookRepository {
+	r := &bookRepository{
+		db:             db,
+		searchLanguage: "english",
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 func (r *bookRepository) Create(ctx context.Context, req *book.CreateRequest) (bookID uint64, err error) {
@@ -48,26 +103,150 @@ func (r *bookRepository) Create(ctx context.Context, req *book.CreateRequest) (b
 	return bookID, nil
 }
 
+// List lists books, newest first. Pages start after or end before f's
+// cursor, if any, rather than at its offset.
 func (r *bookRepository) List(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
 	if f == nil {
 		return nil, errors.New("filter cannot be nil")
 	}
-	if f.Base.DisablePaging {
-		var books []*book.Schema
-		err := r.db.SelectContext(ctx, &books, SelectFromBooks)
+
+	return r.page(ctx, " WHERE deleted_at IS NULL", nil, []filter.Order{book.DefaultOrder}, &f.Base)
+}
+
+// ListWithTotal lists books sorted by f.Base.SortBy, newest first by
+// default, and published within f's range if any. Pages start after or end
+// before f's cursor, if any, rather than at its offset. It also returns how
+// many books are in that range across all pages.
+func (r *bookRepository) ListWithTotal(ctx context.Context, f *book.Filter) ([]*book.Schema, int, error) {
+	if f == nil {
+		return nil, 0, errors.New("filter cannot be nil")
+	}
+
+	var (
+		where strings.Builder
+		args  []any
+	)
+	where.WriteString(" WHERE deleted_at IS NULL")
+	if !f.PublishedFrom.IsZero() {
+		args = append(args, f.PublishedFrom)
+		fmt.Fprintf(&where, " AND published_date >= $%d", len(args))
+	}
+	if !f.PublishedUntil.IsZero() {
+		args = append(args, f.PublishedUntil)
+		fmt.Fprintf(&where, " AND published_date < $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT count(*) FROM books"+where.String(), args...); err != nil {
+		return nil, 0, message.ErrFetchingBook
+	}
+
+	books, err := r.page(ctx, where.String(), args, f.Base.Orders(book.SortKeys, book.DefaultOrder), &f.Base)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return books, total, nil
+}
+
+// page selects the books matching where and args, sorted by orders. Pages
+// start after or end before base's cursor, if any, rather than at its
+// offset.
+func (r *bookRepository) page(ctx context.Context, where string, args []any, orders []filter.Order, base *filter.Filter) ([]*book.Schema, error) {
+	cursor, backwards := base.After, false
+	if base.Before != nil {
+		cursor, backwards = base.Before, true
+	}
+	if cursor != nil {
+		predicate, keysetArgs, err := keyset(orders, cursor, backwards, len(args))
 		if err != nil {
-			return nil, message.ErrFetchingBook
+			return nil, err
 		}
+		where += " AND " + predicate
+		args = append(args, keysetArgs...)
+	}
 
-		return books, nil
-	} else {
-		var books []*book.Schema
-		err := r.db.SelectContext(ctx, &books, SelectFromBooksPaginate, f.Base.Limit, f.Base.Offset)
-		if err != nil {
-			return nil, message.ErrFetchingBook
+	query := "SELECT " + bookColumns + " FROM books" + where + orderBy(orders, backwards)
+	if !base.DisablePaging {
+		args = append(args, base.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if cursor == nil {
+			args = append(args, base.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
 		}
-		return books, nil
 	}
+
+	books := make([]*book.Schema, 0)
+	if err := r.db.SelectContext(ctx, &books, query, args...); err != nil {
+		return nil, message.ErrFetchingBook
+	}
+	if backwards {
+		slices.Reverse(books)
+	}
+
+	return books, nil
+}
+
+// orderBy sorts by orders, then by id so that pages are stable when sorting
+// by a column with duplicate values. Backwards reverses every direction to
+// read the rows before a cursor. Keys are columns, and come from
+// book.SortKeys only, so nothing from the request ends up in the query as
+// is.
+func orderBy(orders []filter.Order, backwards bool) string {
+	columns := make([]string, 0, len(orders)+1)
+	for _, o := range orders {
+		columns = append(columns, o.Key+" "+direction(o.Desc != backwards))
+	}
+	columns = append(columns, "id "+direction(backwards))
+
+	return " ORDER BY " + strings.Join(columns, ", ")
+}
+
+func direction(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// keyset returns the predicate keeping rows sorted after cursor, or before
+// it if backwards, by orders then id. Its placeholders are numbered after
+// the n arguments already in the query.
+//
+// For (a ASC, b DESC, id), rows after (1, 2, 3) are those where
+// a > 1 OR (a = 1 AND b < 2) OR (a = 1 AND b = 2 AND id > 3).
+func keyset(orders []filter.Order, cursor *filter.Cursor, backwards bool, n int) (string, []any, error) {
+	if len(cursor.Values) != len(orders) {
+		return "", nil, filter.ErrInvalidCursor
+	}
+
+	columns := make([]string, 0, len(orders)+1)
+	descs := make([]bool, 0, len(orders)+1)
+	args := make([]any, 0, len(orders)+1)
+	for i, o := range orders {
+		columns = append(columns, o.Key)
+		descs = append(descs, o.Desc)
+		args = append(args, cursor.Values[i])
+	}
+	columns = append(columns, "id")
+	descs = append(descs, false)
+	args = append(args, cursor.ID)
+
+	disjuncts := make([]string, 0, len(columns))
+	for i := range columns {
+		conjuncts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conjuncts = append(conjuncts, fmt.Sprintf("%s = $%d", columns[j], n+j+1))
+		}
+		op := ">"
+		if descs[i] != backwards {
+			op = "<"
+		}
+		conjuncts = append(conjuncts, fmt.Sprintf("%s %s $%d", columns[i], op, n+i+1))
+		disjuncts = append(disjuncts, "("+strings.Join(conjuncts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(disjuncts, " OR ") + ")", args, nil
 }
 
 func (r *bookRepository) Read(ctx context.Context, bookID uint64) (*book.Schema, error) {
@@ -83,41 +262,159 @@ func (r *bookRepository) Read(ctx context.Context, bookID uint64) (*book.Schema,
 	return &b, err
 }
 
+// Update writes every column of the book. If ctx carries a revision, the
+// book must still be at it, or message.ErrPreconditionFailed is returned.
 func (r *bookRepository) Update(ctx context.Context, book *book.UpdateRequest) error {
 	var returnedID int
 
-	err := r.db.QueryRowContext(ctx, UpdateBook,
+	query := UpdateBook
+	args := []any{
 		book.Title,
 		book.Description,
 		book.PublishedDate,
 		book.ImageURL,
 		book.ID,
-	).Scan(&returnedID)
+	}
+	rev, guarded := etag.FromContext(ctx)
+	if guarded {
+		query = UpdateBookIfUnchanged
+		args = append(args, rev.UpdatedAt, rev.Version)
+	}
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&returnedID)
+	if err != nil {
+		if guarded && errors.Is(err, sql.ErrNoRows) {
+			return message.ErrPreconditionFailed
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Patch writes the columns listed in req.Changed, and nothing if there are
+// none. message.ErrNoRecord is returned when there is no such book. If ctx
+// carries a revision, the book must still be at it, or
+// message.ErrPreconditionFailed is returned.
+func (r *bookRepository) Patch(ctx context.Context, req *book.PatchRequest) error {
+	if len(req.Changed) == 0 {
+		return nil
+	}
+
+	set := make([]string, 0, len(req.Changed))
+	args := make([]any, 0, len(req.Changed)+1)
+	for _, column := range req.Changed {
+		switch column {
+		case "title":
+			args = append(args, req.Title)
+		case "published_date":
+			args = append(args, req.PublishedDate)
+		case "image_url":
+			args = append(args, req.ImageURL)
+		case "description":
+			args = append(args, req.Description)
+		default:
+			return fmt.Errorf("book has no column %q to patch", column)
+		}
+		set = append(set, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	args = append(args, req.ID)
+	where := fmt.Sprintf("id = $%d AND deleted_at IS NULL", len(args))
+
+	rev, guarded := etag.FromContext(ctx)
+	if guarded {
+		args = append(args, rev.UpdatedAt, rev.Version)
+		where += fmt.Sprintf(" AND updated_at = $%d AND version = $%d", len(args)-1, len(args))
+	}
+
+	query := fmt.Sprintf("UPDATE books SET %s WHERE %s RETURNING id", strings.Join(set, ", "), where)
+
+	var returnedID int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&returnedID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if guarded {
+				return message.ErrPreconditionFailed
+			}
+			return message.ErrNoRecord
+		}
 		return err
 	}
 
 	return nil
 }
 
+// Delete moves a book to the trash. If ctx carries a revision, the book must
+// still be at it, or message.ErrPreconditionFailed is returned.
 func (r *bookRepository) Delete(ctx context.Context, bookID uint64) error {
 	var returnedID int
-	err := r.db.QueryRowContext(ctx, DeleteByID, bookID).Scan(&returnedID)
+
+	query, args := DeleteByID, []any{bookID}
+	rev, guarded := etag.FromContext(ctx)
+	if guarded {
+		query = DeleteByIDIfUnchanged
+		args = append(args, rev.UpdatedAt, rev.Version)
+	}
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&returnedID)
 	if err != nil {
+		if guarded && errors.Is(err, sql.ErrNoRows) {
+			return message.ErrPreconditionFailed
+		}
 		return fmt.Errorf("ID not found: %w", err)
 	}
 
 	return nil
 }
 
+// Trash lists soft-deleted books, most recently deleted first. Like List, it
+// pages by cursor when f has one.
+func (r *bookRepository) Trash(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
+	if f == nil {
+		return nil, errors.New("filter cannot be nil")
+	}
+
+	return r.page(ctx, " WHERE deleted_at IS NOT NULL", nil, []filter.Order{book.TrashOrder}, &f.Base)
+}
+
+// Restore brings back a soft-deleted book. message.ErrNoRecord is returned
+// when there is no such book in the trash.
+func (r *bookRepository) Restore(ctx context.Context, bookID uint64) error {
+	var returnedID int
+	err := r.db.QueryRowContext(ctx, RestoreByID, bookID).Scan(&returnedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return message.ErrNoRecord
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Purge hard-deletes books soft-deleted before deletedBefore and returns how
+// many were removed.
+func (r *bookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, PurgeDeleted, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Search finds books matching f.Query, most relevant first. Words in the
+// title weigh more than those in the description. The query follows the web
+// search syntax of websearch_to_tsquery, so it may contain "quoted phrases",
+// OR and -excluded words.
 func (r *bookRepository) Search(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
 	if f == nil {
 		return nil, errors.New("filter cannot be nil")
 	}
 	var books []*book.Schema
 	err := r.db.SelectContext(ctx, &books, SearchBooksPaginate,
-		f.Title,
-		f.Description,
+		r.searchLanguage,
+		f.SearchQuery(),
 		f.Base.Limit,
 		f.Base.Offset,
 	)
@@ -20,6 +20,7 @@ import (
 
 	"github.com/gmhafiz/go8/database"
 	"github.com/gmhafiz/go8/internal/domain/book"
+	"github.com/gmhafiz/go8/internal/utility/etag"
 	"github.com/gmhafiz/go8/internal/utility/filter"
 	"github.com/gmhafiz/go8/internal/utility/message"
 )
@@ -358,6 +359,131 @@ func TestRepository_List(t *testing.T) {
 	}
 }
 
+func TestRepository_ListWithTotal(t *testing.T) {
+	ctx := context.Background()
+	client := sqlxDBClient(migrator.DB)
+	repo := New(client)
+
+	// Published long ago so that books of other tests are out of range.
+	ids := map[string]uint64{}
+	for _, b := range []struct{ title, published string }{
+		{"b", "1801-03-01T10:00:00Z"},
+		{"a", "1801-03-01T12:00:00Z"},
+		{"c", "1801-06-30T23:00:00Z"},
+		{"d", "1801-07-01T00:00:00Z"},
+	} {
+		id, err := repo.Create(ctx, &book.CreateRequest{
+			Title:         b.title,
+			PublishedDate: b.published,
+			ImageURL:      "https://example.com/image.png",
+			Description:   "description",
+		})
+		assert.Nil(t, err)
+		ids[b.title] = id
+	}
+
+	from := time.Date(1801, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(1801, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		f         *book.Filter
+		wantIDs   []uint64
+		wantTotal int
+	}{
+		{
+			name: "within range, sorted by several columns",
+			f: &book.Filter{
+				Base: filter.Filter{
+					Limit:  10,
+					Sort:   map[string]string{"published_date": "DESC", "title": "ASC"},
+					SortBy: []string{"published_date", "title"},
+				},
+				PublishedFrom:  from,
+				PublishedUntil: until,
+			},
+			wantIDs:   []uint64{ids["c"], ids["a"], ids["b"]},
+			wantTotal: 3,
+		},
+		{
+			name: "total counts every page",
+			f: &book.Filter{
+				Base: filter.Filter{
+					Limit:  1,
+					Offset: 1,
+					Sort:   map[string]string{"title": "ASC"},
+					SortBy: []string{"title"},
+				},
+				PublishedFrom:  from,
+				PublishedUntil: until,
+			},
+			wantIDs:   []uint64{ids["b"]},
+			wantTotal: 3,
+		},
+		{
+			name: "unknown sort key is ignored",
+			f: &book.Filter{
+				Base: filter.Filter{
+					Limit:  10,
+					Sort:   map[string]string{"id; DROP TABLE books": "ASC", "title": "DESC"},
+					SortBy: []string{"id; DROP TABLE books", "title"},
+				},
+				PublishedFrom:  until,
+				PublishedUntil: until.AddDate(0, 0, 1),
+			},
+			wantIDs:   []uint64{ids["d"]},
+			wantTotal: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := repo.ListWithTotal(ctx, tt.f)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+
+			var gotIDs []uint64
+			for _, b := range got {
+				gotIDs = append(gotIDs, b.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+
+	t.Run("pages by cursor both ways", func(t *testing.T) {
+		base := filter.Filter{
+			Limit:  2,
+			Sort:   map[string]string{"published_date": "DESC"},
+			SortBy: []string{"published_date"},
+		}
+		orders := base.Orders(book.SortKeys, book.DefaultOrder)
+		page := func(after, before *filter.Cursor) []uint64 {
+			f := &book.Filter{Base: base, PublishedFrom: from, PublishedUntil: until.AddDate(0, 0, 1)}
+			f.Base.After, f.Base.Before = after, before
+			got, total, err := repo.ListWithTotal(ctx, f)
+			assert.Nil(t, err)
+			assert.Equal(t, 4, total)
+
+			var gotIDs []uint64
+			for _, b := range got {
+				gotIDs = append(gotIDs, b.ID)
+			}
+			return gotIDs
+		}
+		cursorOf := func(id uint64) *filter.Cursor {
+			b, err := repo.Read(ctx, id)
+			assert.Nil(t, err)
+			c := b.Cursor(orders)
+			return &c
+		}
+
+		assert.Equal(t, []uint64{ids["d"], ids["c"]}, page(nil, nil))
+		assert.Equal(t, []uint64{ids["a"], ids["b"]}, page(cursorOf(ids["c"]), nil))
+		assert.Equal(t, []uint64{ids["b"]}, page(cursorOf(ids["a"]), nil))
+		assert.Equal(t, []uint64{ids["c"], ids["a"]}, page(nil, cursorOf(ids["b"])))
+	})
+}
+
 func TestRepository_Read(t *testing.T) {
 	type args struct {
 		context.Context
@@ -519,6 +645,124 @@ func TestRepository_Update(t *testing.T) {
 	}
 }
 
+func TestRepository_Patch(t *testing.T) {
+	ctx := context.Background()
+	client := sqlxDBClient(migrator.DB)
+	repo := New(client)
+
+	bookID, err := repo.Create(ctx, &book.CreateRequest{
+		Title:         "patched title",
+		PublishedDate: "2020-02-17T00:00:00Z",
+		ImageURL:      "https://example.com/image.png",
+		Description:   "patched description",
+	})
+	assert.Nil(t, err)
+
+	before, err := repo.Read(ctx, bookID)
+	assert.Nil(t, err)
+
+	t.Run("writes changed columns only", func(t *testing.T) {
+		req := book.Patchable(before)
+		req.Title = "new title"
+		req.Description = "ignored as unchanged"
+		req.Changed = []string{"title"}
+
+		err := repo.Patch(ctx, req)
+		assert.Nil(t, err)
+
+		got, err := repo.Read(ctx, bookID)
+		assert.Nil(t, err)
+		assert.Equal(t, "new title", got.Title)
+		assert.Equal(t, before.Description, got.Description)
+		assert.Equal(t, before.ImageURL, got.ImageURL)
+		assert.Equal(t, before.PublishedDate.UTC(), got.PublishedDate.UTC())
+	})
+
+	t.Run("clears image", func(t *testing.T) {
+		req := book.Patchable(before)
+		req.ImageURL = nil
+		req.Changed = []string{"image_url"}
+
+		err := repo.Patch(ctx, req)
+		assert.Nil(t, err)
+
+		got, err := repo.Read(ctx, bookID)
+		assert.Nil(t, err)
+		assert.Equal(t, "", got.ImageURL)
+	})
+
+	t.Run("no such book", func(t *testing.T) {
+		err := repo.Patch(ctx, &book.PatchRequest{
+			ID:      math.MaxInt - 1,
+			Title:   "title",
+			Changed: []string{"title"},
+		})
+		assert.ErrorIs(t, err, message.ErrNoRecord)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		err := repo.Patch(ctx, &book.PatchRequest{
+			ID:      bookID,
+			Changed: []string{"deleted_at"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_UpdateIfUnchanged(t *testing.T) {
+	ctx := context.Background()
+	client := sqlxDBClient(migrator.DB)
+	repo := New(client)
+
+	bookID, err := repo.Create(ctx, &book.CreateRequest{
+		Title:         "guarded title",
+		PublishedDate: "2020-02-17T00:00:00Z",
+		ImageURL:      "https://example.com/image.png",
+		Description:   "guarded description",
+	})
+	assert.Nil(t, err)
+
+	read, err := repo.Read(ctx, bookID)
+	assert.Nil(t, err)
+	stale := etag.NewContext(ctx, read.Revision())
+
+	req := &book.UpdateRequest{
+		ID:            bookID,
+		Title:         "first editor",
+		PublishedDate: "2020-02-17T00:00:00Z",
+		ImageURL:      "https://example.com/image.png",
+		Description:   "guarded description",
+	}
+	err = repo.Update(stale, req)
+	assert.Nil(t, err)
+
+	got, err := repo.Read(ctx, bookID)
+	assert.Nil(t, err)
+	assert.Equal(t, read.Version+1, got.Version)
+
+	// A second editor still holding the first revision must not overwrite
+	// the first one.
+	req.Title = "second editor"
+	err = repo.Update(stale, req)
+	assert.ErrorIs(t, err, message.ErrPreconditionFailed)
+
+	patch := book.Patchable(read)
+	patch.Title = "second editor"
+	patch.Changed = []string{"title"}
+	err = repo.Patch(stale, patch)
+	assert.ErrorIs(t, err, message.ErrPreconditionFailed)
+
+	err = repo.Delete(stale, bookID)
+	assert.ErrorIs(t, err, message.ErrPreconditionFailed)
+
+	got, err = repo.Read(ctx, bookID)
+	assert.Nil(t, err)
+	assert.Equal(t, "first editor", got.Title)
+
+	err = repo.Delete(etag.NewContext(ctx, got.Revision()), bookID)
+	assert.Nil(t, err)
+}
+
 func TestRepository_Delete(t *testing.T) {
 	type args struct {
 		context.Context
@@ -663,6 +907,116 @@ func TestRepository_Search(t *testing.T) {
 	}
 }
 
+func TestRepository_SearchRelevance(t *testing.T) {
+	ctx := context.Background()
+	client := sqlxDBClient(migrator.DB)
+	repo := New(client, WithSearchLanguage("english"))
+
+	inDescription, err := repo.Create(ctx, &book.CreateRequest{
+		Title:         "A field guide",
+		PublishedDate: "2020-01-01T15:04:05Z",
+		ImageURL:      "https://example.com/image.png",
+		Description:   "Spotting wild gophers in their burrows",
+	})
+	assert.Nil(t, err)
+	inTitle, err := repo.Create(ctx, &book.CreateRequest{
+		Title:         "Gophers of the world",
+		PublishedDate: "2020-01-01T15:04:05Z",
+		ImageURL:      "https://example.com/image.png",
+		Description:   "An atlas",
+	})
+	assert.Nil(t, err)
+
+	got, err := repo.Search(ctx, &book.Filter{
+		Base:  filter.Filter{Limit: 10, Search: true},
+		Query: "gopher -sharks",
+	})
+	assert.Nil(t, err)
+	assert.Len(t, got, 2)
+
+	// stemmed "gopher" matches "Gophers", and a title match ranks first.
+	assert.Equal(t, inTitle, got[0].ID)
+	assert.Equal(t, inDescription, got[1].ID)
+	assert.True(t, got[0].Rank.Float64 > got[1].Rank.Float64)
+	assert.Equal(t, "<mark>Gophers</mark> of the world", got[0].TitleHighlight.String)
+	assert.Contains(t, got[1].DescriptionHighlight.String, "<mark>gophers</mark>")
+
+	got, err = repo.Search(ctx, &book.Filter{
+		Base:  filter.Filter{Limit: 10, Search: true},
+		Query: "gopher -atlas",
+	})
+	assert.Nil(t, err)
+	assert.Len(t, got, 1)
+	assert.Equal(t, inDescription, got[0].ID)
+}
+
+func TestRepository_Trash(t *testing.T) {
+	ctx := context.Background()
+	client := sqlxDBClient(migrator.DB)
+	repo := New(client)
+
+	bookID, err := repo.Create(ctx, &book.CreateRequest{
+		Title:         "trashed",
+		PublishedDate: "2020-01-01T15:04:05Z",
+		ImageURL:      "https://example.com/image.png",
+		Description:   "description",
+	})
+	assert.Nil(t, err)
+
+	err = repo.Delete(ctx, bookID)
+	assert.Nil(t, err)
+
+	t.Run("deleted book is hidden", func(t *testing.T) {
+		_, err := repo.Read(ctx, bookID)
+		assert.Equal(t, message.ErrBadRequest, err)
+
+		books, err := repo.List(ctx, &book.Filter{Base: filter.Filter{DisablePaging: true}})
+		assert.Nil(t, err)
+		for _, b := range books {
+			assert.NotEqual(t, bookID, b.ID)
+		}
+
+		err = repo.Delete(ctx, bookID)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("deleted book is in trash", func(t *testing.T) {
+		books, err := repo.Trash(ctx, &book.Filter{Base: filter.Filter{Limit: 10}})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, books)
+		assert.Equal(t, bookID, books[0].ID)
+		assert.True(t, books[0].DeletedAt.Valid)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		err := repo.Restore(ctx, bookID)
+		assert.Nil(t, err)
+
+		got, err := repo.Read(ctx, bookID)
+		assert.Nil(t, err)
+		assert.False(t, got.DeletedAt.Valid)
+
+		err = repo.Restore(ctx, bookID)
+		assert.Equal(t, message.ErrNoRecord, err)
+	})
+
+	t.Run("purge keeps books within retention", func(t *testing.T) {
+		err := repo.Delete(ctx, bookID)
+		assert.Nil(t, err)
+
+		purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		purged, err = repo.Purge(ctx, time.Now().Add(time.Second))
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, purged, int64(1))
+
+		err = repo.Restore(ctx, bookID)
+		assert.Equal(t, message.ErrNoRecord, err)
+	})
+}
+
 func sqlxDBClient(db *sql.DB) *sqlx.DB {
 	return sqlx.NewDb(db, DBDriver)
 }
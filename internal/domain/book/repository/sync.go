@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"entgo.io/ent"
+
+	"github.com/gmhafiz/go8/ent/gen"
+	entBook "github.com/gmhafiz/go8/ent/gen/book"
+	"github.com/gmhafiz/go8/ent/gen/hook"
+	"github.com/gmhafiz/go8/internal/domain/book"
+)
+
+// indexer writes books to a search index. A book that is nil or deleted is
+// removed from it.
+type indexer interface {
+	index(ctx context.Context, books map[uint64]*book.Schema) error
+}
+
+type indexSync struct {
+	Book
+	indexer indexer
+}
+
+func newIndexSync(repo Book, indexer indexer) *indexSync {
+	return &indexSync{
+		Book:    repo,
+		indexer: indexer,
+	}
+}
+
+func (r *indexSync) Create(ctx context.Context, req *book.CreateRequest) (uint64, error) {
+	bookID, err := r.Book.Create(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	r.sync(ctx, bookID)
+
+	return bookID, nil
+}
+
+func (r *indexSync) Update(ctx context.Context, req *book.UpdateRequest) error {
+	if err := r.Book.Update(ctx, req); err != nil {
+		return err
+	}
+	r.sync(ctx, req.ID)
+
+	return nil
+}
+
+func (r *indexSync) Patch(ctx context.Context, req *book.PatchRequest) error {
+	if err := r.Book.Patch(ctx, req); err != nil {
+		return err
+	}
+	r.sync(ctx, req.ID)
+
+	return nil
+}
+
+func (r *indexSync) Delete(ctx context.Context, bookID uint64) error {
+	if err := r.Book.Delete(ctx, bookID); err != nil {
+		return err
+	}
+	r.write(ctx, bookID, nil)
+
+	return nil
+}
+
+func (r *indexSync) Restore(ctx context.Context, bookID uint64) error {
+	if err := r.Book.Restore(ctx, bookID); err != nil {
+		return err
+	}
+	r.sync(ctx, bookID)
+
+	return nil
+}
+
+func (r *indexSync) sync(ctx context.Context, bookID uint64) {
+	b, err := r.Book.Read(ctx, bookID)
+	if err != nil {
+		slog.WarnContext(ctx, "reading book to index", "id", bookID, "error", err)
+		return
+	}
+	r.write(ctx, bookID, b)
+}
+
+func (r *indexSync) write(ctx context.Context, bookID uint64, b *book.Schema) {
+	if err := r.indexer.index(ctx, map[uint64]*book.Schema{bookID: b}); err != nil {
+		slog.WarnContext(ctx, "indexing book", "id", bookID, "error", err)
+	}
+}
+
+// syncHook indexes books written through ent. Books written in a
+// transaction are indexed once it commits.
+func syncHook(client *gen.Client, indexer indexer) ent.Hook {
+	return hook.On(func(next ent.Mutator) ent.Mutator {
+		return hook.BookFunc(func(ctx context.Context, m *gen.BookMutation) (ent.Value, error) {
+			var ids []uint64
+			if !m.Op().Is(ent.OpCreate) {
+				var err error
+				if ids, err = m.IDs(ctx); err != nil {
+					return nil, err
+				}
+			}
+
+			v, err := next.Mutate(ctx, m)
+			if err != nil {
+				return v, err
+			}
+			if b, ok := v.(*gen.Book); ok && m.Op().Is(ent.OpCreate) {
+				ids = []uint64{b.ID}
+			}
+
+			sync := func(ctx context.Context) {
+				if err := syncEntBooks(ctx, client, indexer, ids); err != nil {
+					slog.WarnContext(ctx, "indexing books", "ids", ids, "error", err)
+				}
+			}
+			if tx, err := m.Tx(); err == nil {
+				tx.OnCommit(func(next gen.Committer) gen.Committer {
+					return gen.CommitFunc(func(ctx context.Context, tx *gen.Tx) error {
+						if err := next.Commit(ctx, tx); err != nil {
+							return err
+						}
+						sync(ctx)
+						return nil
+					})
+				})
+			} else {
+				sync(ctx)
+			}
+
+			return v, nil
+		})
+	}, ent.OpCreate|ent.OpUpdate|ent.OpUpdateOne|ent.OpDelete|ent.OpDeleteOne)
+}
+
+func syncEntBooks(ctx context.Context, client *gen.Client, indexer indexer, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	books, err := client.Book.Query().
+		Where(entBook.IDIn(ids...)).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[uint64]*book.Schema, len(ids))
+	for _, id := range ids {
+		found[id] = nil
+	}
+	for _, b := range books {
+		found[b.ID] = &book.Schema{
+			ID:            b.ID,
+			Title:         b.Title,
+			PublishedDate: b.PublishedDate,
+			ImageURL:      b.ImageURL,
+			Description:   b.Description,
+			CreatedAt:     b.CreatedAt,
+			UpdatedAt:     b.UpdatedAt,
+			Version:       b.Version,
+		}
+		if b.DeletedAt != nil {
+			found[b.ID].DeletedAt = sql.NullTime{Time: *b.DeletedAt, Valid: true}
+		}
+	}
+
+	return indexer.index(ctx, found)
+}
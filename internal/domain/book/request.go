@@ -1,5 +1,7 @@
 package book
 
+import "time"
+
 type CreateRequest struct {
 	Title         string `json:"title" validate:"required"`
 	PublishedDate string `json:"published_date" validate:"required"`
@@ -14,3 +16,57 @@ type UpdateRequest struct {
 	ImageURL      string `json:"image_url" validate:"url"`
 	Description   string `json:"description" validate:"required"`
 }
+
+// PatchRequest is a book as JSON merge patches see it. Patches are applied
+// to the stored book, so that the result is validated in full, but only the
+// columns listed in Changed are written.
+type PatchRequest struct {
+	ID            uint64   `json:"-"`
+	Title         string   `json:"title" validate:"required"`
+	PublishedDate string   `json:"published_date" validate:"required"`
+	ImageURL      *string  `json:"image_url" validate:"omitempty,url"`
+	Description   string   `json:"description" validate:"required"`
+	Changed       []string `json:"-"`
+}
+
+// Patchable returns b as the document merge patches are applied to. A book
+// without an image has a null image_url.
+func Patchable(b *Schema) *PatchRequest {
+	p := &PatchRequest{
+		ID:            b.ID,
+		Title:         b.Title,
+		PublishedDate: b.PublishedDate.Format(time.RFC3339Nano),
+		Description:   b.Description,
+	}
+	if b.ImageURL != "" {
+		imageURL := b.ImageURL
+		p.ImageURL = &imageURL
+	}
+
+	return p
+}
+
+// Diff sets Changed to the columns p gives a value other than the one in
+// before.
+func (p *PatchRequest) Diff(before *PatchRequest) {
+	p.Changed = nil
+	if p.Title != before.Title {
+		p.Changed = append(p.Changed, "title")
+	}
+	if p.PublishedDate != before.PublishedDate {
+		p.Changed = append(p.Changed, "published_date")
+	}
+	if !equal(p.ImageURL, before.ImageURL) {
+		p.Changed = append(p.Changed, "image_url")
+	}
+	if p.Description != before.Description {
+		p.Changed = append(p.Changed, "description")
+	}
+}
+
+func equal(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
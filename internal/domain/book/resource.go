@@ -10,6 +10,17 @@ type Res struct {
 	PublishedDate time.Time `json:"published_date"`
 	ImageURL      string    `json:"image_url" swaggertype:"string"`
 	Description   string    `json:"description" swaggertype:"string"`
+	// DeletedAt is only set for books in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Highlight is only set for search results.
+	Highlight *Highlight `json:"highlight,omitempty"`
+}
+
+// Highlight holds snippets of a book where search terms are wrapped in
+// <mark></mark>.
+type Highlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
 }
 
 func Resource(book *Schema) *Res {
@@ -23,6 +34,15 @@ func Resource(book *Schema) *Res {
 		ImageURL:      book.ImageURL,
 		Description:   book.Description,
 	}
+	if book.DeletedAt.Valid {
+		resource.DeletedAt = &book.DeletedAt.Time
+	}
+	if book.TitleHighlight.Valid || book.DescriptionHighlight.Valid {
+		resource.Highlight = &Highlight{
+			Title:       book.TitleHighlight.String,
+			Description: book.DescriptionHighlight.String,
+		}
+	}
 
 	return resource
 }
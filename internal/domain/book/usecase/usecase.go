@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/gmhafiz/go8/internal/domain/book"
 	"github.com/gmhafiz/go8/internal/domain/book/repository"
@@ -11,19 +12,27 @@ import (
 type Book interface {
 	Create(ctx context.Context, book *book.CreateRequest) (*book.Schema, error)
 	List(ctx context.Context, f *book.Filter) ([]*book.Schema, error)
+	ListWithTotal(ctx context.Context, f *book.Filter) ([]*book.Schema, int, error)
 	Read(ctx context.Context, bookID uint64) (*book.Schema, error)
 	Update(ctx context.Context, book *book.UpdateRequest) (*book.Schema, error)
+	Patch(ctx context.Context, book *book.PatchRequest) (*book.Schema, error)
 	Delete(ctx context.Context, bookID uint64) error
 	Search(ctx context.Context, req *book.Filter) ([]*book.Schema, error)
+	Trash(ctx context.Context, f *book.Filter) ([]*book.Schema, error)
+	Restore(ctx context.Context, bookID uint64) (*book.Schema, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 type BookUseCase struct {
 	bookRepo repository.Book
+
+	searchRepo repository.Searcher
 }
 
-func New(bookRepo repository.Book) *BookUseCase {
+func New(bookRepo repository.Book, searcher repository.Searcher) *BookUseCase {
 	return &BookUseCase{
-		bookRepo: bookRepo,
+		bookRepo:   bookRepo,
+		searchRepo: searcher,
 	}
 }
 
@@ -43,6 +52,10 @@ func (u *BookUseCase) List(ctx context.Context, f *book.Filter) ([]*book.Schema,
 	return u.bookRepo.List(ctx, f)
 }
 
+func (u *BookUseCase) ListWithTotal(ctx context.Context, f *book.Filter) ([]*book.Schema, int, error) {
+	return u.bookRepo.ListWithTotal(ctx, f)
+}
+
 func (u *BookUseCase) Read(ctx context.Context, bookID uint64) (*book.Schema, error) {
 	return u.bookRepo.Read(ctx, bookID)
 }
@@ -55,10 +68,34 @@ func (u *BookUseCase) Update(ctx context.Context, book *book.UpdateRequest) (*bo
 	return u.bookRepo.Read(ctx, book.ID)
 }
 
+func (u *BookUseCase) Patch(ctx context.Context, book *book.PatchRequest) (*book.Schema, error) {
+	err := u.bookRepo.Patch(ctx, book)
+	if err != nil {
+		return nil, err
+	}
+	return u.bookRepo.Read(ctx, book.ID)
+}
+
 func (u *BookUseCase) Delete(ctx context.Context, bookID uint64) error {
 	return u.bookRepo.Delete(ctx, bookID)
 }
 
 func (u *BookUseCase) Search(ctx context.Context, req *book.Filter) ([]*book.Schema, error) {
-	return u.bookRepo.Search(ctx, req)
+	return u.searchRepo.Search(ctx, req)
+}
+
+func (u *BookUseCase) Trash(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
+	return u.bookRepo.Trash(ctx, f)
+}
+
+func (u *BookUseCase) Restore(ctx context.Context, bookID uint64) (*book.Schema, error) {
+	err := u.bookRepo.Restore(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	return u.bookRepo.Read(ctx, bookID)
+}
+
+func (u *BookUseCase) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return u.bookRepo.Purge(ctx, deletedBefore)
 }
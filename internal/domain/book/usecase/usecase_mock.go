@@ -6,16 +6,22 @@ package usecase
 import (
 	"context"
 	"github.com/gmhafiz/go8/internal/domain/book"
+	"time"
 )
 
 // BookMock is a mock implementation of Book.
 type BookMock struct {
-	CreateFunc func(ctx context.Context, bookMiripParam *book.CreateRequest) (*book.Schema, error)
-	DeleteFunc func(ctx context.Context, bookID uint64) error
-	ListFunc   func(ctx context.Context, f *book.Filter) ([]*book.Schema, error)
-	ReadFunc   func(ctx context.Context, bookID uint64) (*book.Schema, error)
-	SearchFunc func(ctx context.Context, req *book.Filter) ([]*book.Schema, error)
-	UpdateFunc func(ctx context.Context, bookMiripParam *book.UpdateRequest) (*book.Schema, error)
+	CreateFunc        func(ctx context.Context, bookMiripParam *book.CreateRequest) (*book.Schema, error)
+	DeleteFunc        func(ctx context.Context, bookID uint64) error
+	ListFunc          func(ctx context.Context, f *book.Filter) ([]*book.Schema, error)
+	ListWithTotalFunc func(ctx context.Context, f *book.Filter) ([]*book.Schema, int, error)
+	PatchFunc         func(ctx context.Context, bookMiripParam *book.PatchRequest) (*book.Schema, error)
+	PurgeFunc         func(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ReadFunc          func(ctx context.Context, bookID uint64) (*book.Schema, error)
+	RestoreFunc       func(ctx context.Context, bookID uint64) (*book.Schema, error)
+	SearchFunc        func(ctx context.Context, req *book.Filter) ([]*book.Schema, error)
+	TrashFunc         func(ctx context.Context, f *book.Filter) ([]*book.Schema, error)
+	UpdateFunc        func(ctx context.Context, bookMiripParam *book.UpdateRequest) (*book.Schema, error)
 }
 
 func (m *BookMock) Create(ctx context.Context, bookMiripParam *book.CreateRequest) (*book.Schema, error) {
@@ -30,14 +36,34 @@ func (m *BookMock) List(ctx context.Context, f *book.Filter) ([]*book.Schema, er
 	return m.ListFunc(ctx, f)
 }
 
+func (m *BookMock) ListWithTotal(ctx context.Context, f *book.Filter) ([]*book.Schema, int, error) {
+	return m.ListWithTotalFunc(ctx, f)
+}
+
+func (m *BookMock) Patch(ctx context.Context, bookMiripParam *book.PatchRequest) (*book.Schema, error) {
+	return m.PatchFunc(ctx, bookMiripParam)
+}
+
+func (m *BookMock) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return m.PurgeFunc(ctx, deletedBefore)
+}
+
 func (m *BookMock) Read(ctx context.Context, bookID uint64) (*book.Schema, error) {
 	return m.ReadFunc(ctx, bookID)
 }
 
+func (m *BookMock) Restore(ctx context.Context, bookID uint64) (*book.Schema, error) {
+	return m.RestoreFunc(ctx, bookID)
+}
+
 func (m *BookMock) Search(ctx context.Context, req *book.Filter) ([]*book.Schema, error) {
 	return m.SearchFunc(ctx, req)
 }
 
+func (m *BookMock) Trash(ctx context.Context, f *book.Filter) ([]*book.Schema, error) {
+	return m.TrashFunc(ctx, f)
+}
+
 func (m *BookMock) Update(ctx context.Context, bookMiripParam *book.UpdateRequest) (*book.Schema, error) {
 	return m.UpdateFunc(ctx, bookMiripParam)
 }
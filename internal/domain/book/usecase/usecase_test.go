@@ -11,6 +11,7 @@ import (
 	"github.com/gmhafiz/go8/internal/domain/book"
 	"github.com/gmhafiz/go8/internal/domain/book/repository"
 	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/internal/utility/message"
 )
 
 func TestBookUseCase_Create(t *testing.T) {
@@ -75,7 +76,7 @@ func TestBookUseCase_Create(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			uc := New(test.BookMock)
+			uc := New(test.BookMock, test.BookMock)
 
 			created, err := uc.Create(test.args.ctx, test.args.req)
 			assert.Equal(t, test.want.err, err)
@@ -159,6 +160,23 @@ func TestBookUseCase_List(t *testing.T) {
 	}
 }
 
+func TestBookUseCase_ListWithTotal(t *testing.T) {
+	oneBook := []*book.Schema{{ID: 1, Title: "title 1"}}
+	f := &book.Filter{Base: filter.Filter{Limit: 1}}
+
+	u := New(&repository.BookMock{
+		ListWithTotalFunc: func(ctx context.Context, got *book.Filter) ([]*book.Schema, int, error) {
+			assert.Equal(t, f, got)
+			return oneBook, 3, nil
+		},
+	}, nil)
+
+	got, total, err := u.ListWithTotal(context.Background(), f)
+	assert.Nil(t, err)
+	assert.Equal(t, oneBook, got)
+	assert.Equal(t, 3, total)
+}
+
 func TestBookUseCase_Read(t *testing.T) {
 	type fields struct {
 		bookRepo repository.Book
@@ -288,6 +306,57 @@ func TestBookUseCase_Update(t *testing.T) {
 	}
 }
 
+func TestBookUseCase_Patch(t *testing.T) {
+	published := time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC)
+	patched := &book.Schema{
+		ID:            1,
+		Title:         "new title",
+		PublishedDate: published,
+		Description:   "description",
+	}
+
+	tests := []struct {
+		name     string
+		patchErr error
+		want     *book.Schema
+		wantErr  error
+	}{
+		{
+			name: "simple",
+			want: patched,
+		},
+		{
+			name:     "no such book",
+			patchErr: message.ErrNoRecord,
+			wantErr:  message.ErrNoRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &BookUseCase{
+				bookRepo: &repository.BookMock{
+					PatchFunc: func(ctx context.Context, req *book.PatchRequest) error {
+						assert.Equal(t, []string{"title"}, req.Changed)
+						return tt.patchErr
+					},
+					ReadFunc: func(ctx context.Context, bookID uint64) (*book.Schema, error) {
+						return patched, nil
+					},
+				},
+			}
+
+			got, err := u.Patch(context.Background(), &book.PatchRequest{
+				ID:      1,
+				Title:   "new title",
+				Changed: []string{"title"},
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestBookUseCase_Delete(t *testing.T) {
 	type fields struct {
 		bookRepo repository.Book
@@ -331,7 +400,7 @@ func TestBookUseCase_Delete(t *testing.T) {
 
 func TestBookUseCase_Search(t *testing.T) {
 	type fields struct {
-		bookRepo repository.Book
+		searchRepo repository.Searcher
 	}
 	type args struct {
 		ctx context.Context
@@ -351,7 +420,7 @@ func TestBookUseCase_Search(t *testing.T) {
 		{
 			name: "simple",
 			fields: fields{
-				bookRepo: &repository.BookMock{
+				searchRepo: &repository.SearcherMock{
 					SearchFunc: func(ctx context.Context, req *book.Filter) ([]*book.Schema, error) {
 						return []*book.Schema{
 							{
@@ -396,7 +465,7 @@ func TestBookUseCase_Search(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			u := &BookUseCase{
-				bookRepo: tt.fields.bookRepo,
+				searchRepo: tt.fields.searchRepo,
 			}
 			got, err := u.Search(tt.args.ctx, tt.args.req)
 			assert.Equal(t, tt.wantErr, err)
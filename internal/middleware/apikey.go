@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+const (
+	KeyScopes key = "scopes"
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("api key is invalid or has expired")
+)
+
+// APIKeyFinder looks up the owner and scopes of an API key. If the key is not
+// found or has expired, the returned found flag is false.
+type APIKeyFinder interface {
+	FindAPIKey(ctx context.Context, key string) (userID uint64, scopes []string, found bool, err error)
+}
+
+// APIKey authenticates requests carrying an `Authorization: Bearer <key>`
+// header. On success, user ID is saved under KeyID and the key's scopes under
+// KeyScopes. Requests without the header, or with an access token instead,
+// pass through untouched so that other mechanisms keep working.
+func APIKey(finder APIKeyFinder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := bearerToken(r)
+			if !ok || isJWT(key) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+
+			userID, scopes, found, err := finder.FindAPIKey(ctx, key)
+			if err != nil {
+				slog.ErrorContext(ctx, "finding api key", "error", err)
+				respond.Error(w, http.StatusInternalServerError, nil)
+				return
+			}
+			if !found {
+				respond.Error(w, http.StatusUnauthorized, ErrInvalidAPIKey)
+				return
+			}
+
+			if scopes == nil {
+				scopes = []string{}
+			}
+
+			ctx = context.WithValue(ctx, KeyID, userID)
+			ctx = context.WithValue(ctx, KeyScopes, scopes)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IsAPIKey tells if current request is authenticated using an API key rather
+// than a session cookie.
+func IsAPIKey(ctx context.Context) bool {
+	_, ok := ctx.Value(KeyScopes).([]string)
+	return ok
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
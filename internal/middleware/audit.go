@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"database/sql"
 	"net/http"
 	"time"
 )
@@ -14,18 +15,21 @@ const (
 
 type Action string
 
+// Event is a change made during a request. When an admin is impersonating a
+// user, ActorID is the admin and ImpersonatedID is the user.
 type Event struct {
-	ActorID    uint64    `db:"actor_id" json:"actor_id,omitempty"`
-	TableRowID int       `db:"table_row_id" json:"table_row_id,omitempty"`
-	Table      string    `db:"table_name" json:"table,omitempty"`
-	Action     Action    `db:"action" json:"action,omitempty"`
-	OldValues  string    `db:"old_values" json:"old_values,omitempty"`
-	NewValues  string    `db:"new_values" json:"new_values,omitempty"`
-	HTTPMethod string    `db:"http_method" json:"http_method,omitempty"`
-	URL        string    `db:"url" json:"url,omitempty"`
-	IPAddress  string    `db:"ip_address" json:"ip_address,omitempty"`
-	UserAgent  string    `db:"user_agent" json:"user_agent,omitempty"`
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	ActorID        uint64    `db:"actor_id" json:"actor_id,omitempty"`
+	ImpersonatedID uint64    `db:"impersonated_id" json:"impersonated_id,omitempty"`
+	TableRowID     int       `db:"table_row_id" json:"table_row_id,omitempty"`
+	Table          string    `db:"table_name" json:"table,omitempty"`
+	Action         Action    `db:"action" json:"action,omitempty"`
+	OldValues      string    `db:"old_values" json:"old_values,omitempty"`
+	NewValues      string    `db:"new_values" json:"new_values,omitempty"`
+	HTTPMethod     string    `db:"http_method" json:"http_method,omitempty"`
+	URL            string    `db:"url" json:"url,omitempty"`
+	IPAddress      string    `db:"ip_address" json:"ip_address,omitempty"`
+	UserAgent      string    `db:"user_agent" json:"user_agent,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
 }
 
 func Audit(next http.Handler) http.Handler {
@@ -34,9 +38,14 @@ func Audit(next http.Handler) http.Handler {
 			ActorID:    getUserID(r),
 			HTTPMethod: r.Method,
 			URL:        r.RequestURI,
-			IPAddress:  readUserIP(r),
+			IPAddress:  ClientIP(r),
 			UserAgent:  r.UserAgent(),
 		}
+		// Changes made while impersonating are the admin's doing.
+		if impersonatorID, ok := ImpersonatorID(r.Context()); ok {
+			ev.ImpersonatedID = ev.ActorID
+			ev.ActorID = impersonatorID
+		}
 
 		ctx := context.WithValue(r.Context(), KeyAuditID, ev)
 
@@ -44,21 +53,29 @@ func Audit(next http.Handler) http.Handler {
 	})
 }
 
+// SaveEvent stores an audit event. Changes made by anonymous requests are
+// kept without an actor.
+func SaveEvent(ctx context.Context, db *sql.DB, ev Event) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO audits (actor_id, impersonated_id, table_row_id, table_name, action, old_values,
+		                    new_values, http_method, url, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, nullID(ev.ActorID), nullID(ev.ImpersonatedID), ev.TableRowID, ev.Table, ev.Action, ev.OldValues,
+		ev.NewValues, ev.HTTPMethod, ev.URL, ev.IPAddress, ev.UserAgent)
+
+	return err
+}
+
+func nullID(id uint64) sql.Null[uint64] {
+	return sql.Null[uint64]{V: id, Valid: id != 0}
+}
+
+// getUserID returns logged-in user, whether by session cookie, API key or
+// access token. Each of them saves user ID under KeyID.
 func getUserID(r *http.Request) uint64 {
-	userID, ok := r.Context().Value(KeySession).(uint64)
+	userID, ok := r.Context().Value(KeyID).(uint64)
 	if !ok {
 		return 0
 	}
 	return userID
 }
-
-func readUserIP(r *http.Request) string {
-	ipAddress := r.Header.Get("X-Real-Ip")
-	if ipAddress == "" {
-		ipAddress = r.Header.Get("X-Forwarded-For")
-	}
-	if ipAddress == "" {
-		ipAddress = r.RemoteAddr
-	}
-	return ipAddress
-}
@@ -4,24 +4,75 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
+	"log/slog"
 	"net"
 	"net/http"
 	"time"
 
 	"github.com/gmhafiz/scs/v2"
+
+	"github.com/gmhafiz/go8/internal/utility/respond"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
 )
 
 const (
-	KeyID      key = "id"
-	KeySession key = "session"
+	KeyID        key = "id"
+	KeySession   key = "session"
+	KeyIPAddress key = "ipAddress"
+	KeyUserAgent key = "userAgent"
+	// KeyImpersonatorID holds the ID of the admin who opened an impersonated
+	// session. Like KeyID, it is also the key used in session data.
+	KeyImpersonatorID key = "impersonator_id"
+	KeyAccounts       key = "accounts"
+	// KeySessionDeadline is the key in session data of when a session expires
+	// however active it is. Removing it on login gives the session a new one.
+	KeySessionDeadline key = "session_deadline"
+	KeySessionExpiry   key = "sessionExpiry"
 )
 
-// Authenticate simply checks is current user is logged in by checking token validity in
-// cookie
+var ErrAccountDisabled = errors.New("account has been disabled")
+
+// AccountChecker tells if a user has been disabled.
+type AccountChecker interface {
+	IsDisabled(ctx context.Context, userID uint64) (bool, error)
+}
+
+// Accounts makes the checker available to Authenticate and RequirePermission
+// so that disabled users are refused no matter how they authenticate. Without
+// it, neither checks whether an account is disabled.
+func Accounts(checker AccountChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), KeyAccounts, checker)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ImpersonatorID returns the admin acting as current user, if any.
+func ImpersonatorID(ctx context.Context) (uint64, bool) {
+	id, ok := ctx.Value(KeyImpersonatorID).(uint64)
+	return id, ok
+}
+
+// Authenticate checks if current user is logged in, either with an API key or
+// access token already resolved by APIKey or AccessToken middleware, or by
+// checking token validity in cookie. Logged-in user ID is available in context
+// under KeyID.
 func Authenticate(m *scs.SessionManager) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
+			if IsAPIKey(ctx) || IsAccessToken(ctx) {
+				userID, _ := ctx.Value(KeyID).(uint64)
+				if !enabled(w, r, userID) {
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			token := m.Token(ctx)
 			if token == "" {
 				w.WriteHeader(http.StatusUnauthorized)
@@ -30,6 +81,7 @@ func Authenticate(m *scs.SessionManager) func(http.Handler) http.Handler {
 
 			_, found, err := m.CtxStore.FindCtx(r.Context(), token)
 			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
 			if !found {
@@ -37,11 +89,46 @@ func Authenticate(m *scs.SessionManager) func(http.Handler) http.Handler {
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			// A session may exist without a logged-in user, for example while
+			// waiting for a second factor.
+			userID, ok := m.Get(ctx, string(KeyID)).(uint64)
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !enabled(w, r, userID) {
+				return
+			}
+			ctx = context.WithValue(ctx, KeyID, userID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// enabled writes a 403 and returns false if user has been disabled.
+func enabled(w http.ResponseWriter, r *http.Request, userID uint64) bool {
+	ctx := r.Context()
+
+	checker, ok := ctx.Value(KeyAccounts).(AccountChecker)
+	if !ok {
+		return true
+	}
+
+	disabled, err := checker.IsDisabled(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "checking if account is disabled", "error", err)
+		respond.Error(w, http.StatusInternalServerError, nil)
+		return false
+	}
+	if disabled {
+		respond.Error(w, http.StatusForbidden, ErrAccountDisabled)
+		return false
+	}
+
+	return true
+}
+
 // LoadAndSave is a custom middleware adapted from scs library that saves logged in user ID
 // into request context. To access user ID:
 //
@@ -49,7 +136,10 @@ func Authenticate(m *scs.SessionManager) func(http.Handler) http.Handler {
 //			if !ok {
 //	         // no user ID saved into context
 //			}
-func LoadAndSave(s *scs.SessionManager) func(http.Handler) http.Handler {
+//
+// Sessions expire as decided by expiry. When it is nil, every session lives
+// for SessionManager.Lifetime.
+func LoadAndSave(s *scs.SessionManager, expiry *SessionExpiry) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var token string
@@ -64,6 +154,20 @@ func LoadAndSave(s *scs.SessionManager) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Session store records where a session is used from.
+			ctx = context.WithValue(ctx, KeyIPAddress, ClientIP(r))
+			ctx = context.WithValue(ctx, KeyUserAgent, r.UserAgent())
+
+			// Make logged in user ID available to handlers and middlewares
+			// further down the chain.
+			if userID, ok := s.Get(ctx, string(KeyID)).(uint64); ok {
+				ctx = context.WithValue(ctx, KeyID, userID)
+				ctx = context.WithValue(ctx, KeySessionExpiry, expiry.expiry(ctx, s))
+			}
+			if impersonatorID, ok := s.Get(ctx, string(KeyImpersonatorID)).(uint64); ok {
+				ctx = context.WithValue(ctx, KeyImpersonatorID, impersonatorID)
+			}
+
 			sr := r.WithContext(ctx)
 			bw := &bufferedResponseWriter{ResponseWriter: w}
 			next.ServeHTTP(bw, sr)
@@ -79,15 +183,28 @@ func LoadAndSave(s *scs.SessionManager) func(http.Handler) http.Handler {
 			}
 			ctx = context.WithValue(ctx, KeyID, userID)
 
+			var impersonatorID any
+			impersonatorID, ok = s.Get(ctx, string(KeyImpersonatorID)).(uint64)
+			if !ok {
+				impersonatorID = nil
+			}
+			ctx = context.WithValue(ctx, KeyImpersonatorID, impersonatorID)
+
 			switch s.Status(ctx) {
 			case scs.Modified:
-				token, expiry, err := s.Commit(ctx)
+				expiry.stamp(ctx, s)
+
+				token, _, err := s.Commit(sessionstore.WithExpiry(ctx, expiry.expiry(ctx, s)))
 				if err != nil {
 					s.ErrorFunc(w, r, err)
 					return
 				}
 
-				s.WriteSessionCookie(ctx, w, token, expiry)
+				// Server enforces idle timeout, so a persistent cookie can
+				// last until the deadline.
+				s.WriteSessionCookie(ctx, w, token, expiry.deadline(ctx, s))
+			case scs.Unmodified:
+				expiry.renew(ctx, s)
 			case scs.Destroyed:
 				s.WriteSessionCookie(ctx, w, "", time.Time{})
 			}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+const (
+	KeyPermission key = "permission"
+)
+
+var (
+	ErrUnauthenticated = errors.New("you need to be logged in")
+	ErrForbidden       = errors.New("you do not have permission to perform this action")
+)
+
+// PermissionChecker tells if a user is granted a permission through any of
+// their roles.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID uint64, permission string) (bool, error)
+}
+
+// Permissions makes the checker available to RequirePermission. It must be
+// registered before any route that uses RequirePermission.
+func Permissions(checker PermissionChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), KeyPermission, checker)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission only lets through logged-in users that have the given
+// permission and have not been disabled. Can be used on any chi route group:
+//
+//	router.Group(func(router chi.Router) {
+//		router.Use(middleware.RequirePermission("books:write"))
+//		router.Post("/", h.Create)
+//	})
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			userID, ok := ctx.Value(KeyID).(uint64)
+			if !ok {
+				respond.Error(w, http.StatusUnauthorized, ErrUnauthenticated)
+				return
+			}
+			// Routes guarded by permission alone never pass through
+			// Authenticate, so API keys and access tokens of a disabled user
+			// would otherwise keep working until they expire.
+			if !enabled(w, r, userID) {
+				return
+			}
+
+			checker, ok := ctx.Value(KeyPermission).(PermissionChecker)
+			if !ok {
+				slog.ErrorContext(ctx, "no permission checker registered")
+				respond.Error(w, http.StatusInternalServerError, nil)
+				return
+			}
+
+			allowed, err := checker.HasPermission(ctx, userID, permission)
+			if err != nil {
+				slog.ErrorContext(ctx, "checking permission", "permission", permission, "error", err)
+				respond.Error(w, http.StatusInternalServerError, nil)
+				return
+			}
+			if !allowed {
+				respond.Error(w, http.StatusForbidden, ErrForbidden)
+				return
+			}
+
+			// An API key can only do what both its owner and its scopes allow.
+			if scopes, ok := ctx.Value(KeyScopes).([]string); ok && !slices.Contains(scopes, permission) {
+				respond.Error(w, http.StatusForbidden, ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
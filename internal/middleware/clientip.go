@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	KeyClientIP key = "clientIP"
+)
+
+// RealIP resolves the address of the client once per request. Forwarding
+// headers are only believed when the request comes from one of trusted,
+// which are addresses or CIDR ranges of reverse proxies. Anybody else could
+// claim to be any address, for example to get around login lockout.
+func RealIP(trusted []string) (func(http.Handler) http.Handler, error) {
+	prefixes := make([]netip.Prefix, 0, len(trusted))
+	for _, t := range trusted {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(t)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(t)
+			if addrErr != nil {
+				return nil, fmt.Errorf("trusted proxy %q is neither an address nor a CIDR range", t)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	isTrusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, prefix := range prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+
+			if isTrusted(ip) {
+				ip = forwardedFor(r, ip, isTrusted)
+			}
+
+			ctx := context.WithValue(r.Context(), KeyClientIP, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}, nil
+}
+
+// ClientIP returns the address of the client as resolved by RealIP.
+// Without that middleware, it is the address the request came from. Port is
+// dropped so that the same client is always reported with the same value.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(KeyClientIP).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// forwardedFor returns the client behind trusted proxies. Each proxy appends
+// whoever connected to it to X-Forwarded-For, so the client is the rightmost
+// address not belonging to a proxy. Anything to its left was sent by the
+// client itself and cannot be believed.
+func forwardedFor(r *http.Request, ip string, isTrusted func(string) bool) string {
+	header := r.Header.Get("X-Forwarded-For")
+	if header == "" {
+		if realIP := strings.TrimSpace(r.Header.Get("X-Real-Ip")); realIP != "" {
+			return realIP
+		}
+		return ip
+	}
+
+	hops := strings.Split(header, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !isTrusted(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gmhafiz/go8/config"
+	"github.com/gmhafiz/go8/internal/utility/csrf"
+	"github.com/gmhafiz/go8/internal/utility/respond"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
+)
+
+var (
+	ErrCSRFTokenMissing = errors.New("csrf token is missing, request one from /api/v1/restricted/csrf")
+	ErrCSRFTokenInvalid = errors.New("csrf token is invalid or has expired")
+)
+
+// CSRF requires a valid CSRF token on requests that may modify state and are
+// authenticated with a session cookie. Token is read from cfg.Header, or from
+// cfg.FormField for HTML form submissions. Depending on cfg.Mode, a token is
+// either reused until it expires or deleted once checked.
+//
+// Requests authenticated with an API key or access token are not checked
+// because browsers never attach one on their own. Tokens are kept in the
+// session store. Must be placed after LoadAndSave, APIKey and AccessToken.
+func CSRF(store sessionstore.Store, cfg config.CSRF) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isSafeMethod(r.Method) || isExempt(cfg.Exempt, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+
+			// A bearer header alone proves nothing. Unless it is what
+			// identified the user, the session cookie still does.
+			if IsAPIKey(ctx) || IsAccessToken(ctx) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Without a logged-in session, there is nothing to forge.
+			userID, ok := ctx.Value(KeyID).(uint64)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := csrfToken(r, cfg)
+			if token == "" {
+				respond.Error(w, http.StatusForbidden, ErrCSRFTokenMissing)
+				return
+			}
+
+			if cfg.Mode == config.CSRFModeSingleUse {
+				ok = csrf.ValidAndDeleteTokenForUser(ctx, store, token, userID) == nil
+			} else {
+				ok = csrf.ValidTokenForUser(ctx, store, token, userID)
+			}
+			if !ok {
+				respond.Error(w, http.StatusForbidden, ErrCSRFTokenInvalid)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func csrfToken(r *http.Request, cfg config.CSRF) string {
+	if token := r.Header.Get(cfg.Header); token != "" {
+		return token
+	}
+
+	if cfg.FormField == "" {
+		return ""
+	}
+
+	// Only form bodies are parsed so that JSON bodies are left untouched for
+	// handlers.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return r.PostFormValue(cfg.FormField)
+	default:
+		return ""
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+func isExempt(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+var ErrPreconditionRequired = errors.New("this route needs an If-Match header with the ETag of the record to change")
+
+// RequireIfMatch refuses writes without an If-Match header to the given
+// routes of router with 428 Precondition Required. Routes are patterns as
+// registered on router, each optionally after a method, e.g.
+// "PUT /api/v1/book/{bookID}". Without a method, PUT, PATCH and DELETE are
+// all refused.
+//
+// Whether the header matches is up to the handler, which knows the record.
+func RequireIfMatch(router chi.Routes, routes []string) func(http.Handler) http.Handler {
+	required := make(map[string]bool)
+	for _, route := range routes {
+		method, pattern, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok {
+			for _, m := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+				required[m+" "+method] = true
+			}
+			continue
+		}
+		required[strings.ToUpper(method)+" "+strings.TrimSpace(pattern)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(required) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-Match") == "" {
+				pattern := router.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+				if required[r.Method+" "+pattern] {
+					respond.Error(w, http.StatusPreconditionRequired, ErrPreconditionRequired)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
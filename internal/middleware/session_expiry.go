@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gmhafiz/scs/v2"
+
+	"github.com/gmhafiz/go8/config"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
+)
+
+const defaultRenewInterval = time.Minute
+
+// rememberMeKey is where scs keeps the flag set by SessionManager.RememberMe.
+const rememberMeKey = "__rememberMe"
+
+// SessionExpiry decides when a session expires. A session expires at its
+// deadline, counted from login, however active it is. Before that, it
+// expires once it has not been used for its idle timeout. Sessions of users
+// who asked to be remembered get their own, usually longer, deadline and idle
+// timeout.
+//
+// Every request pushes expiry of its session forward. Rather than writing to
+// the session store on every request, renewals are kept in memory and written
+// in one batch every renew interval. A request made in the last renew
+// interval of an idle timeout may therefore not keep its session alive.
+type SessionExpiry struct {
+	store            sessionstore.Store
+	lifetime         time.Duration
+	idleTimeout      time.Duration
+	rememberLifetime time.Duration
+	rememberIdle     time.Duration
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+	stop    chan chan struct{}
+}
+
+// NewSessionExpiry starts writing renewals to store every
+// SESSION_RENEW_INTERVAL until Stop is called. Remember me durations left at
+// zero fall back to the ones of other sessions. Without an idle timeout,
+// nothing needs renewing.
+func NewSessionExpiry(cfg config.Session, store sessionstore.Store) *SessionExpiry {
+	e := &SessionExpiry{
+		store:            store,
+		lifetime:         cfg.Duration,
+		idleTimeout:      cfg.IdleTimeout,
+		rememberLifetime: cfg.RememberMeDuration,
+		rememberIdle:     cfg.RememberMeIdleTimeout,
+		pending:          make(map[string]time.Time),
+	}
+	if e.rememberLifetime == 0 {
+		e.rememberLifetime = e.lifetime
+	}
+	if e.rememberIdle == 0 {
+		e.rememberIdle = e.idleTimeout
+	}
+
+	if e.idleTimeout > 0 || e.rememberIdle > 0 {
+		interval := cfg.RenewInterval
+		if interval <= 0 {
+			interval = defaultRenewInterval
+		}
+		e.stop = make(chan chan struct{})
+		go e.start(interval)
+	}
+
+	return e
+}
+
+func (e *SessionExpiry) start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			e.flush()
+		case done := <-e.stop:
+			ticker.Stop()
+			e.flush()
+			close(done)
+			return
+		}
+	}
+}
+
+// Stop writes renewals still waiting and terminates the background
+// goroutine. Call it before the session store is closed.
+func (e *SessionExpiry) Stop() {
+	if e == nil || e.stop == nil {
+		return
+	}
+	done := make(chan struct{})
+	e.stop <- done
+	<-done
+}
+
+func (e *SessionExpiry) flush() {
+	e.mu.Lock()
+	pending := e.pending
+	e.pending = make(map[string]time.Time)
+	e.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := e.store.ExtendCtx(context.Background(), pending); err != nil {
+		slog.Error("renewing sessions", "count", len(pending), "error", err)
+	}
+}
+
+// renew queues expiry of an existing session to be written with the next
+// batch. Only the latest expiry of a session is kept.
+func (e *SessionExpiry) renew(ctx context.Context, s *scs.SessionManager) {
+	token := s.Token(ctx)
+	if e == nil || e.stop == nil || token == "" || e.idle(ctx, s) <= 0 {
+		return
+	}
+
+	expiry := e.expiry(ctx, s)
+
+	e.mu.Lock()
+	e.pending[token] = expiry
+	e.mu.Unlock()
+}
+
+// deadline returns when session expires however active it is. A session is
+// given its own deadline once it is committed. Until then, or without
+// SessionExpiry, scs decides from SessionManager.Lifetime.
+func (e *SessionExpiry) deadline(ctx context.Context, s *scs.SessionManager) time.Time {
+	if e != nil {
+		if deadline := s.GetTime(ctx, string(KeySessionDeadline)); !deadline.IsZero() {
+			return deadline
+		}
+	}
+
+	return s.Deadline(ctx)
+}
+
+// stamp gives session being committed a deadline if it does not have one
+// yet, which happens on login.
+func (e *SessionExpiry) stamp(ctx context.Context, s *scs.SessionManager) {
+	if e == nil || !s.GetTime(ctx, string(KeySessionDeadline)).IsZero() {
+		return
+	}
+
+	lifetime := e.lifetime
+	if s.GetBool(ctx, rememberMeKey) {
+		lifetime = e.rememberLifetime
+	}
+	if lifetime <= 0 {
+		return
+	}
+
+	s.Put(ctx, string(KeySessionDeadline), time.Now().Add(lifetime).UTC())
+}
+
+// expiry returns when session expires if it is not used again.
+func (e *SessionExpiry) expiry(ctx context.Context, s *scs.SessionManager) time.Time {
+	deadline := e.deadline(ctx, s)
+	if e == nil {
+		return deadline
+	}
+
+	idle := e.idle(ctx, s)
+	if idle <= 0 {
+		return deadline
+	}
+
+	if expiry := time.Now().Add(idle).UTC(); expiry.Before(deadline) {
+		return expiry
+	}
+	return deadline
+}
+
+func (e *SessionExpiry) idle(ctx context.Context, s *scs.SessionManager) time.Duration {
+	if s.GetBool(ctx, rememberMeKey) {
+		return e.rememberIdle
+	}
+	return e.idleTimeout
+}
+
+// SessionExpiresAt returns when session of current request expires if it is
+// not used again. It is only known for logged-in sessions.
+func SessionExpiresAt(ctx context.Context) (time.Time, bool) {
+	expiry, ok := ctx.Value(KeySessionExpiry).(time.Time)
+	return expiry, ok
+}
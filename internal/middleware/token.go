@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gmhafiz/go8/internal/utility/respond"
+)
+
+const (
+	KeyTokenSession key = "tokenSession"
+)
+
+var (
+	ErrInvalidAccessToken = errors.New("access token is invalid or has expired")
+)
+
+// AccessTokenVerifier checks an access token and returns who it was issued
+// to, and the refresh token family it belongs to.
+type AccessTokenVerifier interface {
+	VerifyAccessToken(token string) (userID uint64, sessionID string, err error)
+}
+
+// AccessToken authenticates requests carrying an `Authorization: Bearer <jwt>`
+// header. On success, user ID is saved under KeyID and the token's session
+// under KeyTokenSession. Other bearer values are left for APIKey.
+func AccessToken(verifier AccessTokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok || !isJWT(token) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, sessionID, err := verifier.VerifyAccessToken(token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				respond.Error(w, http.StatusUnauthorized, ErrInvalidAccessToken)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), KeyID, userID)
+			ctx = context.WithValue(ctx, KeyTokenSession, sessionID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IsAccessToken tells if current request is authenticated using an access
+// token rather than a session cookie.
+func IsAccessToken(ctx context.Context) bool {
+	_, ok := ctx.Value(KeyTokenSession).(string)
+	return ok
+}
+
+// isJWT tells access tokens apart from API keys, which never contain a dot.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
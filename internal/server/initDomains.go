@@ -1,9 +1,12 @@
 package server
 
 import (
+	"context"
 	"embed"
 	"io/fs"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -11,21 +14,25 @@ import (
 	authorHandler "github.com/gmhafiz/go8/internal/domain/author/handler"
 	authorRepo "github.com/gmhafiz/go8/internal/domain/author/repository"
 	authorUseCase "github.com/gmhafiz/go8/internal/domain/author/usecase"
+	"github.com/gmhafiz/go8/internal/domain/authorization"
 	bookHandler "github.com/gmhafiz/go8/internal/domain/book/handler"
 	bookRepo "github.com/gmhafiz/go8/internal/domain/book/repository"
 	bookUseCase "github.com/gmhafiz/go8/internal/domain/book/usecase"
 	"github.com/gmhafiz/go8/internal/domain/health"
 	"github.com/gmhafiz/go8/internal/middleware"
 	"github.com/gmhafiz/go8/internal/utility/respond"
+	"github.com/gmhafiz/go8/internal/utility/trash"
 )
 
 func (s *Server) InitDomains() {
 	s.initVersion()
 	s.initSwagger()
 	s.initAuthentication()
+	s.initAuthorization()
 	s.initAuthor()
 	s.initHealth()
 	s.initBook()
+	s.initTrash()
 }
 
 func (s *Server) initVersion() {
@@ -64,17 +71,62 @@ func (s *Server) initSwagger() {
 	}
 }
 
+// rebuildBatchSize is how many rows are read at a time when rebuilding the
+// embedded search indexes.
+const rebuildBatchSize = 500
+
 func (s *Server) initBook() {
-	newBookRepo := bookRepo.New(s.sqlx)
-	newBookUseCase := bookUseCase.New(newBookRepo)
+	newBookRepo := bookRepo.New(s.sqlx, bookRepo.WithSearchLanguage(s.cfg.Search.Language))
+
+	var (
+		bookRepository bookRepo.Book     = newBookRepo
+		bookSearcher   bookRepo.Searcher = newBookRepo
+	)
+	if s.es != nil {
+		bookRepository = bookRepo.NewElasticSync(newBookRepo, s.es)
+		bookSearcher = bookRepo.NewElasticSearch(s.es, newBookRepo)
+		// Books are also created along with their author.
+		s.ent.Book.Use(bookRepo.ElasticSyncHook(s.ent, s.es))
+	}
+	if s.bookIndex != nil {
+		bookRepository = bookRepo.NewEmbeddedSync(newBookRepo, s.bookIndex)
+		bookSearcher = bookRepo.NewEmbeddedSearch(s.bookIndex, s.sqlx, newBookRepo)
+		s.ent.Book.Use(bookRepo.EmbeddedSyncHook(s.ent, s.bookIndex))
+
+		// Catches up with writes made while the application was down. The
+		// saved index, if any, serves searches meanwhile.
+		go func() {
+			if err := bookRepo.RebuildEmbedded(context.Background(), s.sqlx, s.bookIndex, rebuildBatchSize); err != nil {
+				slog.Error("rebuilding book search index", "error", err)
+			}
+		}()
+	}
+
+	newBookUseCase := bookUseCase.New(bookRepository, bookSearcher)
 	bookHandler.RegisterHTTPEndPoints(s.router, s.validator, newBookUseCase)
+	s.trash["books"] = newBookUseCase.Purge
 }
 
 func (s *Server) initAuthor() {
 	newAuthorRepo := authorRepo.New(s.ent)
 	newLRUCache := authorRepo.NewLRUCache(newAuthorRepo)
 	newRedisCache := authorRepo.NewRedisCache(newAuthorRepo, s.cache)
-	newAuthorSearchRepo := authorRepo.NewSearch(s.ent)
+
+	var newAuthorSearchRepo authorRepo.Searcher = authorRepo.NewTrigramSearch(s.ent, s.cfg.Search.SimilarityThreshold)
+	if s.es != nil {
+		newAuthorSearchRepo = authorRepo.NewElasticSearch(s.es, newAuthorSearchRepo)
+		s.ent.Author.Use(authorRepo.ElasticSyncHook(s.ent, s.es))
+	}
+	if s.authorIndex != nil {
+		newAuthorSearchRepo = authorRepo.NewEmbeddedSearch(s.authorIndex, s.ent, newAuthorSearchRepo)
+		s.ent.Author.Use(authorRepo.EmbeddedSyncHook(s.ent, s.authorIndex))
+
+		go func() {
+			if err := authorRepo.RebuildEmbedded(context.Background(), s.ent, s.authorIndex, rebuildBatchSize); err != nil {
+				slog.Error("rebuilding author search index", "error", err)
+			}
+		}()
+	}
 
 	newAuthorUseCase := authorUseCase.New(
 		s.cfg.Cache,
@@ -84,9 +136,43 @@ func (s *Server) initAuthor() {
 		newRedisCache,
 	)
 	authorHandler.RegisterHTTPEndPoints(s.router, s.validator, newAuthorUseCase)
+	s.trash["authors"] = newAuthorUseCase.Purge
+}
+
+// initTrash starts emptying the trash of every domain registered above.
+func (s *Server) initTrash() {
+	s.trashPurger = trash.NewPurger(s.cfg.Trash, s.trash)
 }
 
 func (s *Server) initAuthentication() {
 	repo := authentication.NewRepo(s.ent, s.db, s.session)
-	authentication.RegisterHTTPEndPoints(s.router, s.session, repo)
+	authentication.RegisterHTTPEndPoints(s.router, s.cfg.Authentication, s.session, repo, s.mailer, s.lockoutStore())
+	s.accountPurger = authentication.NewAccountPurger(repo, s.cfg.Authentication.AccountPurgeInterval)
+
+	if s.tokens != nil {
+		authentication.RegisterTokenEndPoints(s.router, s.cfg.Authentication, s.cfg.Token, s.tokens, repo, s.lockoutStore())
+	}
+
+	if s.cfg.OIDC.Enable {
+		client := &http.Client{Timeout: 10 * time.Second}
+		authentication.RegisterOIDCEndPoints(s.router, s.cfg.OIDC, s.cfg.Authentication, s.session, repo, client)
+	}
+}
+
+// lockoutStore keeps failed logins in Redis when cache is enabled so that
+// every instance sees the same count, and in Postgres otherwise.
+func (s *Server) lockoutStore() authentication.LockoutStore {
+	switch {
+	case s.cluster != nil:
+		return authentication.NewRedisLockoutStore(s.cluster)
+	case s.cache != nil:
+		return authentication.NewRedisLockoutStore(s.cache)
+	default:
+		return authentication.NewPostgresLockoutStore(s.db)
+	}
+}
+
+func (s *Server) initAuthorization() {
+	repo := authorization.NewRepo(s.ent)
+	authorization.RegisterHTTPEndPoints(s.router, s.session, repo)
 }
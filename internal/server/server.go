@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -42,10 +43,22 @@ import (
 	"github.com/gmhafiz/go8/third_party/otlp"
 	//_ "github.com/gmhafiz/go8/docs"
 	"github.com/gmhafiz/go8/ent/gen"
+	"github.com/gmhafiz/go8/internal/domain/authentication"
+	authorRepo "github.com/gmhafiz/go8/internal/domain/author/repository"
+	"github.com/gmhafiz/go8/internal/domain/authorization"
+	bookRepo "github.com/gmhafiz/go8/internal/domain/book/repository"
 	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/internal/utility/filter"
+	"github.com/gmhafiz/go8/internal/utility/searchindex"
+	"github.com/gmhafiz/go8/internal/utility/token"
+	"github.com/gmhafiz/go8/internal/utility/trash"
 	db "github.com/gmhafiz/go8/third_party/database"
+	"github.com/gmhafiz/go8/third_party/elasticsearch"
+	"github.com/gmhafiz/go8/third_party/mailer"
 	"github.com/gmhafiz/go8/third_party/postgresstore"
 	redisLib "github.com/gmhafiz/go8/third_party/redis"
+	"github.com/gmhafiz/go8/third_party/redisstore"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
 	"github.com/gmhafiz/go8/third_party/validate"
 )
 
@@ -60,8 +73,20 @@ type Server struct {
 	cache   *redis.Client
 	cluster *redis.ClusterClient
 
+	es          *elasticsearch.Client
+	bookIndex   *searchindex.Index
+	authorIndex *searchindex.Index
+
 	session       *scs.SessionManager
+	sessions      sessionstore.Store
 	sessionCloser *postgresstore.PostgresStore
+	sessionExpiry *middleware.SessionExpiry
+	accountPurger *authentication.AccountPurger
+	trash         map[string]trash.PurgeFunc
+	trashPurger   *trash.Purger
+	tokens        *token.Issuer
+
+	mailer mailer.Mailer
 
 	otlp *middleware.Config
 
@@ -98,6 +123,7 @@ func defaultServer() *Server {
 	return &Server{
 		cfg:    config.New(),
 		router: chi.NewRouter(),
+		trash:  make(map[string]trash.PurgeFunc),
 	}
 }
 
@@ -107,8 +133,13 @@ func (s *Server) Init() {
 	s.newOpenTelemetry()
 	s.newRedis()
 	s.NewDatabase()
+	s.newElasticsearch()
+	s.newSearchIndex()
 	s.newValidator()
 	s.newAuthentication()
+	s.newTokenIssuer()
+	s.setCursorKey()
+	s.newMailer()
 	s.newRouter()
 	s.setGlobalMiddleware()
 	s.InitDomains()
@@ -176,6 +207,60 @@ func (s *Server) newRedis() {
 	}
 }
 
+// newElasticsearch connects to Elasticsearch when books and authors are
+// searched there, and creates their indices if missing. Being unable to reach
+// it is not fatal since searches fall back to PostgreSQL.
+func (s *Server) newElasticsearch() {
+	if s.cfg.Search.Backend != "elasticsearch" {
+		return
+	}
+
+	s.es = elasticsearch.New(s.cfg.Elasticsearch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indices := map[string][]byte{
+		authorRepo.AuthorIndex: authorRepo.AuthorMapping,
+		bookRepo.BookIndex:     bookRepo.BookMapping(s.cfg.Search.Language),
+	}
+	for index, mapping := range indices {
+		if err := s.es.CreateIndex(ctx, index, mapping); err != nil {
+			slog.Warn("creating elasticsearch index", "index", index, "error", err)
+		}
+	}
+}
+
+// newSearchIndex opens the embedded search indexes when books and authors
+// are searched there. They are rebuilt from the database once domains are
+// initialised.
+func (s *Server) newSearchIndex() {
+	if s.cfg.Search.Backend != "embedded" {
+		return
+	}
+
+	var err error
+	s.bookIndex, err = searchindex.Open(
+		filepath.Join(s.cfg.Search.Directory, "books.idx"),
+		s.cfg.Search.Language,
+		s.cfg.Search.SaveInterval,
+		bookRepo.BookFields...,
+	)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	s.authorIndex, err = searchindex.Open(
+		filepath.Join(s.cfg.Search.Directory, "authors.idx"),
+		s.cfg.Search.Language,
+		s.cfg.Search.SaveInterval,
+		authorRepo.AuthorFields...,
+	)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
 func (s *Server) NewDatabase() {
 	if s.cfg.Database.Driver == "" {
 		log.Fatal("please fill in database credentials in .env file or set in environment variable")
@@ -203,21 +288,93 @@ func (s *Server) newValidator() {
 }
 
 func (s *Server) newAuthentication() {
+	store := s.newSessionStore()
+
 	manager := scs.New()
-	manager.Store = postgresstore.New(s.sqlx.DB)
-	manager.CtxStore = postgresstore.New(s.sqlx.DB)
+	manager.Store = store
+	manager.CtxStore = store
 	manager.Lifetime = s.cfg.Session.Duration
 	manager.Cookie.Name = s.cfg.Session.Name
 	manager.Cookie.Domain = s.cfg.Session.Domain
 	manager.Cookie.HttpOnly = s.cfg.Session.HTTPOnly
 	manager.Cookie.Path = s.cfg.Session.Path
-	manager.Cookie.Persist = true
+	// Only sessions of users who asked to be remembered outlive the browser.
+	manager.Cookie.Persist = false
 	manager.Cookie.SameSite = http.SameSite(s.cfg.Session.SameSite)
 	manager.Cookie.Secure = s.cfg.Session.Secure
 
-	s.sessionCloser = postgresstore.NewWithCleanupInterval(s.sqlx.DB, 30*time.Minute)
-
 	s.session = manager
+	s.sessions = store
+	s.sessionExpiry = middleware.NewSessionExpiry(s.cfg.Session, store)
+}
+
+// newSessionStore keeps sessions in Redis when configured to, where they
+// expire on their own. Otherwise, they are kept in Postgres and expired ones
+// are cleaned up periodically.
+func (s *Server) newSessionStore() sessionstore.Store {
+	switch s.cfg.Session.Store {
+	case config.SessionStoreRedis:
+		switch {
+		case s.cluster != nil:
+			return redisstore.New(s.cluster)
+		case s.cache != nil:
+			return redisstore.New(s.cache)
+		default:
+			log.Fatalln("SESSION_STORE=redis requires REDIS_ENABLE=true")
+		}
+	case config.SessionStorePostgres:
+		s.sessionCloser = postgresstore.NewWithCleanupInterval(s.sqlx.DB, 30*time.Minute)
+		return s.sessionCloser
+	default:
+		log.Fatalf("SESSION_STORE must be either %q or %q\n", config.SessionStorePostgres, config.SessionStoreRedis)
+	}
+
+	return nil
+}
+
+// newTokenIssuer prepares signing of access tokens when token mode is on.
+// Without configured keys, a random one is used, which means tokens do not
+// survive a restart and are not accepted by other instances.
+func (s *Server) newTokenIssuer() {
+	if !s.cfg.Token.Enable {
+		return
+	}
+
+	keys, err := token.ParseKeys(s.cfg.Token.SigningKeys)
+	if err != nil {
+		log.Fatalf("invalid TOKEN_SIGNING_KEYS: %v\n", err)
+	}
+	if len(keys) == 0 {
+		slog.Warn("TOKEN_SIGNING_KEYS is not set, using a temporary signing key")
+		key, err := token.GenerateKey()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		keys = append(keys, key)
+	}
+
+	s.tokens, err = token.NewIssuer(s.cfg.Token.Issuer, s.cfg.Token.AccessExpiry, keys)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// setCursorKey sets the key pagination cursors are signed with.
+func (s *Server) setCursorKey() {
+	if s.cfg.Pagination.CursorKey == "" {
+		slog.Warn("PAGINATION_CURSOR_KEY is not set, using a temporary cursor key")
+		return
+	}
+
+	key, err := base64.StdEncoding.DecodeString(s.cfg.Pagination.CursorKey)
+	if err != nil || len(key) < 32 {
+		log.Fatalln("PAGINATION_CURSOR_KEY must be a base64 encoded key of at least 32 bytes")
+	}
+	filter.SetCursorKey(key)
+}
+
+func (s *Server) newMailer() {
+	s.mailer = mailer.New(s.cfg.Mail)
 }
 
 func (s *Server) newRouter() {
@@ -230,11 +387,29 @@ func (s *Server) setGlobalMiddleware() {
 		w.WriteHeader(http.StatusNotFound)
 		_, _ = w.Write([]byte(`{"message": "endpoint not found"}`))
 	})
+	realIP, err := middleware.RealIP(s.cfg.Proxy.Trusted)
+	if err != nil {
+		log.Fatalf("PROXY_TRUSTED: %v\n", err)
+	}
+	s.router.Use(realIP)
 	s.router.Use(s.cors.Handler)
 	s.router.Use(middleware.Otlp(s.cfg.OpenTelemetry.Enable))
 	s.router.Use(middleware.JSON)
-	s.router.Use(middleware.LoadAndSave(s.session))
+	s.router.Use(middleware.LoadAndSave(s.session, s.sessionExpiry))
+	if s.tokens != nil {
+		s.router.Use(middleware.AccessToken(s.tokens))
+	}
+	s.router.Use(middleware.APIKey(authentication.NewRepo(s.ent, s.db, s.session)))
+	if s.cfg.CSRF.Enable {
+		if s.cfg.CSRF.Mode != config.CSRFModeSession && s.cfg.CSRF.Mode != config.CSRFModeSingleUse {
+			log.Fatalf("CSRF_MODE must be either %q or %q\n", config.CSRFModeSession, config.CSRFModeSingleUse)
+		}
+		s.router.Use(middleware.CSRF(s.sessions, s.cfg.CSRF))
+	}
+	s.router.Use(middleware.Permissions(authorization.NewRepo(s.ent)))
+	s.router.Use(middleware.Accounts(authentication.NewRepo(s.ent, s.db, s.session)))
 	s.router.Use(middleware.Audit)
+	s.router.Use(middleware.RequireIfMatch(s.router, s.cfg.Precondition.Routes))
 	if s.cfg.API.RequestLog {
 		s.router.Use(chiMiddleware.Logger)
 	}
@@ -366,15 +541,21 @@ func (s *Server) newEnt(dsn string) {
 			}
 
 			val, err := next.Mutate(ctx, mutation)
+			if err != nil {
+				return val, err
+			}
 
 			meta.Table = mutation.Type()
 			meta.Action = middleware.Action(mutation.Op().String())
 
 			newValues, _ := json.Marshal(val)
 			meta.NewValues = string(newValues)
-			log.Println(meta)
 
-			return val, err
+			if err := middleware.SaveEvent(ctx, otelDB, meta); err != nil {
+				slog.ErrorContext(ctx, "saving audit event", "error", err)
+			}
+
+			return val, nil
 		})
 	})
 
@@ -483,10 +664,28 @@ func gracefulShutdown(ctx context.Context, s *Server) error {
 }
 
 func (s *Server) closeResources(ctx context.Context) {
+	// Pending renewals are written before the session store goes away.
+	s.sessionExpiry.Stop()
+
+	if err := s.bookIndex.Close(); err != nil {
+		slog.Error("saving book search index", "error", err)
+	}
+	if err := s.authorIndex.Close(); err != nil {
+		slog.Error("saving author search index", "error", err)
+	}
+
 	_ = s.sqlx.Close()
 	_ = s.ent.Close()
 	s.cluster.Shutdown(ctx)
 	s.cache.Shutdown(ctx)
-	s.sessionCloser.StopCleanup()
+	if s.sessionCloser != nil {
+		s.sessionCloser.StopCleanup()
+	}
+	if s.accountPurger != nil {
+		s.accountPurger.Stop()
+	}
+	if s.trashPurger != nil {
+		s.trashPurger.Stop()
+	}
 	defer s.otlp.Cancel()
 }
@@ -0,0 +1,65 @@
+// Package crypt encrypts small values, such as TOTP secrets, before they are
+// stored in the database.
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrKeyLength  = errors.New("encryption key must be 32 bytes, base64 encoded")
+	ErrCiphertext = errors.New("ciphertext is malformed")
+)
+
+// Cipher encrypts with AES-256-GCM. The random nonce is prepended to the
+// ciphertext.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New creates a Cipher from a base64 encoded 32-byte key. A key can be
+// generated with `openssl rand -base64 32`.
+func New(key string) (*Cipher, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, ErrKeyLength
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *Cipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, ErrCiphertext
+	}
+
+	return c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
@@ -0,0 +1,89 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func key(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "32 bytes", key: key(1)},
+		{name: "too short", key: base64.StdEncoding.EncodeToString(make([]byte, 16)), wantErr: true},
+		{name: "not base64", key: "not base64!", wantErr: true},
+		{name: "empty", key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.key)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestCipher(t *testing.T) {
+	c, err := New(key(1))
+	assert.Nil(t, err)
+	other, err := New(key(2))
+	assert.Nil(t, err)
+
+	plaintext := []byte("12345678901234567890")
+
+	tests := []struct {
+		name    string
+		cipher  *Cipher
+		tamper  func(ciphertext []byte) []byte
+		wantErr bool
+	}{
+		{name: "round trip", cipher: c, tamper: func(b []byte) []byte { return b }},
+		{name: "wrong key", cipher: other, tamper: func(b []byte) []byte { return b }, wantErr: true},
+		{name: "tampered ciphertext", cipher: c, tamper: func(b []byte) []byte {
+			b[len(b)-1] ^= 0x01
+			return b
+		}, wantErr: true},
+		{name: "tampered nonce", cipher: c, tamper: func(b []byte) []byte {
+			b[0] ^= 0x01
+			return b
+		}, wantErr: true},
+		{name: "truncated", cipher: c, tamper: func(b []byte) []byte { return b[:4] }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := c.Encrypt(plaintext)
+			assert.Nil(t, err)
+			assert.NotContains(t, string(ciphertext), string(plaintext))
+
+			got, err := tt.cipher.Decrypt(tt.tamper(ciphertext))
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, plaintext, got)
+		})
+	}
+}
+
+func TestCipher_Encrypt_Nonce(t *testing.T) {
+	c, err := New(key(1))
+	assert.Nil(t, err)
+
+	first, err := c.Encrypt([]byte("secret"))
+	assert.Nil(t, err)
+	second, err := c.Encrypt([]byte("secret"))
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, first, second)
+}
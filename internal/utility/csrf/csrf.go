@@ -1,70 +1,66 @@
 package csrf
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
-	"encoding/hex"
 	"errors"
 
-	"github.com/cespare/xxhash/v2"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
 )
 
-// ValidToken Checks if CSRF token is valid
-func ValidToken(ctx context.Context, db *sql.DB, token string) bool {
-	hash, err := sum(token)
-	if err != nil {
-		return false
-	}
+// Data is stored in place of session data so that a CSRF token can be told
+// apart from a session.
+var Data = []byte("csrf_token")
+
+var ErrTokenNotFound = errors.New("no csrf token was found")
 
-	var exists bool
-	row := db.QueryRowContext(ctx, `
-			SELECT EXISTS(
-				SELECT token FROM sessions 
-				WHERE token = $1 
-				  AND current_timestamp < expiry
-			) `, hash)
-	err = row.Scan(&exists)
+// ValidToken Checks if CSRF token is valid
+func ValidToken(ctx context.Context, store sessionstore.Store, token string) bool {
+	_, found, err := store.FindCtx(ctx, token)
 	if err != nil {
 		return false
 	}
-	return exists
+	return found
 }
 
 // ValidAndDeleteToken deletes the token from the store if and only if token is valid.
 // Useful for one-time token use.
-func ValidAndDeleteToken(ctx context.Context, db *sql.DB, token string) error {
-	hash, err := sum(token)
-	if err != nil {
-		return nil
-	}
-
-	res, err := db.ExecContext(ctx, `
-		DELETE FROM sessions WHERE token = $1 AND current_timestamp < expiry
-	`, hash)
+func ValidAndDeleteToken(ctx context.Context, store sessionstore.Store, token string) error {
+	_, _, found, err := store.TakeCtx(ctx, token)
 	if err != nil {
 		return err
 	}
-
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		return errors.New("token not found")
-	}
-
-	if rowsAffected != 1 {
-		return errors.New("no csrf token was found")
+	if !found {
+		return ErrTokenNotFound
 	}
 	return nil
 }
 
-func sum(token string) (string, error) {
-	h := xxhash.New()
-	_, err := h.Write([]byte(token))
+// ValidTokenForUser checks if CSRF token is valid and was issued to userID.
+// A token issued to another user is rejected, so that an attacker cannot use
+// a token obtained from their own account.
+func ValidTokenForUser(ctx context.Context, store sessionstore.Store, token string, userID uint64) bool {
+	b, owner, found, err := store.FindUserCtx(ctx, token)
 	if err != nil {
+		return false
+	}
+	return found && owner == userID && bytes.Equal(b, Data)
+}
 
-		return "", err
+// ValidAndDeleteTokenForUser is ValidTokenForUser for one-time token use.
+// Token is only taken once it is known to be valid, so that a session token
+// or another user's token cannot be deleted through here.
+func ValidAndDeleteTokenForUser(ctx context.Context, store sessionstore.Store, token string, userID uint64) error {
+	if !ValidTokenForUser(ctx, store, token, userID) {
+		return ErrTokenNotFound
 	}
-	sum := h.Sum(nil)
-	str := hex.EncodeToString(sum)
 
-	return str, err
+	b, owner, found, err := store.TakeCtx(ctx, token)
+	if err != nil {
+		return err
+	}
+	if !found || owner != userID || !bytes.Equal(b, Data) {
+		return ErrTokenNotFound
+	}
+	return nil
 }
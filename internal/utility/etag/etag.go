@@ -0,0 +1,95 @@
+// Package etag tags revisions of records so that clients can tell if the
+// one they hold is still current, and only change a record they have seen.
+package etag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Revision identifies a revision of a record. Two updates in the same
+// transaction leave the same updated_at, so the version bumped on every
+// update goes with it.
+type Revision struct {
+	UpdatedAt time.Time
+	Version   int64
+}
+
+// Tag returns rev as a strong entity tag, quotes included. updated_at is
+// kept to microseconds, as precise as Postgres stores it.
+func (rev Revision) Tag() string {
+	return fmt.Sprintf(`"%d-%x"`, rev.Version, rev.UpdatedAt.UnixMicro())
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying rev, the revision a record must still be
+// at for writes made with ctx to go through.
+func NewContext(ctx context.Context, rev Revision) context.Context {
+	return context.WithValue(ctx, contextKey{}, rev)
+}
+
+// FromContext returns the revision a record must still be at, if any.
+func FromContext(ctx context.Context) (Revision, bool) {
+	rev, ok := ctx.Value(contextKey{}).(Revision)
+	return rev, ok
+}
+
+// Set tells the client the tag of rev.
+func Set(w http.ResponseWriter, rev Revision) {
+	w.Header().Set("ETag", rev.Tag())
+}
+
+// NotModified sets the tag of rev on w, and reports if the If-None-Match
+// header of r names it already, in which case the client's copy is current
+// and the response is 304 Not Modified without a body.
+func NotModified(w http.ResponseWriter, r *http.Request, rev Revision) bool {
+	Set(w, rev)
+
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	return matches(header, rev.Tag(), true)
+}
+
+// IfMatch checks the If-Match header of r against rev, the revision of the
+// record as it is now, and reports if the write may go ahead. Unless the
+// header is missing, the returned context carries rev so that the write is
+// only made if the record is still at rev by then.
+func IfMatch(ctx context.Context, r *http.Request, rev Revision) (context.Context, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return ctx, true
+	}
+	if !matches(header, rev.Tag(), false) {
+		return ctx, false
+	}
+	return NewContext(ctx, rev), true
+}
+
+// matches reports if the list of entity tags in header, or "*", includes
+// tag. Weak tags are only equal to tag when comparing weakly, as
+// If-None-Match does, and never in If-Match.
+func matches(header, tag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+
+	return false
+}
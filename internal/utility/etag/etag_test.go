@@ -0,0 +1,86 @@
+package etag
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevision_Tag(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 18, 9, 0, 0, 123456789, time.UTC)
+	rev := Revision{UpdatedAt: updatedAt, Version: 3}
+
+	assert.Equal(t, `"3-`, rev.Tag()[:3])
+	// Postgres keeps microseconds only, so nanoseconds must not change the tag.
+	assert.Equal(t, rev.Tag(), Revision{UpdatedAt: updatedAt.Truncate(time.Microsecond), Version: 3}.Tag())
+	assert.NotEqual(t, rev.Tag(), Revision{UpdatedAt: updatedAt, Version: 4}.Tag())
+	assert.NotEqual(t, rev.Tag(), Revision{UpdatedAt: updatedAt.Add(time.Microsecond), Version: 3}.Tag())
+}
+
+func TestIfMatch(t *testing.T) {
+	rev := Revision{UpdatedAt: time.Now(), Version: 2}
+
+	tests := []struct {
+		name    string
+		header  string
+		ok      bool
+		guarded bool
+	}{
+		{name: "no header", header: "", ok: true},
+		{name: "same", header: rev.Tag(), ok: true, guarded: true},
+		{name: "one of many", header: `"1-a", ` + rev.Tag(), ok: true, guarded: true},
+		{name: "any", header: "*", ok: true, guarded: true},
+		{name: "stale", header: `"1-a"`, ok: false},
+		{name: "weak never matches", header: "W/" + rev.Tag(), ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			ctx, ok := IfMatch(context.Background(), r, rev)
+			assert.Equal(t, tt.ok, ok)
+
+			got, guarded := FromContext(ctx)
+			assert.Equal(t, tt.guarded, guarded)
+			if guarded {
+				assert.Equal(t, rev, got)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	rev := Revision{UpdatedAt: time.Now(), Version: 2}
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "no header", header: "", want: false},
+		{name: "same", header: rev.Tag(), want: true},
+		{name: "weak", header: "W/" + rev.Tag(), want: true},
+		{name: "stale", header: `"1-a"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-None-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			assert.Equal(t, tt.want, NotModified(w, r, rev))
+			assert.Equal(t, rev.Tag(), w.Header().Get("ETag"))
+		})
+	}
+}
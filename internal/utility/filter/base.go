@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -16,6 +17,8 @@ const (
 	queryParamOffset        = "offset"
 	queryParamDisablePaging = "disable_paging"
 	queryParamSort          = "sort"
+	queryParamAfter         = "after"
+	queryParamBefore        = "before"
 )
 
 type Filter struct {
@@ -24,8 +27,18 @@ type Filter struct {
 	Limit         int
 	DisablePaging bool
 
-	Sort   map[string]string
+	Sort map[string]string
+	// SortBy holds the keys of Sort in the order they were given, which
+	// matters when sorting by several of them.
+	SortBy []string
 	Search bool
+
+	// After and Before page by cursor rather than offset: rows sorted after
+	// or before the row they mark. At most one of them is set.
+	After  *Cursor
+	Before *Cursor
+
+	err error
 }
 
 func New(queries url.Values) *Filter {
@@ -50,10 +63,14 @@ func New(queries url.Values) *Filter {
 	disablePaging, _ := strconv.ParseBool(queries.Get(queryParamDisablePaging))
 
 	sortKey := make(map[string]string)
+	var sortBy []string
 	if queries.Has(queryParamSort) {
 		s := queries[queryParamSort]
 		for _, val := range s {
 			key, order, found := strings.Cut(val, ",")
+			if _, seen := sortKey[key]; !seen {
+				sortBy = append(sortBy, key)
+			}
 			if found {
 				sortKey[key] = strings.ToUpper(order)
 			} else {
@@ -62,11 +79,47 @@ func New(queries url.Values) *Filter {
 		}
 	}
 
-	return &Filter{
+	f := &Filter{
 		Page:          page,
 		Offset:        offset,
 		Limit:         limit,
 		DisablePaging: disablePaging,
 		Sort:          sortKey,
+		SortBy:        sortBy,
+	}
+	f.setCursor(queries)
+
+	return f
+}
+
+func (f *Filter) setCursor(queries url.Values) {
+	param, token := queryParamAfter, queries.Get(queryParamAfter)
+	if token == "" {
+		param, token = queryParamBefore, queries.Get(queryParamBefore)
+	}
+	if token == "" {
+		return
+	}
+	if queries.Get(queryParamAfter) != "" && queries.Get(queryParamBefore) != "" {
+		f.err = fmt.Errorf("%w: only one of %s and %s can be given", ErrInvalidCursor, queryParamAfter, queryParamBefore)
+		return
+	}
+
+	cursor, err := DecodeCursor(token)
+	if err != nil {
+		f.err = err
+		return
+	}
+	if cursor.Sort != f.sortSpec() {
+		f.err = fmt.Errorf("%w: it was made for another sort", ErrInvalidCursor)
+		return
+	}
+
+	// Cursors replace offsets.
+	f.Offset = 0
+	if param == queryParamAfter {
+		f.After = cursor
+	} else {
+		f.Before = cursor
 	}
 }
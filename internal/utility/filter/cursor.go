@@ -0,0 +1,178 @@
+package filter
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidCursor is returned for a cursor that was tampered with, signed
+// with another key or made for another sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrSearchCursor is returned for a cursor given to a search. Search results
+// are ranked, so they can only be paged by offset.
+var ErrSearchCursor = fmt.Errorf("%w: search results can only be paged by page or offset", ErrInvalidCursor)
+
+var (
+	cursorKeyMu sync.RWMutex
+	cursorKey   = randomKey()
+)
+
+func randomKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// SetCursorKey sets the key cursors are signed with. Until it is called, a
+// random key is used, so cursors are only valid until the application
+// restarts and only on the instance that made them.
+func SetCursorKey(key []byte) {
+	cursorKeyMu.Lock()
+	defer cursorKeyMu.Unlock()
+	cursorKey = key
+}
+
+// Order is a key rows are sorted by.
+type Order struct {
+	Key  string
+	Desc bool
+}
+
+// Cursor marks the row a page starts after, or ends before. It holds the
+// values of that row for each key rows are sorted by, and its ID to tell
+// apart rows with the same values.
+type Cursor struct {
+	Values []string `json:"v"`
+	ID     uint64   `json:"id"`
+	// Sort is the sort of the request the cursor was made for.
+	Sort string `json:"s"`
+}
+
+// Encode returns c as an opaque token, signed so that it cannot be altered.
+func (c Cursor) Encode() string {
+	payload, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sign(payload))
+}
+
+// DecodeCursor reads a token made by Encode.
+func DecodeCursor(token string) (*Cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func sign(payload []byte) []byte {
+	cursorKeyMu.RLock()
+	defer cursorKeyMu.RUnlock()
+
+	mac := hmac.New(sha256.New, cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Orders returns the keys of Sort found in allowed, in the order they were
+// given, or fallback when there are none. Rows are then sorted by ID.
+func (f *Filter) Orders(allowed []string, fallback ...Order) []Order {
+	var orders []Order
+	for _, key := range f.SortBy {
+		for _, a := range allowed {
+			if key == a {
+				orders = append(orders, Order{Key: key, Desc: f.Sort[key] == "DESC"})
+				break
+			}
+		}
+	}
+	if len(orders) == 0 {
+		return fallback
+	}
+	return orders
+}
+
+// sortSpec describes the sort of the request, so that a cursor is only used
+// with the sort it was made for.
+func (f *Filter) sortSpec() string {
+	specs := make([]string, 0, len(f.SortBy))
+	for _, key := range f.SortBy {
+		specs = append(specs, key+","+f.Sort[key])
+	}
+	return strings.Join(specs, ";")
+}
+
+// Cursors returns the cursors of the pages after and before a page of n
+// rows, or empty ones when there is no such page. row returns the sort
+// values and ID of the i-th row of the page. A full page is assumed to be
+// followed by another, so the last page may be empty.
+func (f *Filter) Cursors(n int, row func(i int) Cursor) (next, prev string) {
+	if f.DisablePaging || n == 0 {
+		return "", ""
+	}
+
+	cursor := func(i int) string {
+		return f.cursor(row(i))
+	}
+
+	full := n >= f.Limit
+	switch {
+	case f.Before != nil:
+		// The row the page ends before comes next.
+		next = cursor(n - 1)
+		if full {
+			prev = cursor(0)
+		}
+	case f.After != nil:
+		if full {
+			next = cursor(n - 1)
+		}
+		prev = cursor(0)
+	default:
+		if full {
+			next = cursor(n - 1)
+		}
+		if f.Offset > 0 {
+			prev = cursor(0)
+		}
+	}
+
+	return next, prev
+}
+
+// HasCursor tells if the request pages by cursor rather than offset.
+func (f *Filter) HasCursor() bool {
+	return f.After != nil || f.Before != nil
+}
+
+// cursor returns c as a token for the sort of f.
+func (f *Filter) cursor(c Cursor) string {
+	c.Sort = f.sortSpec()
+	return c.Encode()
+}
+
+// Err tells why paging as requested is not possible.
+func (f *Filter) Err() error {
+	return f.err
+}
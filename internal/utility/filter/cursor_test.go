@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	sorted := url.Values{"sort": {"title,desc", "created_at"}}
+	token := (&Filter{Sort: map[string]string{"title": "DESC", "created_at": "ASC"}, SortBy: []string{"title", "created_at"}}).
+		cursor(Cursor{Values: []string{"Go", "2020-01-01T00:00:00Z"}, ID: 7})
+
+	tampered := []byte(token)
+	tampered[3] ^= 1
+
+	tests := []struct {
+		name    string
+		queries url.Values
+		after   *Cursor
+		before  *Cursor
+		err     error
+	}{
+		{
+			name:    "after",
+			queries: with(sorted, "after", token),
+			after:   &Cursor{Values: []string{"Go", "2020-01-01T00:00:00Z"}, ID: 7, Sort: "title,DESC;created_at,ASC"},
+		},
+		{
+			name:    "before",
+			queries: with(sorted, "before", token),
+			before:  &Cursor{Values: []string{"Go", "2020-01-01T00:00:00Z"}, ID: 7, Sort: "title,DESC;created_at,ASC"},
+		},
+		{
+			name:    "tampered",
+			queries: with(sorted, "after", string(tampered)),
+			err:     ErrInvalidCursor,
+		},
+		{
+			name:    "garbage",
+			queries: with(sorted, "after", "not a cursor"),
+			err:     ErrInvalidCursor,
+		},
+		{
+			name:    "another sort",
+			queries: url.Values{"sort": {"title,asc"}, "after": {token}},
+			err:     ErrInvalidCursor,
+		},
+		{
+			name:    "both directions",
+			queries: with(with(sorted, "after", token), "before", token),
+			err:     ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(tt.queries)
+			assert.True(t, errors.Is(f.Err(), tt.err), f.Err())
+			assert.Equal(t, tt.after, f.After)
+			assert.Equal(t, tt.before, f.Before)
+		})
+	}
+
+	t.Run("other key", func(t *testing.T) {
+		SetCursorKey([]byte("another key of at least 32 bytes!"))
+		t.Cleanup(func() { SetCursorKey(randomKey()) })
+
+		f := New(with(sorted, "after", token))
+		assert.ErrorIs(t, f.Err(), ErrInvalidCursor)
+	})
+}
+
+func TestFilter_Orders(t *testing.T) {
+	f := New(url.Values{"sort": {"updated_at,desc", "password", "title"}})
+
+	assert.Equal(t, []Order{{Key: "updated_at", Desc: true}, {Key: "title"}}, f.Orders([]string{"title", "updated_at"}))
+	assert.Equal(t, []Order{{Key: "id"}}, New(nil).Orders([]string{"title"}, Order{Key: "id"}))
+	assert.Empty(t, New(nil).Orders([]string{"title"}))
+}
+
+func TestFilter_Cursors(t *testing.T) {
+	row := func(i int) Cursor { return Cursor{ID: uint64(i + 1)} }
+	decode := func(token string) uint64 {
+		if token == "" {
+			return 0
+		}
+		c, err := DecodeCursor(token)
+		assert.Nil(t, err)
+		return c.ID
+	}
+
+	tests := []struct {
+		name     string
+		f        *Filter
+		n        int
+		wantNext uint64
+		wantPrev uint64
+	}{
+		{name: "first page", f: &Filter{Limit: 2}, n: 2, wantNext: 2},
+		{name: "last page by offset", f: &Filter{Limit: 2, Offset: 2}, n: 1, wantPrev: 1},
+		{name: "after, full", f: &Filter{Limit: 2, After: &Cursor{}}, n: 2, wantNext: 2, wantPrev: 1},
+		{name: "after, last", f: &Filter{Limit: 2, After: &Cursor{}}, n: 1, wantPrev: 1},
+		{name: "before, full", f: &Filter{Limit: 2, Before: &Cursor{}}, n: 2, wantNext: 2, wantPrev: 1},
+		{name: "before, first", f: &Filter{Limit: 2, Before: &Cursor{}}, n: 1, wantNext: 1},
+		{name: "empty", f: &Filter{Limit: 2, After: &Cursor{}}, n: 0},
+		{name: "paging disabled", f: &Filter{Limit: 2, DisablePaging: true}, n: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, prev := tt.f.Cursors(tt.n, row)
+			assert.Equal(t, tt.wantNext, decode(next))
+			assert.Equal(t, tt.wantPrev, decode(prev))
+		})
+	}
+}
+
+func with(queries url.Values, key, value string) url.Values {
+	q := url.Values{}
+	for k, v := range queries {
+		q[k] = v
+	}
+	q.Set(key, value)
+	return q
+}
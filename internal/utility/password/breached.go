@@ -0,0 +1,124 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxLineLength is longer than any line in a Have I Been Pwned file, which
+// is a 40 character hash followed by a count.
+const maxLineLength = 256
+
+// breachedFile looks up passwords in a local copy of Have I Been Pwned SHA-1
+// hashes, downloaded with the official downloader and ordered by hash:
+//
+//	000000005AD76BD555C1D6D771DE417A4B87E4B4:10
+//	00000000A8DAE4228F821FB418F59826079BF368:4
+//
+// File is binary searched in place, so it is never loaded into memory and
+// no request leaves the server. Count after the colon is optional.
+type breachedFile struct {
+	f    *os.File
+	size int64
+}
+
+func openBreachedFile(path string) (*breachedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &breachedFile{f: f, size: info.Size()}, nil
+}
+
+// contains reports whether SHA-1 hash of plain is in the file. ReadAt is safe
+// to call concurrently, so no lock is needed.
+func (b *breachedFile) contains(plain string) (bool, error) {
+	sum := sha1.Sum([]byte(plain))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// lo is always the start of a line, and every line before it sorts
+	// before hash.
+	lo, hi := int64(0), b.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, err := b.lineStart(mid)
+		if err != nil {
+			return false, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		line, err := b.line(start)
+		if err != nil {
+			return false, err
+		}
+
+		lineHash, _, _ := strings.Cut(strings.TrimSpace(string(line)), ":")
+		switch strings.Compare(strings.ToUpper(lineHash), hash) {
+		case 0:
+			return true, nil
+		case -1:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return false, nil
+}
+
+// lineStart returns the offset of the first line starting at or after off.
+func (b *breachedFile) lineStart(off int64) (int64, error) {
+	if off == 0 {
+		return 0, nil
+	}
+
+	buf := make([]byte, maxLineLength)
+	n, err := b.f.ReadAt(buf, off-1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+
+	i := bytes.IndexByte(buf[:n], '\n')
+	if i < 0 {
+		if errors.Is(err, io.EOF) {
+			return b.size, nil
+		}
+		return 0, errors.New("breached password file has a line that is too long")
+	}
+
+	return off + int64(i), nil
+}
+
+// line returns the line starting at off without its line break.
+func (b *breachedFile) line(off int64) ([]byte, error) {
+	buf := make([]byte, maxLineLength)
+	n, err := b.f.ReadAt(buf, off)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+		return buf[:i], nil
+	}
+	if errors.Is(err, io.EOF) {
+		return buf[:n], nil
+	}
+
+	return nil, errors.New("breached password file has a line that is too long")
+}
@@ -0,0 +1,55 @@
+// Package password hashes passwords with argon2id using parameters from
+// config, and tells when an existing hash should be upgraded.
+package password
+
+import (
+	"github.com/alexedwards/argon2id"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+const (
+	saltLength = 16
+	keyLength  = 32
+)
+
+// Params returns argon2id parameters set in config. Parameters left at zero
+// fall back to argon2id library defaults.
+func Params(cfg config.Authentication) *argon2id.Params {
+	params := *argon2id.DefaultParams
+	params.SaltLength = saltLength
+	params.KeyLength = keyLength
+
+	if cfg.PasswordMemory != 0 {
+		params.Memory = cfg.PasswordMemory
+	}
+	if cfg.PasswordIterations != 0 {
+		params.Iterations = cfg.PasswordIterations
+	}
+	if cfg.PasswordParallelism != 0 {
+		params.Parallelism = cfg.PasswordParallelism
+	}
+
+	return &params
+}
+
+// Hash creates an encoded argon2id hash of password.
+func Hash(password string, params *argon2id.Params) (string, error) {
+	return argon2id.CreateHash(password, params)
+}
+
+// NeedsRehash reports whether hash was created with a lower cost than params.
+// Parallelism is not compared because it changes how the work is spread, not
+// how much work an attacker has to do. A hash that cannot be decoded is left
+// alone.
+func NeedsRehash(hash string, params *argon2id.Params) bool {
+	current, _, _, err := argon2id.DecodeHash(hash)
+	if err != nil {
+		return false
+	}
+
+	return current.Memory < params.Memory ||
+		current.Iterations < params.Iterations ||
+		current.SaltLength < params.SaltLength ||
+		current.KeyLength < params.KeyLength
+}
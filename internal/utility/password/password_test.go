@@ -0,0 +1,60 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsRehash(t *testing.T) {
+	weak := &argon2id.Params{Memory: 16 * 1024, Iterations: 1, Parallelism: 1, SaltLength: saltLength, KeyLength: keyLength}
+	strong := &argon2id.Params{Memory: 64 * 1024, Iterations: 2, Parallelism: 1, SaltLength: saltLength, KeyLength: keyLength}
+
+	weakHash, err := Hash("highEntropyPassword", weak)
+	assert.Nil(t, err)
+	strongHash, err := Hash("highEntropyPassword", strong)
+	assert.Nil(t, err)
+
+	type args struct {
+		hash   string
+		params *argon2id.Params
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "lower memory and iterations",
+			args: args{hash: weakHash, params: strong},
+			want: true,
+		},
+		{
+			name: "same parameters",
+			args: args{hash: strongHash, params: strong},
+			want: false,
+		},
+		{
+			name: "stronger than config",
+			args: args{hash: strongHash, params: weak},
+			want: false,
+		},
+		{
+			name: "only parallelism differs",
+			args: args{hash: strongHash, params: &argon2id.Params{Memory: 64 * 1024, Iterations: 2, Parallelism: 4, SaltLength: saltLength, KeyLength: keyLength}},
+			want: false,
+		},
+		{
+			name: "not an argon2id hash",
+			args: args{hash: "", params: strong},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NeedsRehash(tt.args.hash, tt.args.params))
+		})
+	}
+}
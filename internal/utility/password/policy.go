@@ -0,0 +1,197 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+const (
+	defaultMinLength = 13
+	defaultMaxLength = 128
+
+	// minPersonalLength keeps short names such as "Al" from rejecting most
+	// passwords.
+	minPersonalLength = 3
+)
+
+// Violation codes tell clients which rule a password broke.
+const (
+	CodeTooShort      = "too_short"
+	CodeTooLong       = "too_long"
+	CodeCommon        = "common"
+	CodeContainsEmail = "contains_email"
+	CodeContainsName  = "contains_name"
+	CodeBreached      = "breached"
+)
+
+//go:embed common.txt
+var common string
+
+// Violation is a rule a password does not follow.
+type Violation struct {
+	Code    string
+	Message string
+}
+
+// Owner is who a password is for, so that it can be checked for their
+// personal details.
+type Owner struct {
+	Email      string
+	FirstName  string
+	MiddleName string
+	LastName   string
+}
+
+// Policy decides whether a new password is acceptable.
+type Policy struct {
+	minLength      int
+	maxLength      int
+	banned         map[string]struct{}
+	rejectPersonal bool
+	breached       *breachedFile
+}
+
+// NewPolicy builds a password policy from config. Lengths left at zero fall
+// back to defaults. Banned passwords from PasswordBannedFile are added to a
+// built-in list of common passwords.
+func NewPolicy(cfg config.Authentication) (*Policy, error) {
+	p := &Policy{
+		minLength:      cfg.PasswordMinLength,
+		maxLength:      cfg.PasswordMaxLength,
+		banned:         make(map[string]struct{}),
+		rejectPersonal: cfg.PasswordRejectPersonal,
+	}
+	if p.minLength == 0 {
+		p.minLength = defaultMinLength
+	}
+	if p.maxLength == 0 {
+		p.maxLength = defaultMaxLength
+	}
+	if p.maxLength < p.minLength {
+		return nil, fmt.Errorf("maximum password length %d is less than minimum %d", p.maxLength, p.minLength)
+	}
+
+	if err := p.ban(strings.NewReader(common)); err != nil {
+		return nil, err
+	}
+
+	if cfg.PasswordBannedFile != "" {
+		f, err := os.Open(cfg.PasswordBannedFile)
+		if err != nil {
+			return nil, fmt.Errorf("opening banned passwords: %w", err)
+		}
+		defer f.Close()
+
+		if err = p.ban(f); err != nil {
+			return nil, fmt.Errorf("reading banned passwords: %w", err)
+		}
+	}
+
+	if cfg.PasswordBreachedFile != "" {
+		breached, err := openBreachedFile(cfg.PasswordBreachedFile)
+		if err != nil {
+			return nil, fmt.Errorf("opening breached passwords: %w", err)
+		}
+		p.breached = breached
+	}
+
+	return p, nil
+}
+
+// ban reads one password per line. Blank lines and lines starting with `#`
+// are skipped.
+func (p *Policy) ban(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.banned[strings.ToLower(line)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// Check returns every rule plain breaks, or none if it is acceptable. An
+// error means the breached password file could not be read.
+func (p *Policy) Check(plain string, owner Owner) ([]Violation, error) {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(plain)
+	if length < p.minLength {
+		violations = append(violations, Violation{
+			Code:    CodeTooShort,
+			Message: fmt.Sprintf("must be at least %d characters", p.minLength),
+		})
+	}
+	if length > p.maxLength {
+		violations = append(violations, Violation{
+			Code:    CodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", p.maxLength),
+		})
+	}
+
+	lower := strings.ToLower(plain)
+
+	if _, ok := p.banned[lower]; ok {
+		violations = append(violations, Violation{
+			Code:    CodeCommon,
+			Message: "is too common",
+		})
+	}
+
+	if p.rejectPersonal {
+		if containsEmail(lower, owner.Email) {
+			violations = append(violations, Violation{
+				Code:    CodeContainsEmail,
+				Message: "must not contain your email address",
+			})
+		}
+		if containsAny(lower, owner.FirstName, owner.MiddleName, owner.LastName) {
+			violations = append(violations, Violation{
+				Code:    CodeContainsName,
+				Message: "must not contain your name",
+			})
+		}
+	}
+
+	if p.breached != nil {
+		found, err := p.breached.contains(plain)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			violations = append(violations, Violation{
+				Code:    CodeBreached,
+				Message: "has appeared in a data breach",
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// containsEmail checks both the whole address and the part before `@`.
+func containsEmail(lower, email string) bool {
+	local, _, _ := strings.Cut(email, "@")
+	return containsAny(lower, email, local)
+}
+
+func containsAny(lower string, values ...string) bool {
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if utf8.RuneCountInString(v) >= minPersonalLength && strings.Contains(lower, v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,181 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	dir := t.TempDir()
+
+	banned := filepath.Join(dir, "banned.txt")
+	err := os.WriteFile(banned, []byte("# company name\nAcmeCorporation2024\n"), 0o600)
+	assert.Nil(t, err)
+
+	breached := filepath.Join(dir, "breached.txt")
+	err = os.WriteFile(breached, []byte(breachedFileOf("leakedButLongEnough", "anotherLeakedPassword", "thirdLeakedPassword")), 0o600)
+	assert.Nil(t, err)
+
+	policy, err := NewPolicy(config.Authentication{
+		PasswordMinLength:      13,
+		PasswordMaxLength:      32,
+		PasswordBannedFile:     banned,
+		PasswordRejectPersonal: true,
+		PasswordBreachedFile:   breached,
+	})
+	assert.Nil(t, err)
+
+	owner := Owner{Email: "jane.doe@example.com", FirstName: "Jane", LastName: "Al"}
+
+	type args struct {
+		password string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "acceptable",
+			args: args{password: "highEntropyPassword"},
+			want: nil,
+		},
+		{
+			name: "too short",
+			args: args{password: "short"},
+			want: []string{CodeTooShort},
+		},
+		{
+			name: "length is counted in characters",
+			args: args{password: "ßßßßßßßßßßßßß"},
+			want: nil,
+		},
+		{
+			name: "too long",
+			args: args{password: strings.Repeat("a", 33)},
+			want: []string{CodeTooLong},
+		},
+		{
+			name: "built-in common password in any case",
+			args: args{password: "PasswordPassword"},
+			want: []string{CodeCommon},
+		},
+		{
+			name: "banned from file",
+			args: args{password: "acmecorporation2024"},
+			want: []string{CodeCommon},
+		},
+		{
+			name: "contains email",
+			args: args{password: "myJane.Doe1234567"},
+			want: []string{CodeContainsEmail, CodeContainsName},
+		},
+		{
+			name: "contains name",
+			args: args{password: "iAmJaneAndThatIsIt"},
+			want: []string{CodeContainsName},
+		},
+		{
+			name: "short names are ignored",
+			args: args{password: "alwaysHighEntropy"},
+			want: nil,
+		},
+		{
+			name: "breached",
+			args: args{password: "anotherLeakedPassword"},
+			want: []string{CodeBreached},
+		},
+		{
+			name: "first breached entry",
+			args: args{password: "leakedButLongEnough"},
+			want: []string{CodeBreached},
+		},
+		{
+			name: "last breached entry",
+			args: args{password: "thirdLeakedPassword"},
+			want: []string{CodeBreached},
+		},
+		{
+			name: "several rules",
+			args: args{password: "jane"},
+			want: []string{CodeTooShort, CodeContainsName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := policy.Check(tt.args.password, owner)
+			assert.Nil(t, err)
+
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Code)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPolicy_Defaults(t *testing.T) {
+	policy, err := NewPolicy(config.Authentication{})
+	assert.Nil(t, err)
+
+	violations, err := policy.Check("", Owner{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Violation{{Code: CodeTooShort, Message: "must be at least 13 characters"}}, violations)
+
+	_, err = NewPolicy(config.Authentication{PasswordMinLength: 20, PasswordMaxLength: 10})
+	assert.NotNil(t, err)
+}
+
+func TestBreachedFile_Contains(t *testing.T) {
+	var passwords []string
+	for i := range 500 {
+		passwords = append(passwords, strings.Repeat("x", i%7)+string(rune('a'+i%26))+strings.Repeat("y", i))
+	}
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	err := os.WriteFile(path, []byte(breachedFileOf(passwords...)), 0o600)
+	assert.Nil(t, err)
+
+	f, err := openBreachedFile(path)
+	assert.Nil(t, err)
+
+	for _, p := range passwords {
+		found, err := f.contains(p)
+		assert.Nil(t, err)
+		assert.True(t, found, p)
+	}
+
+	for _, p := range []string{"", "notInTheFile", "zzzzzzzzzzzzzzzz"} {
+		if slices.Contains(passwords, p) {
+			continue
+		}
+		found, err := f.contains(p)
+		assert.Nil(t, err)
+		assert.False(t, found, p)
+	}
+}
+
+// breachedFileOf returns a Have I Been Pwned style file of passwords, ordered
+// by hash, with Windows line endings and no trailing line break.
+func breachedFileOf(passwords ...string) string {
+	var lines []string
+	for i, p := range passwords {
+		sum := sha1.Sum([]byte(p))
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":"+strings.Repeat("9", i%5+1))
+	}
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\r\n")
+}
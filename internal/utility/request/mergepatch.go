@@ -0,0 +1,95 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// MergePatchContentType is the media type of JSON merge patches, RFC 7396.
+const MergePatchContentType = "application/merge-patch+json"
+
+var ErrUnsupportedPatch = errors.New("patch must be a JSON merge patch sent as " + MergePatchContentType)
+
+// DecodeMergePatch applies the JSON merge patch in r's body to original and
+// decodes the result into dst. Members left out of the patch keep their
+// value from original, and members set to null are removed so that their
+// field in dst is left at its zero value. Patches sent as application/json
+// are accepted too, as many clients send nothing else.
+func DecodeMergePatch(w http.ResponseWriter, r *http.Request, original, dst any) error {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != MergePatchContentType && mediaType != "application/json") {
+			return ErrUnsupportedPatch
+		}
+	}
+
+	var patch json.RawMessage
+	if err := DecodeJSON(w, r, &patch); err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+
+	merged, err := MergePatch(doc, patch)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+
+	return nil
+}
+
+// MergePatch applies the JSON merge patch to the JSON document doc as
+// described in RFC 7396.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, changes any
+	if err := unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("reading document: %w", err)
+	}
+	if err := unmarshal(patch, &changes); err != nil {
+		return nil, fmt.Errorf("reading patch: %w", err)
+	}
+
+	return json.Marshal(mergePatch(target, changes))
+}
+
+func mergePatch(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = make(map[string]any, len(members))
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = mergePatch(result[name], value)
+	}
+
+	return result
+}
+
+// unmarshal keeps numbers as they are written so that merging does not
+// round large ones through float64.
+func unmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
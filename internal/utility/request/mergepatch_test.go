@@ -0,0 +1,107 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7396 Appendix A.
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"n":12345678901234567890}`, `{}`, `{"n":12345678901234567890}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.doc+" "+test.patch, func(t *testing.T) {
+			got, err := MergePatch([]byte(test.doc), []byte(test.patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.want, string(got))
+		})
+	}
+}
+
+func TestDecodeMergePatch(t *testing.T) {
+	type doc struct {
+		Title string  `json:"title"`
+		Image *string `json:"image"`
+	}
+	image := "https://example.com/a.png"
+
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		want        doc
+		wantErr     bool
+	}{
+		{
+			name:        "keeps members left out",
+			contentType: MergePatchContentType,
+			patch:       `{"title":"new"}`,
+			want:        doc{Title: "new", Image: &image},
+		},
+		{
+			name:        "null clears",
+			contentType: "application/json; charset=utf-8",
+			patch:       `{"image":null}`,
+			want:        doc{Title: "old"},
+		},
+		{
+			name:        "unknown member",
+			contentType: MergePatchContentType,
+			patch:       `{"author":"someone"}`,
+			wantErr:     true,
+		},
+		{
+			name:        "wrong type",
+			contentType: MergePatchContentType,
+			patch:       `{"title":1}`,
+			wantErr:     true,
+		},
+		{
+			name:        "other media type",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op":"remove","path":"/image"}]`,
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(test.patch))
+			r.Header.Set("Content-Type", test.contentType)
+
+			var got doc
+			err := DecodeMergePatch(httptest.NewRecorder(), r, doc{Title: "old", Image: &image}, &got)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -61,3 +61,30 @@ func write(w http.ResponseWriter, data []byte) {
 		log.Println(err)
 	}
 }
+
+// FieldError tells which field of a request is invalid and why.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// FieldErrors is Error along with the fields that caused it.
+func FieldErrors(w http.ResponseWriter, statusCode int, message error, fields []FieldError) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(statusCode)
+
+	p := struct {
+		Message string       `json:"message"`
+		Errors  []FieldError `json:"errors"`
+	}{
+		Message: message.Error(),
+		Errors:  fields,
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		log.Println(err)
+	}
+
+	write(w, data)
+}
@@ -16,6 +16,12 @@ type Standard struct {
 type Meta struct {
 	Size  int `json:"size"`
 	Total int `json:"total"`
+	// Scores is how relevant each result of a search is, by its ID.
+	Scores map[uint64]float64 `json:"scores,omitempty"`
+	// NextCursor and PrevCursor fetch the pages around this one when passed
+	// as `after` and `before`.
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 func JSON(w http.ResponseWriter, statusCode int, payload interface{}) {
@@ -0,0 +1,38 @@
+package respond
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Links sets the Link header (RFC 8288) to the pages around this one, as
+// found in meta. They are the requested URL with its cursor replaced, so
+// that filters and sorting carry over.
+func Links(w http.ResponseWriter, r *http.Request, meta Meta) {
+	var links []string
+	for _, page := range []struct {
+		rel, param, cursor string
+	}{
+		{rel: "next", param: "after", cursor: meta.NextCursor},
+		{rel: "prev", param: "before", cursor: meta.PrevCursor},
+	} {
+		if page.cursor == "" {
+			continue
+		}
+
+		u := *r.URL
+		q := u.Query()
+		for _, param := range []string{"after", "before", "page", "offset"} {
+			q.Del(param)
+		}
+		q.Set(page.param, page.cursor)
+		u.RawQuery = q.Encode()
+
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), page.rel))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
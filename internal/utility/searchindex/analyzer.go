@@ -0,0 +1,126 @@
+package searchindex
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// englishStopWords are too common to tell documents apart, so they are
+// neither indexed nor searched for.
+var englishStopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {},
+	"but": {}, "by": {}, "for": {}, "if": {}, "in": {}, "into": {}, "is": {},
+	"it": {}, "no": {}, "not": {}, "of": {}, "on": {}, "or": {}, "such": {},
+	"that": {}, "the": {}, "their": {}, "then": {}, "there": {}, "these": {},
+	"they": {}, "this": {}, "to": {}, "was": {}, "will": {}, "with": {},
+}
+
+// token is a word of a text and where it is, in bytes.
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text into words made of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			tokens = append(tokens, token{word: text[start:i], start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: text[start:], start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+// analyzer turns words into the terms they are indexed and searched by.
+type analyzer struct {
+	english bool
+}
+
+func newAnalyzer(language string) analyzer {
+	return analyzer{english: language == "" || language == "english"}
+}
+
+// term returns the term of word, or false for a word not worth indexing.
+func (a analyzer) term(word string) (string, bool) {
+	word = strings.ToLower(word)
+	if !a.english {
+		return word, true
+	}
+	if _, ok := englishStopWords[word]; ok {
+		return "", false
+	}
+	return stem(word), true
+}
+
+// terms returns the terms of text in order, repeated as often as they
+// appear.
+func (a analyzer) terms(text string) []string {
+	var terms []string
+	for _, t := range tokenize(text) {
+		if term, ok := a.term(t.word); ok {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// queryTerm is a term searched for. A prefix term matches every term that
+// starts with it.
+type queryTerm struct {
+	term   string
+	prefix bool
+	// word is the prefix as typed. Stemming it can cut it short, such as
+	// "happy" to "happi", so words of a text are matched against both.
+	word string
+}
+
+// parse splits a query into terms. A word directly followed by * is a
+// prefix. Stop words are not, since they are likely the start of a longer
+// word.
+func (a analyzer) parse(query string) []queryTerm {
+	var terms []queryTerm
+	for _, t := range tokenize(query) {
+		if r, _ := utf8.DecodeRuneInString(query[t.end:]); r == '*' {
+			word := strings.ToLower(t.word)
+			term := word
+			if a.english {
+				term = stem(word)
+			}
+			terms = append(terms, queryTerm{term: term, prefix: true, word: word})
+			continue
+		}
+		if term, ok := a.term(t.word); ok {
+			terms = append(terms, queryTerm{term: term})
+		}
+	}
+	return terms
+}
+
+// matches tells whether word is found by any of terms.
+func (a analyzer) matches(word string, terms []queryTerm) bool {
+	lower := strings.ToLower(word)
+	term, ok := a.term(word)
+	for _, q := range terms {
+		switch {
+		case q.prefix && strings.HasPrefix(lower, q.word):
+			return true
+		case q.prefix && ok && strings.HasPrefix(term, q.term):
+			return true
+		case !q.prefix && ok && term == q.term:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,521 @@
+// Package searchindex is an embedded full-text search index. Documents are
+// split into stemmed terms kept in an inverted index, and searches are
+// ranked with BM25. The index lives in memory and is saved to a file so that
+// it is ready to use when the application restarts.
+package searchindex
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BM25 parameters. k1 limits how much repeating a term raises the score of
+// a document, and b how much long fields are penalised.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// version is bumped whenever the file format changes, so that older files
+// are rebuilt rather than misread.
+const version = 1
+
+// Field is a field of the documents. Terms found in a field with a higher
+// boost weigh more.
+type Field struct {
+	Name  string
+	Boost float64
+}
+
+// Hit is a document found by a search.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index is a search index of documents made of text fields. It is safe for
+// concurrent use.
+type Index struct {
+	path     string
+	fields   []Field
+	analyzer analyzer
+
+	mu    sync.RWMutex
+	data  *data
+	ready bool
+	// dirty is set when the index changed since last saved.
+	dirty  atomic.Bool
+	saveMu sync.Mutex
+	// journal holds the writes made while the index is being rebuilt, to be
+	// replayed onto the rebuilt one.
+	journal    []write
+	rebuilding bool
+	rebuildMu  sync.Mutex
+
+	stop chan bool
+}
+
+type write struct {
+	id  string
+	doc map[string]string
+}
+
+// document holds how often each term appears in each field of a document,
+// and how many terms each field has.
+type document struct {
+	Terms   map[string]map[string]int
+	Lengths map[string]int
+}
+
+type data struct {
+	Docs map[string]*document
+	// Postings lists documents that have a term, by field then term, with
+	// how often the term appears in them.
+	Postings    map[string]map[string]map[string]int
+	TotalLength map[string]int
+
+	// sorted holds the terms of each field in order, to find those starting
+	// with a prefix. It is not saved.
+	sorted map[string][]string
+}
+
+func newData() *data {
+	return &data{
+		Docs:        make(map[string]*document),
+		Postings:    make(map[string]map[string]map[string]int),
+		TotalLength: make(map[string]int),
+		sorted:      make(map[string][]string),
+	}
+}
+
+type file struct {
+	Version int
+	Fields  []Field
+	Data    *data
+}
+
+// Open loads the index saved at path, if any, and saves it there every
+// saveInterval while it changes, until Close is called. language decides
+// how words are stemmed: only english is, other languages are matched
+// word for word. An index that cannot be loaded starts empty and is not
+// Ready until rebuilt.
+func Open(path, language string, saveInterval time.Duration, fields ...Field) (*Index, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating search index directory: %w", err)
+	}
+
+	i := &Index{
+		path:     path,
+		fields:   fields,
+		analyzer: newAnalyzer(language),
+		data:     newData(),
+	}
+
+	if err := i.load(); err != nil {
+		slog.Warn("search index will be rebuilt", "path", path, "error", err)
+	}
+
+	if saveInterval > 0 {
+		i.stop = make(chan bool)
+		go i.start(saveInterval)
+	}
+
+	return i, nil
+}
+
+func (i *Index) load() error {
+	f, err := os.Open(i.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var saved file
+	if err = gob.NewDecoder(f).Decode(&saved); err != nil {
+		return err
+	}
+	if saved.Version != version || !slices.Equal(saved.Fields, i.fields) || saved.Data == nil {
+		return errors.New("saved with other fields or format")
+	}
+
+	saved.Data.sorted = make(map[string][]string)
+	for field, postings := range saved.Data.Postings {
+		terms := make([]string, 0, len(postings))
+		for term := range postings {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+		saved.Data.sorted[field] = terms
+	}
+
+	i.mu.Lock()
+	i.data = saved.Data
+	i.ready = true
+	i.mu.Unlock()
+
+	return nil
+}
+
+func (i *Index) start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			if err := i.Save(); err != nil {
+				slog.Error("saving search index", "path", i.path, "error", err)
+			}
+		case <-i.stop:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// Close stops saving in the background and saves the index one last time.
+func (i *Index) Close() error {
+	if i == nil {
+		return nil
+	}
+	if i.stop != nil {
+		i.stop <- true
+	}
+	return i.Save()
+}
+
+// Save writes the index to its file if it changed since last saved. The
+// file is replaced at once so that it is never left half written.
+func (i *Index) Save() (err error) {
+	i.saveMu.Lock()
+	defer i.saveMu.Unlock()
+
+	if !i.dirty.Swap(false) {
+		return nil
+	}
+	defer func() {
+		if err != nil {
+			i.dirty.Store(true)
+		}
+	}()
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	// A partial index is not worth keeping, it is rebuilt anyway.
+	if !i.ready {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(i.path), filepath.Base(i.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = gob.NewEncoder(tmp).Encode(file{
+		Version: version,
+		Fields:  i.fields,
+		Data:    i.data,
+	})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), i.path)
+}
+
+// Ready tells whether the index holds every document, which is once it was
+// loaded from its file or rebuilt.
+func (i *Index) Ready() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.ready
+}
+
+// Put adds doc, its text by field name, to the index or replaces it.
+func (i *Index) Put(id string, doc map[string]string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.put(i.data, id, doc)
+	i.dirty.Store(true)
+	if i.rebuilding {
+		i.journal = append(i.journal, write{id: id, doc: doc})
+	}
+}
+
+// Delete removes a document from the index.
+func (i *Index) Delete(id string) {
+	i.Put(id, nil)
+}
+
+func (i *Index) put(d *data, id string, doc map[string]string) {
+	i.remove(d, id)
+	if doc == nil {
+		return
+	}
+
+	document := &document{
+		Terms:   make(map[string]map[string]int),
+		Lengths: make(map[string]int),
+	}
+	for _, field := range i.fields {
+		terms := i.analyzer.terms(doc[field.Name])
+		if len(terms) == 0 {
+			continue
+		}
+
+		frequencies := make(map[string]int)
+		for _, term := range terms {
+			frequencies[term]++
+		}
+		document.Terms[field.Name] = frequencies
+		document.Lengths[field.Name] = len(terms)
+		d.TotalLength[field.Name] += len(terms)
+
+		if d.Postings[field.Name] == nil {
+			d.Postings[field.Name] = make(map[string]map[string]int)
+		}
+		for term, frequency := range frequencies {
+			postings, ok := d.Postings[field.Name][term]
+			if !ok {
+				postings = make(map[string]int)
+				d.Postings[field.Name][term] = postings
+				d.sorted[field.Name] = insert(d.sorted[field.Name], term)
+			}
+			postings[id] = frequency
+		}
+	}
+	d.Docs[id] = document
+}
+
+func (i *Index) remove(d *data, id string) {
+	document, ok := d.Docs[id]
+	if !ok {
+		return
+	}
+
+	for field, frequencies := range document.Terms {
+		d.TotalLength[field] -= document.Lengths[field]
+		for term := range frequencies {
+			postings := d.Postings[field][term]
+			delete(postings, id)
+			if len(postings) == 0 {
+				delete(d.Postings[field], term)
+				d.sorted[field] = without(d.sorted[field], term)
+			}
+		}
+	}
+	delete(d.Docs, id)
+}
+
+func insert(sorted []string, term string) []string {
+	n, found := slices.BinarySearch(sorted, term)
+	if found {
+		return sorted
+	}
+	return slices.Insert(sorted, n, term)
+}
+
+func without(sorted []string, term string) []string {
+	n, found := slices.BinarySearch(sorted, term)
+	if !found {
+		return sorted
+	}
+	return slices.Delete(sorted, n, n+1)
+}
+
+// Rebuild replaces the content of the index with the documents passed by
+// load to put. The index keeps serving searches meanwhile, and writes made
+// in the meantime are kept.
+func (i *Index) Rebuild(load func(put func(id string, doc map[string]string)) error) error {
+	i.rebuildMu.Lock()
+	defer i.rebuildMu.Unlock()
+
+	i.mu.Lock()
+	i.rebuilding = true
+	i.journal = nil
+	i.mu.Unlock()
+
+	rebuilt := newData()
+	err := load(func(id string, doc map[string]string) {
+		i.put(rebuilt, id, doc)
+	})
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.rebuilding = false
+	journal := i.journal
+	i.journal = nil
+	if err != nil {
+		return err
+	}
+
+	for _, w := range journal {
+		i.put(rebuilt, w.id, w.doc)
+	}
+	i.data = rebuilt
+	i.ready = true
+	i.dirty.Store(true)
+
+	return nil
+}
+
+// Search returns the page of documents from offset having every term of
+// query, best first, and how many there are in all. A word followed by *
+// matches every word starting with it.
+func (i *Index) Search(query string, offset, limit int) ([]Hit, int) {
+	terms := i.analyzer.parse(query)
+	if len(terms) == 0 {
+		return []Hit{}, 0
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var scores map[string]float64
+	for _, term := range terms {
+		termScores := i.score(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			termScore, ok := termScores[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] = score + termScore
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ID < hits[b].ID
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return []Hit{}, total
+	}
+	hits = hits[offset:]
+	if limit > 0 && limit < len(hits) {
+		hits = hits[:limit]
+	}
+
+	return hits, total
+}
+
+// score returns the BM25 score of every document having term, summed over
+// fields. Of the terms a prefix expands to, only the best one counts.
+func (i *Index) score(term queryTerm) map[string]float64 {
+	scores := make(map[string]float64)
+	n := float64(len(i.data.Docs))
+
+	for _, field := range i.fields {
+		if i.data.TotalLength[field.Name] == 0 {
+			continue
+		}
+		avgLength := float64(i.data.TotalLength[field.Name]) / n
+
+		fieldScores := make(map[string]float64)
+		for _, expanded := range i.expand(field.Name, term) {
+			postings := i.data.Postings[field.Name][expanded]
+			df := float64(len(postings))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+			for id, frequency := range postings {
+				tf := float64(frequency)
+				length := float64(i.data.Docs[id].Lengths[field.Name])
+				score := field.Boost * idf * tf * (k1 + 1) / (tf + k1*(1-b+b*length/avgLength))
+				fieldScores[id] = max(fieldScores[id], score)
+			}
+		}
+
+		for id, score := range fieldScores {
+			scores[id] += score
+		}
+	}
+
+	return scores
+}
+
+func (i *Index) expand(field string, term queryTerm) []string {
+	if !term.prefix {
+		return []string{term.term}
+	}
+
+	sorted := i.data.sorted[field]
+	var terms []string
+	for n, _ := slices.BinarySearch(sorted, term.term); n < len(sorted) && strings.HasPrefix(sorted[n], term.term); n++ {
+		terms = append(terms, sorted[n])
+	}
+	return terms
+}
+
+// Highlight wraps the words of text found by query in <mark></mark>. With
+// maxWords above zero, only that many words around the first one found are
+// kept, and cuts are marked with an ellipsis.
+func (i *Index) Highlight(text, query string, maxWords int) string {
+	terms := i.analyzer.parse(query)
+	tokens := tokenize(text)
+
+	first, last := 0, len(tokens)
+	if maxWords > 0 && len(tokens) > maxWords {
+		found := 0
+		for n, t := range tokens {
+			if i.analyzer.matches(t.word, terms) {
+				found = n
+				break
+			}
+		}
+		first = max(0, min(found-maxWords/2, len(tokens)-maxWords))
+		last = first + maxWords
+	}
+
+	var sb strings.Builder
+	from := 0
+	if first > 0 {
+		sb.WriteString("... ")
+		from = tokens[first].start
+	}
+	for _, t := range tokens[first:last] {
+		sb.WriteString(text[from:t.start])
+		if i.analyzer.matches(t.word, terms) {
+			sb.WriteString("<mark>" + t.word + "</mark>")
+		} else {
+			sb.WriteString(t.word)
+		}
+		from = t.end
+	}
+	if last < len(tokens) {
+		sb.WriteString(" ...")
+	} else {
+		sb.WriteString(text[from:])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,140 @@
+package searchindex
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fields = []Field{{Name: "title", Boost: 2}, {Name: "description", Boost: 1}}
+
+func newIndex(t *testing.T) *Index {
+	idx, err := Open(filepath.Join(t.TempDir(), "test.idx"), "english", 0, fields...)
+	assert.Nil(t, err)
+	return idx
+}
+
+func ids(hits []Hit) []string {
+	got := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		got = append(got, hit.ID)
+	}
+	return got
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := newIndex(t)
+	idx.Put("1", map[string]string{"title": "Learning Go", "description": "A guide to programming with gophers"})
+	idx.Put("2", map[string]string{"title": "Gardening", "description": "Growing vegetables and learning about soil"})
+	idx.Put("3", map[string]string{"title": "Cooking", "description": "Recipes for busy people programming at night"})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "title weighs more", query: "learn", want: []string{"1", "2"}},
+		{name: "stemmed", query: "programs", want: []string{"1", "3"}},
+		{name: "every term", query: "learning gophers", want: []string{"1"}},
+		{name: "prefix", query: "veg*", want: []string{"2"}},
+		{name: "stemmed prefix", query: "prog*", want: []string{"1", "3"}},
+		{name: "stop words only", query: "the and", want: []string{}},
+		{name: "none", query: "astronomy", want: []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hits, total := idx.Search(test.query, 0, 10)
+			assert.Equal(t, test.want, ids(hits))
+			assert.Equal(t, len(test.want), total)
+		})
+	}
+
+	t.Run("paginates", func(t *testing.T) {
+		hits, total := idx.Search("learn", 1, 1)
+		assert.Equal(t, []string{"2"}, ids(hits))
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("replaces and deletes", func(t *testing.T) {
+		idx.Put("3", map[string]string{"title": "Cooking for gophers"})
+		hits, _ := idx.Search("programming", 0, 10)
+		assert.Equal(t, []string{"1"}, ids(hits))
+
+		idx.Delete("1")
+		hits, _ = idx.Search("gopher", 0, 10)
+		assert.Equal(t, []string{"3"}, ids(hits))
+	})
+}
+
+func TestIndex_SaveAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.idx")
+	idx, err := Open(path, "english", 0, fields...)
+	assert.Nil(t, err)
+	assert.False(t, idx.Ready())
+
+	err = idx.Rebuild(func(put func(id string, doc map[string]string)) error {
+		put("1", map[string]string{"title": "Learning Go"})
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.True(t, idx.Ready())
+	assert.Nil(t, idx.Close())
+
+	reopened, err := Open(path, "english", 0, fields...)
+	assert.Nil(t, err)
+	assert.True(t, reopened.Ready())
+	hits, _ := reopened.Search("learn*", 0, 10)
+	assert.Equal(t, []string{"1"}, ids(hits))
+
+	// An index saved with other fields is not trusted.
+	other, err := Open(path, "english", 0, Field{Name: "name", Boost: 1})
+	assert.Nil(t, err)
+	assert.False(t, other.Ready())
+}
+
+func TestIndex_Rebuild(t *testing.T) {
+	idx := newIndex(t)
+	idx.Put("stale", map[string]string{"title": "stale"})
+
+	err := idx.Rebuild(func(put func(id string, doc map[string]string)) error {
+		put("1", map[string]string{"title": "first"})
+		// Written while rebuilding, so must survive it.
+		idx.Put("2", map[string]string{"title": "second"})
+		idx.Delete("1")
+		return nil
+	})
+	assert.Nil(t, err)
+
+	hits, _ := idx.Search("stale", 0, 10)
+	assert.Empty(t, hits)
+	hits, _ = idx.Search("first", 0, 10)
+	assert.Empty(t, hits)
+	hits, _ = idx.Search("second", 0, 10)
+	assert.Equal(t, []string{"2"}, ids(hits))
+}
+
+func TestIndex_Highlight(t *testing.T) {
+	idx := newIndex(t)
+
+	tests := []struct {
+		name     string
+		text     string
+		query    string
+		maxWords int
+		want     string
+	}{
+		{name: "stemmed", text: "Learning Go, learned fast", query: "learn", want: "<mark>Learning</mark> Go, <mark>learned</mark> fast"},
+		{name: "prefix", text: "Happy gophers", query: "hap*", want: "<mark>Happy</mark> gophers"},
+		{name: "no match", text: "Learning Go", query: "rust", want: "Learning Go"},
+		{name: "window", text: "one two three four five six seven", query: "five", maxWords: 3, want: "... four <mark>five</mark> six ..."},
+		{name: "window at start", text: "one two three four", query: "one", maxWords: 2, want: "<mark>one</mark> two ..."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, idx.Highlight(test.text, test.query, test.maxWords))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package searchindex
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "caresses", want: "caress"},
+		{word: "ponies", want: "poni"},
+		{word: "cats", want: "cat"},
+		{word: "agreed", want: "agre"},
+		{word: "hopping", want: "hop"},
+		{word: "filing", want: "file"},
+		{word: "happy", want: "happi"},
+		{word: "relational", want: "relat"},
+		{word: "generalization", want: "gener"},
+		{word: "gophers", want: "gopher"},
+		{word: "go", want: "go"},
+		{word: "café", want: "café"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.word, func(t *testing.T) {
+			if got := stem(test.word); got != test.want {
+				t.Errorf("stem(%q) = %q, want %q", test.word, got, test.want)
+			}
+		})
+	}
+}
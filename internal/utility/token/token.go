@@ -0,0 +1,210 @@
+// Package token issues and verifies short-lived access tokens. Tokens are
+// JWTs signed with Ed25519 and their public keys are published as a JWKS so
+// that other services can verify them too.
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// Type is set in `typ` header as recommended by RFC 9068 so that an access
+// token cannot be mistaken for another kind of JWT.
+const Type = "at+jwt"
+
+var (
+	ErrNoSigningKey = errors.New("at least one signing key is required")
+	ErrInvalid      = errors.New("access token is invalid or has expired")
+)
+
+// Issuer signs access tokens with its first key, and verifies them with any
+// of its keys.
+type Issuer struct {
+	issuer string
+	ttl    time.Duration
+
+	signer jose.Signer
+	keys   map[string]ed25519.PublicKey
+	jwks   jose.JSONWebKeySet
+}
+
+// Claims are what an access token says about its bearer.
+type Claims struct {
+	UserID uint64
+	// SessionID ties access tokens to the refresh token family they were
+	// issued from.
+	SessionID string
+	ExpiresAt time.Time
+}
+
+type privateClaims struct {
+	SessionID string `json:"sid,omitempty"`
+}
+
+// ParseKeys decodes base64 encoded Ed25519 seeds.
+func ParseKeys(seeds []string) ([]ed25519.PrivateKey, error) {
+	keys := make([]ed25519.PrivateKey, 0, len(seeds))
+	for i, seed := range seeds {
+		b, err := base64.StdEncoding.DecodeString(seed)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %d: %w", i, err)
+		}
+		if len(b) != ed25519.SeedSize {
+			return nil, fmt.Errorf("signing key %d: must be %d bytes, got %d", i, ed25519.SeedSize, len(b))
+		}
+		keys = append(keys, ed25519.NewKeyFromSeed(b))
+	}
+
+	return keys, nil
+}
+
+// GenerateKey returns a new random signing key.
+func GenerateKey() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	return key, err
+}
+
+func NewIssuer(issuer string, ttl time.Duration, keys []ed25519.PrivateKey) (*Issuer, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	i := &Issuer{
+		issuer: issuer,
+		ttl:    ttl,
+		keys:   make(map[string]ed25519.PublicKey, len(keys)),
+	}
+
+	for _, key := range keys {
+		public := key.Public().(ed25519.PublicKey)
+
+		kid, err := keyID(public)
+		if err != nil {
+			return nil, err
+		}
+
+		i.keys[kid] = public
+		i.jwks.Keys = append(i.jwks.Keys, jose.JSONWebKey{
+			Key:       public,
+			KeyID:     kid,
+			Algorithm: string(jose.EdDSA),
+			Use:       "sig",
+		})
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.EdDSA,
+		Key:       jose.JSONWebKey{Key: keys[0], KeyID: i.jwks.Keys[0].KeyID},
+	}, (&jose.SignerOptions{}).WithType(Type))
+	if err != nil {
+		return nil, err
+	}
+	i.signer = signer
+
+	return i, nil
+}
+
+// Sign issues an access token for userID.
+func (i *Issuer) Sign(userID uint64, sessionID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", time.Time{}, err
+	}
+
+	token, err := jwt.Signed(i.signer).
+		Claims(jwt.Claims{
+			ID:        base64.RawURLEncoding.EncodeToString(jti),
+			Issuer:    i.issuer,
+			Subject:   strconv.FormatUint(userID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(expiresAt),
+		}).
+		Claims(privateClaims{SessionID: sessionID}).
+		Serialize()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// Verify checks signature, type, issuer and expiry of an access token.
+func (i *Issuer) Verify(raw string) (*Claims, error) {
+	token, err := jwt.ParseSigned(raw, []jose.SignatureAlgorithm{jose.EdDSA})
+	if err != nil || len(token.Headers) != 1 {
+		return nil, ErrInvalid
+	}
+
+	header := token.Headers[0]
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != Type {
+		return nil, ErrInvalid
+	}
+
+	key, ok := i.keys[header.KeyID]
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	var claims jwt.Claims
+	var private privateClaims
+	if err = token.Claims(key, &claims, &private); err != nil {
+		return nil, ErrInvalid
+	}
+
+	if err = claims.Validate(jwt.Expected{Issuer: i.issuer, Time: time.Now()}); err != nil {
+		return nil, ErrInvalid
+	}
+	if claims.Expiry == nil {
+		return nil, ErrInvalid
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	return &Claims{
+		UserID:    userID,
+		SessionID: private.SessionID,
+		ExpiresAt: claims.Expiry.Time(),
+	}, nil
+}
+
+// VerifyAccessToken lets Issuer be used by middleware.AccessToken.
+func (i *Issuer) VerifyAccessToken(raw string) (uint64, string, error) {
+	claims, err := i.Verify(raw)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return claims.UserID, claims.SessionID, nil
+}
+
+// JWKS returns public keys, including those kept only for verification.
+func (i *Issuer) JWKS() jose.JSONWebKeySet {
+	return i.jwks
+}
+
+// keyID is the RFC 7638 thumbprint of a public key, so the same key always
+// gets the same ID on every instance.
+func keyID(public ed25519.PublicKey) (string, error) {
+	thumbprint, err := (&jose.JSONWebKey{Key: public}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
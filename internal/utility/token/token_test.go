@@ -0,0 +1,149 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssuer(t *testing.T) {
+	oldKey, err := GenerateKey()
+	assert.Nil(t, err)
+	newKey, err := GenerateKey()
+	assert.Nil(t, err)
+	otherKey, err := GenerateKey()
+	assert.Nil(t, err)
+
+	old, err := NewIssuer("go8", time.Minute, []ed25519.PrivateKey{oldKey})
+	assert.Nil(t, err)
+	rotated, err := NewIssuer("go8", time.Minute, []ed25519.PrivateKey{newKey, oldKey})
+	assert.Nil(t, err)
+	other, err := NewIssuer("go8", time.Minute, []ed25519.PrivateKey{otherKey})
+	assert.Nil(t, err)
+	otherIssuer, err := NewIssuer("someone-else", time.Minute, []ed25519.PrivateKey{oldKey})
+	assert.Nil(t, err)
+	expired, err := NewIssuer("go8", -time.Hour, []ed25519.PrivateKey{oldKey})
+	assert.Nil(t, err)
+
+	type args struct {
+		signer   *Issuer
+		verifier *Issuer
+		tamper   bool
+	}
+	type want struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "same key",
+			args: args{signer: old, verifier: old},
+			want: want{},
+		},
+		{
+			name: "old key after rotation",
+			args: args{signer: old, verifier: rotated},
+			want: want{},
+		},
+		{
+			name: "new key",
+			args: args{signer: rotated, verifier: rotated},
+			want: want{},
+		},
+		{
+			name: "new key is unknown to old issuer",
+			args: args{signer: rotated, verifier: old},
+			want: want{err: ErrInvalid},
+		},
+		{
+			name: "unknown key",
+			args: args{signer: other, verifier: old},
+			want: want{err: ErrInvalid},
+		},
+		{
+			name: "wrong issuer",
+			args: args{signer: otherIssuer, verifier: old},
+			want: want{err: ErrInvalid},
+		},
+		{
+			name: "expired",
+			args: args{signer: expired, verifier: old},
+			want: want{err: ErrInvalid},
+		},
+		{
+			name: "tampered payload",
+			args: args{signer: old, verifier: old, tamper: true},
+			want: want{err: ErrInvalid},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := tt.args.signer.Sign(42, "family")
+			assert.Nil(t, err)
+
+			if tt.args.tamper {
+				parts := strings.Split(token, ".")
+				parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"go8","sub":"1","exp":9999999999}`))
+				token = strings.Join(parts, ".")
+			}
+
+			claims, err := tt.args.verifier.Verify(token)
+			assert.Equal(t, tt.want.err, err)
+			if err == nil {
+				assert.Equal(t, uint64(42), claims.UserID)
+				assert.Equal(t, "family", claims.SessionID)
+			}
+		})
+	}
+}
+
+func TestIssuer_JWKS(t *testing.T) {
+	newKey, err := GenerateKey()
+	assert.Nil(t, err)
+	oldKey, err := GenerateKey()
+	assert.Nil(t, err)
+
+	issuer, err := NewIssuer("go8", time.Minute, []ed25519.PrivateKey{newKey, oldKey})
+	assert.Nil(t, err)
+
+	b, err := json.Marshal(issuer.JWKS())
+	assert.Nil(t, err)
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	err = json.Unmarshal(b, &jwks)
+	assert.Nil(t, err)
+
+	assert.Len(t, jwks.Keys, 2)
+	for _, key := range jwks.Keys {
+		assert.Equal(t, "OKP", key["kty"])
+		assert.Equal(t, "Ed25519", key["crv"])
+		assert.Equal(t, "EdDSA", key["alg"])
+		assert.NotEmpty(t, key["kid"])
+		assert.Empty(t, key["d"], "private key must not be published")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	seed := base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize))
+
+	keys, err := ParseKeys([]string{seed})
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+
+	_, err = ParseKeys([]string{"c2hvcnQ="})
+	assert.NotNil(t, err)
+
+	_, err = NewIssuer("go8", time.Minute, nil)
+	assert.Equal(t, ErrNoSigningKey, err)
+}
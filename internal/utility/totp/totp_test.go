@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secret is the SHA-1 seed of the RFC 6238 appendix B test vectors.
+var secret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	// Vectors are eight digits long. Six-digit codes are their last six.
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "59", unix: 59, want: "94287082"},
+		{name: "1111111109", unix: 1111111109, want: "07081804"},
+		{name: "1111111111", unix: 1111111111, want: "14050471"},
+		{name: "1234567890", unix: 1234567890, want: "89005924"},
+		{name: "2000000000", unix: 2000000000, want: "69279037"},
+		{name: "20000000000", unix: 20000000000, want: "65353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Code(secret, time.Unix(tt.unix, 0))
+			assert.Equal(t, tt.want[len(tt.want)-Digits:], got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / Period
+
+	tests := []struct {
+		name   string
+		code   string
+		wantOK bool
+		want   int64
+	}{
+		{name: "current step", code: Code(secret, now), wantOK: true, want: current},
+		{name: "previous step", code: Code(secret, now.Add(-Period*time.Second)), wantOK: true, want: current - 1},
+		{name: "next step", code: Code(secret, now.Add(Period*time.Second)), wantOK: true, want: current + 1},
+		{name: "two steps ago", code: Code(secret, now.Add(-2*Period*time.Second)), wantOK: false},
+		{name: "two steps ahead", code: Code(secret, now.Add(2*Period*time.Second)), wantOK: false},
+		{name: "wrong code", code: "000000", wantOK: false},
+		{name: "too short", code: Code(secret, now)[1:], wantOK: false},
+		{name: "too long", code: Code(secret, now) + "0", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(secret, tt.code, now)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.want, step)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("go8", "user@example.com", secret)
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/go8:user@example.com?"))
+	assert.Contains(t, uri, "secret="+Encode(secret))
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
@@ -0,0 +1,74 @@
+// Package trash empties soft-deleted records once they are past retention.
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+// PurgeFunc hard-deletes records soft-deleted before deletedBefore and
+// returns how many were removed.
+type PurgeFunc func(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+// Purger empties the trash of every registered kind of record.
+type Purger struct {
+	retention time.Duration
+	purges    map[string]PurgeFunc
+	stop      chan bool
+}
+
+// NewPurger starts purging records deleted longer than TRASH_RETENTION ago
+// every TRASH_PURGE_INTERVAL until Stop is called. purges is keyed by a name
+// used in logs, such as `books`.
+func NewPurger(cfg config.Trash, purges map[string]PurgeFunc) *Purger {
+	p := &Purger{
+		retention: cfg.Retention,
+		purges:    purges,
+	}
+	if cfg.PurgeInterval > 0 {
+		p.stop = make(chan bool)
+		go p.start(cfg.PurgeInterval)
+	}
+
+	return p
+}
+
+func (p *Purger) start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			p.Purge(context.Background())
+		case <-p.stop:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// Purge empties the trash once. A failure to purge one kind of record does
+// not stop the others.
+func (p *Purger) Purge(ctx context.Context) {
+	deletedBefore := time.Now().Add(-p.retention)
+
+	for name, purge := range p.purges {
+		purged, err := purge(ctx, deletedBefore)
+		if err != nil {
+			slog.ErrorContext(ctx, "purging trash", "records", name, "error", err)
+			continue
+		}
+		if purged > 0 {
+			slog.InfoContext(ctx, "purged trash", "records", name, "count", purged)
+		}
+	}
+}
+
+// Stop terminates the background purge goroutine.
+func (p *Purger) Stop() {
+	if p.stop != nil {
+		p.stop <- true
+	}
+}
@@ -0,0 +1,249 @@
+// Package elasticsearch is a small client for the parts of Elasticsearch's
+// REST API used to search books and authors: managing an index, writing
+// documents in bulk and searching them.
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+// ErrUnavailable is returned when Elasticsearch cannot be reached or cannot
+// serve the request, so that callers can fall back to another store.
+var ErrUnavailable = errors.New("elasticsearch is unavailable")
+
+// retryAfter is how long requests fail fast with ErrUnavailable after
+// Elasticsearch was found unavailable, rather than each waiting for a
+// timeout.
+const retryAfter = 10 * time.Second
+
+type Client struct {
+	address  string
+	user     string
+	password string
+	http     *http.Client
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func New(cfg config.Elasticsearch) *Client {
+	return &Client{
+		address:  strings.TrimRight(cfg.Address, "/"),
+		user:     cfg.User,
+		password: cfg.Password,
+		http:     &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Action is one document to write in a Bulk request. A nil Document deletes
+// the one with ID.
+type Action struct {
+	ID       string
+	Document any
+}
+
+type SearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []Hit `json:"hits"`
+	} `json:"hits"`
+}
+
+type Hit struct {
+	ID        string              `json:"_id"`
+	Score     float64             `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight"`
+}
+
+// Ping checks that the cluster can be reached.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/", nil, nil)
+}
+
+// CreateIndex creates index with mapping unless it already exists.
+func (c *Client) CreateIndex(ctx context.Context, index string, mapping []byte) error {
+	err := c.do(ctx, http.MethodPut, "/"+url.PathEscape(index), mapping, nil)
+	var respErr *ResponseError
+	if errors.As(err, &respErr) && respErr.Type == "resource_already_exists_exception" {
+		return nil
+	}
+	return err
+}
+
+// DeleteIndex deletes index and all of its documents. A missing index is not
+// an error.
+func (c *Client) DeleteIndex(ctx context.Context, index string) error {
+	err := c.do(ctx, http.MethodDelete, "/"+url.PathEscape(index), nil, nil)
+	var respErr *ResponseError
+	if errors.As(err, &respErr) && respErr.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Bulk indexes and deletes documents of index in one request. Deleting a
+// document that does not exist is not an error.
+func (c *Client) Bulk(ctx context.Context, index string, actions []Action) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, action := range actions {
+		meta := map[string]string{"_index": index, "_id": action.ID}
+		if action.Document == nil {
+			if err := enc.Encode(map[string]any{"delete": meta}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := enc.Encode(map[string]any{"index": meta}); err != nil {
+			return err
+		}
+		if err := enc.Encode(action.Document); err != nil {
+			return err
+		}
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/_bulk", body.Bytes(), &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+
+	for _, item := range resp.Items {
+		for op, result := range item {
+			if result.Status < 300 || (op == "delete" && result.Status == http.StatusNotFound) {
+				continue
+			}
+			return fmt.Errorf("bulk %s of %s failed: %s: %s", op, result.ID, result.Error.Type, result.Error.Reason)
+		}
+	}
+
+	return nil
+}
+
+// Search runs query, a search request body, against index.
+func (c *Client) Search(ctx context.Context, index string, query any) (*SearchResponse, error) {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp SearchResponse
+	if err = c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ResponseError is an error reported by Elasticsearch for a request it could
+// serve, such as a malformed query.
+type ResponseError struct {
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("elasticsearch: %d %s: %s", e.Status, e.Type, e.Reason)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	if c.down() {
+		return ErrUnavailable
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		contentType := "application/json"
+		if path == "/_bulk" {
+			contentType = "application/x-ndjson"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.markDown()
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		c.markDown()
+		return fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		return &ResponseError{
+			Status: resp.StatusCode,
+			Type:   errResp.Error.Type,
+			Reason: errResp.Error.Reason,
+		}
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) down() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Before(c.downUntil)
+}
+
+func (c *Client) markDown() {
+	c.mu.Lock()
+	c.downUntil = time.Now().Add(retryAfter)
+	c.mu.Unlock()
+}
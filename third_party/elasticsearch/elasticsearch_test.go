@@ -0,0 +1,146 @@
+package elasticsearch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gmhafiz/go8/config"
+	"github.com/gmhafiz/go8/third_party/elasticsearch"
+	"github.com/gmhafiz/go8/third_party/elasticsearch/elasticsearchtest"
+)
+
+func TestClient_Index(t *testing.T) {
+	ctx := context.Background()
+	srv := elasticsearchtest.New(t)
+	es := elasticsearch.New(srv.Elasticsearch())
+
+	mapping := []byte(`{"mappings":{}}`)
+	assert.Nil(t, es.CreateIndex(ctx, "books", mapping))
+	assert.Nil(t, es.CreateIndex(ctx, "books", mapping), "creating an existing index")
+
+	got, ok := srv.Mapping("books")
+	assert.True(t, ok)
+	assert.JSONEq(t, string(mapping), string(got))
+
+	assert.Nil(t, es.DeleteIndex(ctx, "books"))
+	assert.Nil(t, es.DeleteIndex(ctx, "books"), "deleting a missing index")
+
+	_, ok = srv.Mapping("books")
+	assert.False(t, ok)
+}
+
+func TestClient_Bulk(t *testing.T) {
+	ctx := context.Background()
+	srv := elasticsearchtest.New(t)
+	es := elasticsearch.New(srv.Elasticsearch())
+
+	err := es.Bulk(ctx, "books", []elasticsearch.Action{
+		{ID: "1", Document: map[string]any{"title": "one"}},
+		{ID: "2", Document: map[string]any{"title": "two"}},
+	})
+	assert.Nil(t, err)
+
+	err = es.Bulk(ctx, "books", []elasticsearch.Action{
+		{ID: "1"},
+		{ID: "3"},
+	})
+	assert.Nil(t, err, "deleting a missing document")
+
+	docs := srv.Documents("books")
+	assert.Len(t, docs, 1)
+	assert.JSONEq(t, `{"title":"two"}`, string(docs["2"]))
+
+	assert.Nil(t, es.Bulk(ctx, "books", nil))
+}
+
+func TestClient_Search(t *testing.T) {
+	ctx := context.Background()
+	srv := elasticsearchtest.New(t)
+	es := elasticsearch.New(srv.Elasticsearch())
+
+	srv.OnSearch(func(index string, body map[string]any) any {
+		assert.Equal(t, "books", index)
+		return map[string]any{
+			"hits": map[string]any{
+				"total": map[string]int{"value": 1},
+				"hits": []map[string]any{
+					{
+						"_id":       "2",
+						"_score":    1.5,
+						"_source":   map[string]string{"title": "two"},
+						"highlight": map[string][]string{"title": {"<mark>two</mark>"}},
+					},
+				},
+			},
+		}
+	})
+
+	resp, err := es.Search(ctx, "books", map[string]any{"query": map[string]any{"match_all": map[string]any{}}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, resp.Hits.Total.Value)
+	assert.Len(t, resp.Hits.Hits, 1)
+	assert.Equal(t, "2", resp.Hits.Hits[0].ID)
+	assert.Equal(t, 1.5, resp.Hits.Hits[0].Score)
+	assert.JSONEq(t, `{"title":"two"}`, string(resp.Hits.Hits[0].Source))
+	assert.Equal(t, []string{"<mark>two</mark>"}, resp.Hits.Hits[0].Highlight["title"])
+
+	assert.Equal(t, []map[string]any{{"query": map[string]any{"match_all": map[string]any{}}}}, srv.Searches())
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("server error", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		es := elasticsearch.New(srv.Elasticsearch())
+
+		srv.FailWith(http.StatusServiceUnavailable)
+		_, err := es.Search(ctx, "books", map[string]any{})
+		assert.True(t, errors.Is(err, elasticsearch.ErrUnavailable))
+
+		// Requests fail fast for a while even though the server is back.
+		srv.FailWith(0)
+		err = es.Ping(ctx)
+		assert.Equal(t, elasticsearch.ErrUnavailable, err)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		srv.Close()
+		es := elasticsearch.New(srv.Elasticsearch())
+
+		err := es.Ping(ctx)
+		assert.True(t, errors.Is(err, elasticsearch.ErrUnavailable))
+	})
+
+	t.Run("bad request is not unavailable", func(t *testing.T) {
+		srv := elasticsearchtest.New(t)
+		es := elasticsearch.New(srv.Elasticsearch())
+
+		_, err := es.Search(ctx, "books/_doc", map[string]any{})
+		var respErr *elasticsearch.ResponseError
+		assert.True(t, errors.As(err, &respErr))
+		assert.Equal(t, http.StatusBadRequest, respErr.Status)
+		assert.False(t, errors.Is(err, elasticsearch.ErrUnavailable))
+	})
+}
+
+func TestClient_BasicAuth(t *testing.T) {
+	srv := elasticsearchtest.New(t)
+
+	var user, password string
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ = r.BasicAuth()
+		handler.ServeHTTP(w, r)
+	})
+
+	es := elasticsearch.New(config.Elasticsearch{Address: srv.URL, User: "elastic", Password: "secret"})
+	assert.Nil(t, es.Ping(context.Background()))
+	assert.Equal(t, "elastic", user)
+	assert.Equal(t, "secret", password)
+}
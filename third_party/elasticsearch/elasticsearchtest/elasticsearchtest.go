@@ -0,0 +1,187 @@
+// Package elasticsearchtest provides a fake Elasticsearch server for tests.
+// It keeps indices and documents in memory and answers searches with
+// whatever the test decides.
+package elasticsearchtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+// SearchFunc answers a search of index made with body.
+type SearchFunc func(index string, body map[string]any) any
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	indices  map[string]json.RawMessage
+	docs     map[string]map[string]json.RawMessage
+	searches []map[string]any
+	search   SearchFunc
+	status   int
+}
+
+// New starts a fake server that is closed when the test ends.
+func New(t *testing.T) *Server {
+	s := &Server{
+		indices: make(map[string]json.RawMessage),
+		docs:    make(map[string]map[string]json.RawMessage),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Elasticsearch returns the configuration to reach the server.
+func (s *Server) Elasticsearch() config.Elasticsearch {
+	return config.Elasticsearch{Address: s.URL}
+}
+
+// OnSearch sets how searches are answered. Without it, nothing is found.
+func (s *Server) OnSearch(f SearchFunc) {
+	s.mu.Lock()
+	s.search = f
+	s.mu.Unlock()
+}
+
+// FailWith makes every request fail with status until it is set back to 0.
+func (s *Server) FailWith(status int) {
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
+
+// Mapping returns the mapping index was created with.
+func (s *Server) Mapping(index string) (json.RawMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mapping, ok := s.indices[index]
+	return mapping, ok
+}
+
+// Documents returns the documents of index by ID.
+func (s *Server) Documents(index string) map[string]json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	docs := make(map[string]json.RawMessage, len(s.docs[index]))
+	for id, doc := range s.docs[index] {
+		docs[id] = doc
+	}
+	return docs
+}
+
+// Searches returns the bodies of searches made so far.
+func (s *Server) Searches() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.searches...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.URL.Path == "/":
+		writeJSON(w, http.StatusOK, map[string]any{"tagline": "You Know, for Search"})
+	case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+		s.bulk(w, body)
+	case len(path) == 2 && path[1] == "_search":
+		var query map[string]any
+		_ = json.Unmarshal(body, &query)
+		s.searches = append(s.searches, query)
+		if s.search != nil {
+			writeJSON(w, http.StatusOK, s.search(path[0], query))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"hits": map[string]any{"total": map[string]int{"value": 0}, "hits": []any{}}})
+	case len(path) == 1 && r.Method == http.MethodPut:
+		if _, ok := s.indices[path[0]]; ok {
+			writeError(w, http.StatusBadRequest, "resource_already_exists_exception")
+			return
+		}
+		s.indices[path[0]] = body
+		s.docs[path[0]] = make(map[string]json.RawMessage)
+		writeJSON(w, http.StatusOK, map[string]any{"acknowledged": true})
+	case len(path) == 1 && r.Method == http.MethodDelete:
+		if _, ok := s.indices[path[0]]; !ok {
+			writeError(w, http.StatusNotFound, "index_not_found_exception")
+			return
+		}
+		delete(s.indices, path[0])
+		delete(s.docs, path[0])
+		writeJSON(w, http.StatusOK, map[string]any{"acknowledged": true})
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported")
+	}
+}
+
+func (s *Server) bulk(w http.ResponseWriter, body []byte) {
+	type meta struct {
+		Index string `json:"_index"`
+		ID    string `json:"_id"`
+	}
+
+	var items []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var action map[string]meta
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			writeError(w, http.StatusBadRequest, "parse_exception")
+			return
+		}
+
+		for op, m := range action {
+			if s.docs[m.Index] == nil {
+				s.docs[m.Index] = make(map[string]json.RawMessage)
+			}
+
+			status := http.StatusOK
+			switch op {
+			case "index":
+				scanner.Scan()
+				s.docs[m.Index][m.ID] = append(json.RawMessage(nil), scanner.Bytes()...)
+			case "delete":
+				if _, ok := s.docs[m.Index][m.ID]; !ok {
+					status = http.StatusNotFound
+				}
+				delete(s.docs[m.Index], m.ID)
+			}
+			items = append(items, map[string]any{op: map[string]any{"_id": m.ID, "status": status}})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"errors": false, "items": items})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, errType string) {
+	writeJSON(w, status, map[string]any{
+		"error":  map[string]string{"type": errType, "reason": errType},
+		"status": status,
+	})
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File writes each message as an .eml file into a directory. Useful for
+// local development where emails can be opened with any mail client.
+type File struct {
+	dir string
+}
+
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+func (f *File) Send(_ context.Context, msg *Message) error {
+	if err := os.MkdirAll(f.dir, 0o750); err != nil {
+		return fmt.Errorf("mailer.File.Send: %w", err)
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_").Replace(strings.Join(msg.To, "_"))
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), recipient)
+
+	err := os.WriteFile(filepath.Join(f.dir, name), compose("", msg), 0o600)
+	if err != nil {
+		return fmt.Errorf("mailer.File.Send: %w", err)
+	}
+
+	return nil
+}
+
+// Log prints messages to the default logger instead of sending them.
+type Log struct{}
+
+func NewLog() *Log {
+	return &Log{}
+}
+
+func (l *Log) Send(ctx context.Context, msg *Message) error {
+	slog.InfoContext(ctx, "mail",
+		"to", strings.Join(msg.To, ","),
+		"subject", msg.Subject,
+		"body", msg.Body,
+	)
+	return nil
+}
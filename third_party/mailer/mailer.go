@@ -0,0 +1,33 @@
+// Package mailer sends transactional emails such as account verification
+// links. The implementation is chosen from config so that local development
+// does not need a running SMTP server.
+package mailer
+
+import (
+	"context"
+
+	"github.com/gmhafiz/go8/config"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New returns a Mailer based on MAIL_DRIVER. Unknown drivers fall back to
+// logging the message.
+func New(cfg config.Mail) Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTP(cfg)
+	case "file":
+		return NewFile(cfg.Directory)
+	default:
+		return NewLog()
+	}
+}
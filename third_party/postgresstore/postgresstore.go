@@ -3,15 +3,25 @@
 //
 //  1. It saves a uint64 data along with the session data for the purpose of user session invalidation.
 //  2. Tokens are hashed before being saved into the database.
+//  3. It records when and where a session is used so that users can list their devices.
 //
-// The schema is identical to scs library but with added `user_id` foreign key column:
+// It implements sessionstore.Store.
+//
+// The schema is identical to scs library but with added `user_id` foreign key column and
+// session metadata. `public_id` identifies a session to users without exposing its token:
 //
 //	CREATE TABLE IF NOT EXISTS sessions
 //	(
-//	    token   TEXT PRIMARY KEY,
-//	    user_id BIGINT      NOT NULL CONSTRAINT session_user_fk REFERENCES users ON DELETE CASCADE ,
-//	    data    BYTEA       NOT NULL,
-//	    expiry  TIMESTAMPTZ NOT NULL
+//	    token        TEXT PRIMARY KEY,
+//	    user_id      BIGINT      NOT NULL CONSTRAINT session_user_fk REFERENCES users ON DELETE CASCADE ,
+//	    data         BYTEA       NOT NULL,
+//	    expiry       TIMESTAMPTZ NOT NULL,
+//	    public_id    UUID        NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+//	    created_at   TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+//	    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+//	    ip_address   TEXT,
+//	    user_agent   TEXT,
+//	    impersonator_id BIGINT CONSTRAINT sessions_impersonator_fk REFERENCES users ON DELETE CASCADE
 //	);
 //
 // If number of records in `expiry` column is large, can consider indexing it using BRIN index
@@ -22,13 +32,13 @@ package postgresstore
 import (
 	"context"
 	"database/sql"
-	"encoding/hex"
 	"log"
 	"time"
 
-	"github.com/cespare/xxhash/v2"
+	"github.com/lib/pq"
 
 	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
 )
 
 // PostgresStore represents the session store.
@@ -53,29 +63,74 @@ func (p *PostgresStore) Commit(token string, b []byte, expiry time.Time) (err er
 // If the session token is not found or is expired, the returned exists flag will
 // be set to false.
 func (p *PostgresStore) FindCtx(ctx context.Context, token string) (b []byte, exists bool, err error) {
-	hash, err := sum(token)
+	b, _, exists, err = p.FindUserCtx(ctx, token)
+	return b, exists, err
+}
+
+// FindUserCtx is FindCtx that also returns the user a session belongs to.
+// Session's `last_seen_at` is refreshed at most once every
+// sessionstore.LastSeenInterval so that reads do not turn into a write on
+// every request.
+func (p *PostgresStore) FindUserCtx(ctx context.Context, token string) (b []byte, userID uint64, exists bool, err error) {
+	hash, err := sessionstore.Sum(token)
 	if err != nil {
-		return nil, false, err
+		return nil, 0, false, err
 	}
 
+	var lastSeenAt time.Time
 	row := p.db.QueryRowContext(ctx, `
-		SELECT data FROM sessions 
+		SELECT data, COALESCE(user_id, 0), last_seen_at FROM sessions 
             WHERE token = $1 
               AND current_timestamp < expiry 
             ORDER BY expiry desc`, hash)
-	err = row.Scan(&b)
+	err = row.Scan(&b, &userID, &lastSeenAt)
 	if err == sql.ErrNoRows {
-		return nil, false, nil
+		return nil, 0, false, nil
 	} else if err != nil {
-		return nil, false, err
+		return nil, 0, false, err
 	}
-	return b, true, nil
+
+	if time.Since(lastSeenAt) > sessionstore.LastSeenInterval {
+		_, err = p.db.ExecContext(ctx, `
+			UPDATE sessions SET last_seen_at = current_timestamp WHERE token = $1`, hash)
+		if err != nil {
+			return nil, 0, false, err
+		}
+	}
+
+	return b, userID, true, nil
+}
+
+// TakeCtx finds and deletes a session in one statement, so that only one of
+// many concurrent callers finds it.
+func (p *PostgresStore) TakeCtx(ctx context.Context, token string) (b []byte, userID uint64, exists bool, err error) {
+	hash, err := sessionstore.Sum(token)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	var expiry time.Time
+	err = p.db.QueryRowContext(ctx, `
+		DELETE FROM sessions WHERE token = $1
+		RETURNING data, COALESCE(user_id, 0), expiry`, hash).Scan(&b, &userID, &expiry)
+	if err == sql.ErrNoRows {
+		return nil, 0, false, nil
+	} else if err != nil {
+		return nil, 0, false, err
+	}
+
+	if !time.Now().Before(expiry) {
+		return nil, 0, false, nil
+	}
+
+	return b, userID, true, nil
 }
 
 // CommitCtx adds a session token and data to the PostgresStore instance with the
 // given expiry time. If the session token already exists, then the data and expiry
-// time are updated. Hashed token is stored into database. User ID is retrieved from request
-// context since modifying method signature will no longer implements scs's Store interface.
+// time are updated. Hashed token is stored into database. User ID, impersonating
+// admin, IP address and user agent are retrieved from request context since
+// modifying method signature will no longer implements scs's Store interface.
 func (p *PostgresStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
 	var userID any
 	userID, ok := ctx.Value(middleware.KeyID).(uint64)
@@ -83,29 +138,64 @@ func (p *PostgresStore) CommitCtx(ctx context.Context, token string, b []byte, e
 		userID = nil
 	}
 
-	hash, err := sum(token)
+	var impersonatorID any
+	impersonatorID, ok = middleware.ImpersonatorID(ctx)
+	if !ok {
+		impersonatorID = nil
+	}
+
+	hash, err := sessionstore.Sum(token)
 	if err != nil {
 		return err
 	}
+	expiry = sessionstore.Expiry(ctx, expiry)
 
 	_, err = p.db.ExecContext(ctx, `
-		INSERT INTO sessions (token, user_id, data, expiry) 
-		VALUES ($1, $2, $3, $4) 
+		INSERT INTO sessions (token, user_id, data, expiry, ip_address, user_agent, impersonator_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) 
 		ON CONFLICT (token) 
 			DO UPDATE 
-			SET data = EXCLUDED.data, 
-				expiry = EXCLUDED.expiry
-				`, hash, userID, b, expiry)
+			SET user_id = EXCLUDED.user_id,
+				data = EXCLUDED.data,
+				expiry = EXCLUDED.expiry,
+				last_seen_at = current_timestamp,
+				ip_address = COALESCE(EXCLUDED.ip_address, sessions.ip_address),
+				user_agent = COALESCE(EXCLUDED.user_agent, sessions.user_agent),
+				impersonator_id = EXCLUDED.impersonator_id
+				`, hash, userID, b, expiry, contextString(ctx, middleware.KeyIPAddress), contextString(ctx, middleware.KeyUserAgent), impersonatorID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// ExtendCtx moves expiry of sessions forward in a single statement.
+func (p *PostgresStore) ExtendCtx(ctx context.Context, expiries map[string]time.Time) error {
+	hashes := make([]string, 0, len(expiries))
+	millis := make([]int64, 0, len(expiries))
+	for token, expiry := range expiries {
+		hash, err := sessionstore.Sum(token)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+		millis = append(millis, expiry.UnixMilli())
+	}
+
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET expiry = to_timestamp(v.expiry / 1000.0)
+		FROM unnest($1::text[], $2::bigint[]) AS v(token, expiry)
+		WHERE sessions.token = v.token
+		  AND current_timestamp < sessions.expiry
+		  AND sessions.expiry < to_timestamp(v.expiry / 1000.0)`, pq.Array(hashes), pq.Array(millis))
+	return err
+}
+
 // DeleteCtx removes a session token and corresponding data from the PostgresStore
 // instance.
 func (p *PostgresStore) DeleteCtx(ctx context.Context, token string) error {
-	hash, err := sum(token)
+	hash, err := sessionstore.Sum(token)
 	if err != nil {
 		return err
 	}
@@ -147,6 +237,76 @@ func (p *PostgresStore) AllCtx(ctx context.Context) (map[string][]byte, error) {
 	return sessions, nil
 }
 
+// UserSessionsCtx lists sessions of a user that have not expired, most
+// recently used first. The session with currentToken is marked as current by
+// comparing hashes, so tokens never leave the database.
+func (p *PostgresStore) UserSessionsCtx(ctx context.Context, userID uint64, currentToken string) ([]sessionstore.Session, error) {
+	hash, err := sessionstore.Sum(currentToken)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT public_id, token = $2, impersonator_id IS NOT NULL, data, created_at, last_seen_at, expiry,
+		       COALESCE(ip_address, ''), COALESCE(user_agent, '')
+		FROM sessions
+		WHERE user_id = $1
+		  AND current_timestamp < expiry
+		ORDER BY last_seen_at DESC
+		`, userID, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []sessionstore.Session
+	for rows.Next() {
+		var s sessionstore.Session
+		err = rows.Scan(&s.PublicID, &s.Current, &s.Impersonated, &s.Data, &s.CreatedAt, &s.LastSeenAt, &s.Expiry, &s.IPAddress, &s.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// DeleteUserSessionCtx deletes one session of a user by its public ID.
+func (p *PostgresStore) DeleteUserSessionCtx(ctx context.Context, userID uint64, publicID string) (bool, error) {
+	res, err := p.db.ExecContext(ctx, `
+		DELETE FROM sessions WHERE public_id::text = $1 AND user_id = $2
+		`, publicID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteUserCtx deletes every session of a user except the one with
+// exceptToken.
+func (p *PostgresStore) DeleteUserCtx(ctx context.Context, userID uint64, exceptToken string) (int64, error) {
+	hash, err := sessionstore.Sum(exceptToken)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := p.db.ExecContext(ctx, `
+		DELETE FROM sessions WHERE user_id = $1 AND token <> $2
+		`, userID, hash)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
 // New returns a new PostgresStore instance, with a background cleanup goroutine
 // that runs every 5 minutes to remove expired session data.
 func New(db *sql.DB) *PostgresStore {
@@ -203,15 +363,12 @@ func (p *PostgresStore) deleteExpired() error {
 	return err
 }
 
-func sum(token string) (string, error) {
-	h := xxhash.New()
-	_, err := h.Write([]byte(token))
-	if err != nil {
-
-		return "", err
+// contextString returns a string saved in context, or nil so that NULL is
+// stored when it is missing.
+func contextString(ctx context.Context, key any) any {
+	v, ok := ctx.Value(key).(string)
+	if !ok || v == "" {
+		return nil
 	}
-	sum := h.Sum(nil)
-	str := hex.EncodeToString(sum)
-
-	return str, err
+	return v
 }
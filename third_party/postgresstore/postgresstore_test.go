@@ -16,6 +16,8 @@ import (
 
 	"github.com/gmhafiz/go8/config"
 	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
+	"github.com/gmhafiz/go8/third_party/sessionstore/sessionstoretest"
 )
 
 func TestMain(m *testing.M) {
@@ -71,10 +73,16 @@ CREATE TABLE IF NOT EXISTS users
 	_, err = db.ExecContext(ctx, `
 CREATE TABLE IF NOT EXISTS sessions
 (
-    token   TEXT PRIMARY KEY,
-    user_id BIGINT      NOT NULL CONSTRAINT session_user_fk REFERENCES users ON DELETE CASCADE ,
-    data    BYTEA       NOT NULL,
-    expiry  TIMESTAMPTZ NOT NULL
+    token        TEXT PRIMARY KEY,
+    user_id      BIGINT      NOT NULL CONSTRAINT session_user_fk REFERENCES users ON DELETE CASCADE ,
+    data         BYTEA       NOT NULL,
+    expiry       TIMESTAMPTZ NOT NULL,
+    public_id    UUID        NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    ip_address   TEXT,
+    user_agent   TEXT,
+    impersonator_id BIGINT CONSTRAINT sessions_impersonator_fk REFERENCES users ON DELETE CASCADE
 );`)
 	if err != nil {
 		log.Println(err)
@@ -113,7 +121,7 @@ func TestFind(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	hash, err := sum("session_token")
+	hash, err := sessionstore.Sum("session_token")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -202,7 +210,7 @@ func TestSaveNew(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	hash, err := sum("session_token")
+	hash, err := sessionstore.Sum("session_token")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -251,7 +259,7 @@ func TestSaveUpdated(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	hash, err := sum("session_token")
+	hash, err := sessionstore.Sum("session_token")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -327,7 +335,7 @@ func TestDelete(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	hash, err := sum("session_token")
+	hash, err := sessionstore.Sum("session_token")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -382,7 +390,7 @@ func TestCleanup(t *testing.T) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, middleware.KeyID, uint64(1))
 
-	hash, err := sum("session_token")
+	hash, err := sessionstore.Sum("session_token")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -433,3 +441,28 @@ func TestStopNilCleanup(t *testing.T) {
 	// A send to a nil channel will block forever
 	p.StopCleanup()
 }
+
+func TestConformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dsn := os.Getenv("SCS_POSTGRES_TEST_DSN")
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionstoretest.Run(t, NewWithCleanupInterval(db, 0), func(t *testing.T) uint64 {
+		var userID uint64
+		err := db.QueryRow(`INSERT INTO users (email) VALUES (gen_random_uuid()) RETURNING id`).Scan(&userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return userID
+	})
+}
@@ -0,0 +1,491 @@
+// Package redisstore is a Redis implementation of sessionstore.Store. It
+// behaves like postgresstore, except that sessions expire on their own
+// through Redis TTL, so no cleanup goroutine is needed.
+//
+// Each session is a hash stored under its hashed token:
+//
+//	session:token:<hash>  data, user_id, impersonator_id, public_id, created_at,
+//	                      last_seen_at, expiry, ip_address, user_agent
+//
+// and the hashes of a user's sessions are kept in a set, so that a user can be
+// logged out everywhere without scanning every session:
+//
+//	session:user:<user id>  {<hash>, ...}
+//
+// A set outlives its sessions by at most the longest session lifetime. Members
+// whose session has expired are removed when the set is next read. Session and
+// set live in different cluster slots, so they are updated one after another
+// rather than in a single transaction. A session is only trusted to belong to
+// a user by its own `user_id` field.
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
+)
+
+const (
+	tokenPrefix = "session:token:"
+	userPrefix  = "session:user:"
+)
+
+// touch updates `last_seen_at` of a session only if it still exists, so that
+// a session expiring in the meantime is not brought back without a TTL.
+var touch = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+return redis.call('HSET', KEYS[1], 'last_seen_at', ARGV[1])
+`)
+
+// deleteOwned deletes a session only if it belongs to the user in ARGV[1] and,
+// when ARGV[2] is not empty, has that public ID.
+var deleteOwned = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'user_id') ~= ARGV[1] then
+	return 0
+end
+if ARGV[2] ~= '' and redis.call('HGET', KEYS[1], 'public_id') ~= ARGV[2] then
+	return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+
+// extend moves expiry of a live session forward to ARGV[1], and returns the
+// user it belongs to so that the user's set can be kept alive as long.
+var extend = redis.NewScript(`
+local expiry = tonumber(redis.call('HGET', KEYS[1], 'expiry'))
+if not expiry or expiry >= tonumber(ARGV[1]) then
+	return false
+end
+redis.call('HSET', KEYS[1], 'expiry', ARGV[1])
+redis.call('PEXPIREAT', KEYS[1], ARGV[1])
+return redis.call('HGET', KEYS[1], 'user_id')
+`)
+
+// RedisStore represents the session store.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+// New returns a new RedisStore instance. Works with both single node and
+// cluster clients.
+func New(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Delete(token string) (err error) {
+	panic("missing context arg")
+}
+
+func (s *RedisStore) Find(token string) (b []byte, found bool, err error) {
+	panic("missing context arg")
+}
+
+func (s *RedisStore) Commit(token string, b []byte, expiry time.Time) (err error) {
+	panic("missing context arg")
+}
+
+// FindCtx returns the data for a given session token. If the session token is
+// not found or is expired, the returned exists flag will be set to false.
+func (s *RedisStore) FindCtx(ctx context.Context, token string) (b []byte, exists bool, err error) {
+	b, _, exists, err = s.FindUserCtx(ctx, token)
+	return b, exists, err
+}
+
+// FindUserCtx is FindCtx that also returns the user a session belongs to.
+// Session's `last_seen_at` is refreshed at most once every
+// sessionstore.LastSeenInterval.
+func (s *RedisStore) FindUserCtx(ctx context.Context, token string) (b []byte, userID uint64, exists bool, err error) {
+	hash, err := sessionstore.Sum(token)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	values, err := s.client.HMGet(ctx, tokenKey(hash), "data", "user_id", "last_seen_at").Result()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	data, ok := values[0].(string)
+	if !ok {
+		return nil, 0, false, nil
+	}
+	userID = parseUint(values[1])
+
+	if time.Since(parseTime(values[2])) > sessionstore.LastSeenInterval {
+		err = touch.Run(ctx, s.client, []string{tokenKey(hash)}, time.Now().UnixMilli()).Err()
+		if err != nil {
+			return nil, 0, false, err
+		}
+	}
+
+	return []byte(data), userID, true, nil
+}
+
+// CommitCtx adds a session token and data with the given expiry time. If the
+// session token already exists, then the data and expiry time are updated.
+// Like postgresstore, user ID, impersonating admin, IP address and user agent
+// are retrieved from request context.
+func (s *RedisStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	hash, err := sessionstore.Sum(token)
+	if err != nil {
+		return err
+	}
+	key := tokenKey(hash)
+	expiry = sessionstore.Expiry(ctx, expiry)
+
+	previousUserID, err := s.client.HGet(ctx, key, "user_id").Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	userID, _ := ctx.Value(middleware.KeyID).(uint64)
+	impersonatorID, _ := middleware.ImpersonatorID(ctx)
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	fields := []any{
+		"data", b,
+		"expiry", expiry.UnixMilli(),
+		"last_seen_at", now,
+	}
+	if ip, ok := ctx.Value(middleware.KeyIPAddress).(string); ok && ip != "" {
+		fields = append(fields, "ip_address", ip)
+	}
+	if ua, ok := ctx.Value(middleware.KeyUserAgent).(string); ok && ua != "" {
+		fields = append(fields, "user_agent", ua)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, fields...)
+		pipe.HSetNX(ctx, key, "public_id", uuid.NewString())
+		pipe.HSetNX(ctx, key, "created_at", now)
+		if userID != 0 {
+			pipe.HSet(ctx, key, "user_id", userID)
+		} else {
+			pipe.HDel(ctx, key, "user_id")
+		}
+		if impersonatorID != 0 {
+			pipe.HSet(ctx, key, "impersonator_id", impersonatorID)
+		} else {
+			pipe.HDel(ctx, key, "impersonator_id")
+		}
+		pipe.PExpireAt(ctx, key, expiry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previousUserID != 0 && previousUserID != userID {
+			pipe.SRem(ctx, userKey(previousUserID), hash)
+		}
+		// Set lives for as long as its longest session. NX gives a new set
+		// an expiry, and GT only ever extends it.
+		if ttl := time.Until(expiry); userID != 0 && ttl > 0 {
+			pipe.SAdd(ctx, userKey(userID), hash)
+			pipe.ExpireNX(ctx, userKey(userID), ttl)
+			pipe.ExpireGT(ctx, userKey(userID), ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// ExtendCtx moves expiry of sessions forward, in one pipeline for the
+// sessions and another for the sets of their users.
+func (s *RedisStore) ExtendCtx(ctx context.Context, expiries map[string]time.Time) error {
+	cmds := make(map[*redis.Cmd]time.Time, len(expiries))
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for token, expiry := range expiries {
+			hash, err := sessionstore.Sum(token)
+			if err != nil {
+				return err
+			}
+			cmds[extend.Eval(ctx, pipe, []string{tokenKey(hash)}, expiry.UnixMilli())] = expiry
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for cmd, expiry := range cmds {
+			userID := parseUint(cmd.Val())
+			if ttl := time.Until(expiry); userID != 0 && ttl > 0 {
+				pipe.ExpireGT(ctx, userKey(userID), ttl)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// DeleteCtx removes a session token and corresponding data.
+func (s *RedisStore) DeleteCtx(ctx context.Context, token string) error {
+	_, _, _, err := s.TakeCtx(ctx, token)
+	return err
+}
+
+// TakeCtx finds and deletes a session in one transaction, so that only one of
+// many concurrent callers finds it.
+func (s *RedisStore) TakeCtx(ctx context.Context, token string) (b []byte, userID uint64, exists bool, err error) {
+	hash, err := sessionstore.Sum(token)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	key := tokenKey(hash)
+
+	var (
+		get *redis.SliceCmd
+		del *redis.IntCmd
+	)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.HMGet(ctx, key, "data", "user_id")
+		del = pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	data, ok := get.Val()[0].(string)
+	if !ok || del.Val() != 1 {
+		return nil, 0, false, nil
+	}
+	userID = parseUint(get.Val()[1])
+
+	if userID != 0 {
+		err = s.client.SRem(ctx, userKey(userID), hash).Err()
+		if err != nil {
+			return nil, 0, false, err
+		}
+	}
+
+	return []byte(data), userID, true, nil
+}
+
+// AllCtx returns a map containing the hashed token and data for all active
+// sessions. Every master is scanned when client is a cluster client.
+func (s *RedisStore) AllCtx(ctx context.Context) (map[string][]byte, error) {
+	var (
+		mu       sync.Mutex
+		sessions = make(map[string][]byte)
+	)
+
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		iter := client.Scan(ctx, 0, tokenPrefix+"*", 100).Iterator()
+		for iter.Next(ctx) {
+			data, err := client.HGet(ctx, iter.Val(), "data").Bytes()
+			if errors.Is(err, redis.Nil) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			mu.Lock()
+			sessions[strings.TrimPrefix(iter.Val(), tokenPrefix)] = data
+			mu.Unlock()
+		}
+		return iter.Err()
+	}
+
+	var err error
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scan(ctx, node)
+		})
+	} else {
+		err = scan(ctx, s.client)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// UserSessionsCtx lists sessions of a user that have not expired, most
+// recently used first. The session with currentToken is marked as current.
+func (s *RedisStore) UserSessionsCtx(ctx context.Context, userID uint64, currentToken string) ([]sessionstore.Session, error) {
+	currentHash, err := sessionstore.Sum(currentToken)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, cmds, err := s.userSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []sessionstore.Session
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		sessions = append(sessions, sessionstore.Session{
+			PublicID:     fields["public_id"],
+			Current:      hashes[i] == currentHash,
+			Impersonated: fields["impersonator_id"] != "",
+			Data:         []byte(fields["data"]),
+			IPAddress:    fields["ip_address"],
+			UserAgent:    fields["user_agent"],
+			CreatedAt:    parseTime(fields["created_at"]),
+			LastSeenAt:   parseTime(fields["last_seen_at"]),
+			Expiry:       parseTime(fields["expiry"]),
+		})
+	}
+
+	slices.SortFunc(sessions, func(a, b sessionstore.Session) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+// DeleteUserSessionCtx deletes one session of a user by its public ID.
+func (s *RedisStore) DeleteUserSessionCtx(ctx context.Context, userID uint64, publicID string) (bool, error) {
+	if publicID == "" {
+		return false, nil
+	}
+
+	deleted, err := s.deleteUser(ctx, userID, publicID, "")
+	return deleted > 0, err
+}
+
+// DeleteUserCtx deletes every session of a user except the one with
+// exceptToken.
+func (s *RedisStore) DeleteUserCtx(ctx context.Context, userID uint64, exceptToken string) (int64, error) {
+	exceptHash, err := sessionstore.Sum(exceptToken)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.deleteUser(ctx, userID, "", exceptHash)
+}
+
+func (s *RedisStore) deleteUser(ctx context.Context, userID uint64, publicID, exceptHash string) (int64, error) {
+	hashes, err := s.client.SMembers(ctx, userKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+	hashes = slices.DeleteFunc(hashes, func(hash string) bool {
+		return hash == exceptHash
+	})
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+
+	cmds := make([]*redis.Cmd, len(hashes))
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		// EVALSHA cannot fall back to EVAL inside a pipeline.
+		for i, hash := range hashes {
+			cmds[i] = deleteOwned.Eval(ctx, pipe, []string{tokenKey(hash)}, userID, publicID)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		deleted int64
+		removed []any
+	)
+	for i, cmd := range cmds {
+		if n, _ := cmd.Int64(); n == 1 {
+			deleted++
+			removed = append(removed, hashes[i])
+		}
+	}
+
+	if len(removed) > 0 {
+		err = s.client.SRem(ctx, userKey(userID), removed...).Err()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// userSessions reads every session in a user's set, and removes members whose
+// session has expired or now belongs to someone else.
+func (s *RedisStore) userSessions(ctx context.Context, userID uint64) ([]string, []*redis.MapStringStringCmd, error) {
+	hashes, err := s.client.SMembers(ctx, userKey(userID)).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(hashes) == 0 {
+		return nil, nil, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(hashes))
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, hash := range hashes {
+			cmds[i] = pipe.HGetAll(ctx, tokenKey(hash))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		liveHashes []string
+		liveCmds   []*redis.MapStringStringCmd
+		stale      []any
+	)
+	for i, cmd := range cmds {
+		if cmd.Val()["user_id"] != strconv.FormatUint(userID, 10) {
+			stale = append(stale, hashes[i])
+			continue
+		}
+		liveHashes = append(liveHashes, hashes[i])
+		liveCmds = append(liveCmds, cmd)
+	}
+
+	if len(stale) > 0 {
+		err = s.client.SRem(ctx, userKey(userID), stale...).Err()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return liveHashes, liveCmds, nil
+}
+
+func tokenKey(hash string) string {
+	return tokenPrefix + hash
+}
+
+func userKey(userID uint64) string {
+	return userPrefix + strconv.FormatUint(userID, 10)
+}
+
+// parseUint reads a number returned by HMGET or HGETALL, or 0 if it is
+// missing.
+func parseUint(v any) uint64 {
+	str, _ := v.(string)
+	n, _ := strconv.ParseUint(str, 10, 64)
+	return n
+}
+
+// parseTime reads a Unix time in milliseconds, or zero time if it is
+// missing.
+func parseTime(v any) time.Time {
+	str, _ := v.(string)
+	ms, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
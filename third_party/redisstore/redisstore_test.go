@@ -0,0 +1,58 @@
+package redisstore
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gmhafiz/go8/third_party/sessionstore/sessionstoretest"
+)
+
+func TestRedisStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "valkey/valkey",
+		Tag:        "7.2",
+	}, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		t.Fatalf("Could not start resource: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := pool.Purge(resource); err != nil {
+			log.Printf("Could not purge resource: %s", err)
+		}
+	})
+	_ = resource.Expire(120)
+
+	client := redis.NewClient(&redis.Options{Addr: resource.GetHostPort("6379/tcp")})
+	t.Cleanup(func() { _ = client.Close() })
+
+	pool.MaxWait = 60 * time.Second
+	if err = pool.Retry(func() error {
+		return client.Ping(context.Background()).Err()
+	}); err != nil {
+		t.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	var lastUserID atomic.Uint64
+	sessionstoretest.Run(t, New(client), func(t *testing.T) uint64 {
+		return lastUserID.Add(1)
+	})
+}
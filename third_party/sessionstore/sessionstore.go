@@ -0,0 +1,100 @@
+// Package sessionstore is the contract shared by session stores of this
+// project. Besides being an scs.CtxStore, a store remembers which user each
+// session belongs to, along with where it is used from, so that users can
+// list their devices and be logged out everywhere.
+//
+// Tokens are hashed with Sum before they are stored. A store never keeps a
+// plain token.
+package sessionstore
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/gmhafiz/scs/v2"
+)
+
+// Store is implemented by postgresstore.PostgresStore and
+// redisstore.RedisStore. User ID, impersonating admin, IP address and user
+// agent of a session are read from request context in CommitCtx, under
+// middleware.KeyID, middleware.KeyImpersonatorID, middleware.KeyIPAddress and
+// middleware.KeyUserAgent. Expiry given to CommitCtx is replaced by one set
+// with WithExpiry.
+type Store interface {
+	scs.CtxStore
+	scs.IterableCtxStore
+
+	// FindUserCtx is FindCtx that also returns the user a session belongs
+	// to, or 0 if nobody is logged in.
+	FindUserCtx(ctx context.Context, token string) (b []byte, userID uint64, found bool, err error)
+
+	// TakeCtx finds and deletes a session at once, so that only one of many
+	// concurrent callers finds it.
+	TakeCtx(ctx context.Context, token string) (b []byte, userID uint64, found bool, err error)
+
+	// UserSessionsCtx lists sessions of a user that have not expired, most
+	// recently used first. The one with currentToken is marked as current.
+	UserSessionsCtx(ctx context.Context, userID uint64, currentToken string) ([]Session, error)
+
+	// DeleteUserSessionCtx deletes one session of a user by its public ID,
+	// and reports whether it existed.
+	DeleteUserSessionCtx(ctx context.Context, userID uint64, publicID string) (bool, error)
+
+	// DeleteUserCtx deletes every session of a user except the one with
+	// exceptToken, which may be empty, and returns how many were deleted.
+	DeleteUserCtx(ctx context.Context, userID uint64, exceptToken string) (int64, error)
+
+	// ExtendCtx moves expiry of many sessions, keyed by token, forward at
+	// once. A session that does not exist, has already expired or expires
+	// later is left alone.
+	ExtendCtx(ctx context.Context, expiries map[string]time.Time) error
+}
+
+// Session is what a store knows about a session. PublicID identifies it
+// without exposing its token.
+type Session struct {
+	PublicID     string
+	Current      bool
+	Impersonated bool
+	Data         []byte
+	IPAddress    string
+	UserAgent    string
+	CreatedAt    time.Time
+	LastSeenAt   time.Time
+	Expiry       time.Time
+}
+
+// LastSeenInterval is how stale a session's last seen time may get before a
+// store updates it, so that reads do not turn into a write on every request.
+const LastSeenInterval = time.Minute
+
+type expiryKey struct{}
+
+// WithExpiry makes CommitCtx store a session with expiry rather than the one
+// scs derives from SessionManager.Lifetime, so that sessions can have
+// lifetimes of their own. Only the context given to a commit should carry
+// it, so that other tokens committed while handling a request keep theirs.
+func WithExpiry(ctx context.Context, expiry time.Time) context.Context {
+	return context.WithValue(ctx, expiryKey{}, expiry)
+}
+
+// Expiry returns expiry set with WithExpiry, or fallback.
+func Expiry(ctx context.Context, fallback time.Time) time.Time {
+	if expiry, ok := ctx.Value(expiryKey{}).(time.Time); ok {
+		return expiry
+	}
+	return fallback
+}
+
+// Sum returns the hash a token is stored under.
+func Sum(token string) (string, error) {
+	h := xxhash.New()
+	_, err := h.Write([]byte(token))
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,458 @@
+// Package sessionstoretest is the conformance test suite every
+// sessionstore.Store must pass, so that stores can be swapped without
+// changing how sessions behave.
+package sessionstoretest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gmhafiz/go8/internal/middleware"
+	"github.com/gmhafiz/go8/third_party/sessionstore"
+)
+
+// Run runs the suite against store. newUser returns the ID of a user that
+// has no sessions yet, creating one in the database if the store needs it.
+func Run(t *testing.T, store sessionstore.Store, newUser func(t *testing.T) uint64) {
+	t.Run("FindMissing", func(t *testing.T) {
+		_, found, err := store.FindCtx(context.Background(), "missing_session_token")
+		if err != nil {
+			t.Fatalf("got %v: expected %v", err, nil)
+		}
+		if found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+	})
+
+	t.Run("CommitAndFind", func(t *testing.T) {
+		userID := newUser(t)
+		ctx := userContext(userID)
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", time.Minute)
+
+		b, found, err := store.FindCtx(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found {
+			t.Fatalf("got %v: expected %v", found, true)
+		}
+		if !bytes.Equal(b, []byte("encoded_data")) {
+			t.Fatalf("got %s: expected %s", b, "encoded_data")
+		}
+
+		_, gotUserID, found, err := store.FindUserCtx(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found || gotUserID != userID {
+			t.Fatalf("got %d, %v: expected %d, %v", gotUserID, found, userID, true)
+		}
+	})
+
+	t.Run("CommitUpdates", func(t *testing.T) {
+		userID := newUser(t)
+		ctx := userContext(userID)
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", time.Minute)
+		before := userSessions(t, store, userID, token)
+
+		commit(t, store, ctx, token, "new_encoded_data", time.Minute)
+
+		b, found, err := store.FindCtx(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found || !bytes.Equal(b, []byte("new_encoded_data")) {
+			t.Fatalf("got %s, %v: expected %s, %v", b, found, "new_encoded_data", true)
+		}
+
+		after := userSessions(t, store, userID, token)
+		if len(before) != 1 || len(after) != 1 {
+			t.Fatalf("got %d and %d sessions: expected 1", len(before), len(after))
+		}
+		if before[0].PublicID != after[0].PublicID {
+			t.Fatalf("public id changed from %s to %s", before[0].PublicID, after[0].PublicID)
+		}
+	})
+
+	t.Run("CommitChangesUser", func(t *testing.T) {
+		oldUserID := newUser(t)
+		newUserID := newUser(t)
+		token := tokenFor(t)
+
+		commit(t, store, userContext(oldUserID), token, "encoded_data", time.Minute)
+		commit(t, store, userContext(newUserID), token, "encoded_data", time.Minute)
+
+		if got := userSessions(t, store, oldUserID, ""); len(got) != 0 {
+			t.Fatalf("got %d sessions of previous user: expected 0", len(got))
+		}
+		if got := userSessions(t, store, newUserID, ""); len(got) != 1 {
+			t.Fatalf("got %d sessions of new user: expected 1", len(got))
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		userID := newUser(t)
+		ctx := userContext(userID)
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", 100*time.Millisecond)
+
+		_, found, _ := store.FindCtx(ctx, token)
+		if !found {
+			t.Fatalf("got %v: expected %v", found, true)
+		}
+
+		time.Sleep(200 * time.Millisecond)
+
+		_, found, _ = store.FindCtx(ctx, token)
+		if found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+		if got := userSessions(t, store, userID, ""); len(got) != 0 {
+			t.Fatalf("got %d sessions: expected 0", len(got))
+		}
+	})
+
+	t.Run("CommitWithExpiry", func(t *testing.T) {
+		ctx := sessionstore.WithExpiry(userContext(newUser(t)), time.Now().Add(time.Minute))
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", 100*time.Millisecond)
+
+		time.Sleep(200 * time.Millisecond)
+
+		_, found, _ := store.FindCtx(ctx, token)
+		if !found {
+			t.Fatalf("got %v: expected %v", found, true)
+		}
+	})
+
+	t.Run("Extend", func(t *testing.T) {
+		userID := newUser(t)
+		ctx := userContext(userID)
+		short, long, expired := tokenFor(t), tokenFor(t), tokenFor(t)
+
+		commit(t, store, ctx, short, "encoded_data", 200*time.Millisecond)
+		commit(t, store, ctx, long, "encoded_data", time.Minute)
+		commit(t, store, ctx, expired, "encoded_data", 50*time.Millisecond)
+
+		time.Sleep(100 * time.Millisecond)
+
+		err := store.ExtendCtx(ctx, map[string]time.Time{
+			short:                   time.Now().Add(time.Minute),
+			long:                    time.Now().Add(time.Second),
+			expired:                 time.Now().Add(time.Minute),
+			"missing_session_token": time.Now().Add(time.Minute),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(200 * time.Millisecond)
+
+		if _, found, _ := store.FindCtx(ctx, short); !found {
+			t.Fatalf("got %v: expected %v", found, true)
+		}
+		if _, found, _ := store.FindCtx(ctx, expired); found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+		if _, found, _ := store.FindCtx(ctx, "missing_session_token"); found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+
+		sessions := userSessions(t, store, userID, long)
+		if len(sessions) != 2 {
+			t.Fatalf("got %d sessions: expected 2", len(sessions))
+		}
+		for _, session := range sessions {
+			if time.Until(session.Expiry) < 30*time.Second {
+				t.Fatalf("got expiry in %s: expected about a minute", time.Until(session.Expiry))
+			}
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		userID := newUser(t)
+		ctx := userContext(userID)
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", time.Minute)
+
+		if err := store.DeleteCtx(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+
+		_, found, _ := store.FindCtx(ctx, token)
+		if found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+		if got := userSessions(t, store, userID, ""); len(got) != 0 {
+			t.Fatalf("got %d sessions: expected 0", len(got))
+		}
+	})
+
+	t.Run("Take", func(t *testing.T) {
+		userID := newUser(t)
+		ctx := userContext(userID)
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", time.Minute)
+
+		b, gotUserID, found, err := store.TakeCtx(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found || gotUserID != userID || !bytes.Equal(b, []byte("encoded_data")) {
+			t.Fatalf("got %s, %d, %v: expected %s, %d, %v", b, gotUserID, found, "encoded_data", userID, true)
+		}
+
+		_, _, found, err = store.TakeCtx(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+		if got := userSessions(t, store, userID, ""); len(got) != 0 {
+			t.Fatalf("got %d sessions: expected 0", len(got))
+		}
+	})
+
+	t.Run("TakeConcurrently", func(t *testing.T) {
+		userID := newUser(t)
+		ctx := userContext(userID)
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", time.Minute)
+
+		var (
+			wg    sync.WaitGroup
+			taken atomic.Int32
+		)
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, found, err := store.TakeCtx(ctx, token)
+				if err != nil {
+					t.Error(err)
+				}
+				if found {
+					taken.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if taken.Load() != 1 {
+			t.Fatalf("taken %d times: expected 1", taken.Load())
+		}
+	})
+
+	t.Run("TakeExpired", func(t *testing.T) {
+		ctx := userContext(newUser(t))
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", 100*time.Millisecond)
+		time.Sleep(200 * time.Millisecond)
+
+		_, _, found, err := store.TakeCtx(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+	})
+
+	t.Run("UserSessions", func(t *testing.T) {
+		userID := newUser(t)
+		adminID := newUser(t)
+		otherID := newUser(t)
+		first, second, other := tokenFor(t), tokenFor(t), tokenFor(t)
+
+		ctx := userContext(userID)
+		ctx = context.WithValue(ctx, middleware.KeyIPAddress, "192.0.2.1")
+		ctx = context.WithValue(ctx, middleware.KeyUserAgent, "sessionstoretest")
+		commit(t, store, ctx, first, "first", time.Minute)
+
+		impersonated := context.WithValue(userContext(userID), middleware.KeyImpersonatorID, adminID)
+		commit(t, store, impersonated, second, "second", time.Hour)
+
+		commit(t, store, userContext(otherID), other, "other", time.Minute)
+
+		sessions := userSessions(t, store, userID, first)
+		if len(sessions) != 2 {
+			t.Fatalf("got %d sessions: expected 2", len(sessions))
+		}
+
+		byData := make(map[string]sessionstore.Session)
+		for _, s := range sessions {
+			if s.PublicID == "" {
+				t.Fatalf("session %s has no public id", s.Data)
+			}
+			byData[string(s.Data)] = s
+		}
+
+		got := byData["first"]
+		if !got.Current || got.Impersonated {
+			t.Fatalf("got current %v, impersonated %v: expected true, false", got.Current, got.Impersonated)
+		}
+		if got.IPAddress != "192.0.2.1" || got.UserAgent != "sessionstoretest" {
+			t.Fatalf("got %q, %q: expected %q, %q", got.IPAddress, got.UserAgent, "192.0.2.1", "sessionstoretest")
+		}
+		if got.CreatedAt.IsZero() || got.LastSeenAt.IsZero() {
+			t.Fatalf("created at %v and last seen at %v must be set", got.CreatedAt, got.LastSeenAt)
+		}
+		if d := time.Until(got.Expiry); d <= 0 || d > time.Minute {
+			t.Fatalf("got expiry in %v: expected within a minute", d)
+		}
+
+		got = byData["second"]
+		if got.Current || !got.Impersonated {
+			t.Fatalf("got current %v, impersonated %v: expected false, true", got.Current, got.Impersonated)
+		}
+		if got.IPAddress != "" || got.UserAgent != "" {
+			t.Fatalf("got %q, %q: expected empty", got.IPAddress, got.UserAgent)
+		}
+	})
+
+	t.Run("DeleteUserSession", func(t *testing.T) {
+		userID := newUser(t)
+		otherID := newUser(t)
+		ctx := userContext(userID)
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", time.Minute)
+		publicID := userSessions(t, store, userID, token)[0].PublicID
+
+		deleted, err := store.DeleteUserSessionCtx(ctx, otherID, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted {
+			t.Fatal("deleted a session of another user")
+		}
+
+		deleted, err = store.DeleteUserSessionCtx(ctx, userID, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !deleted {
+			t.Fatalf("got %v: expected %v", deleted, true)
+		}
+
+		_, found, _ := store.FindCtx(ctx, token)
+		if found {
+			t.Fatalf("got %v: expected %v", found, false)
+		}
+
+		deleted, err = store.DeleteUserSessionCtx(ctx, userID, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted {
+			t.Fatalf("got %v: expected %v", deleted, false)
+		}
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		userID := newUser(t)
+		otherID := newUser(t)
+		ctx := userContext(userID)
+		kept, first, second, other := tokenFor(t), tokenFor(t), tokenFor(t), tokenFor(t)
+
+		for _, token := range []string{kept, first, second} {
+			commit(t, store, ctx, token, "encoded_data", time.Minute)
+		}
+		commit(t, store, userContext(otherID), other, "encoded_data", time.Minute)
+
+		deleted, err := store.DeleteUserCtx(ctx, userID, kept)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted != 2 {
+			t.Fatalf("got %d: expected %d", deleted, 2)
+		}
+
+		for token, want := range map[string]bool{kept: true, first: false, second: false, other: true} {
+			_, found, _ := store.FindCtx(ctx, token)
+			if found != want {
+				t.Fatalf("got %v: expected %v", found, want)
+			}
+		}
+
+		deleted, err = store.DeleteUserCtx(ctx, userID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted != 1 {
+			t.Fatalf("got %d: expected %d", deleted, 1)
+		}
+		if got := userSessions(t, store, userID, ""); len(got) != 0 {
+			t.Fatalf("got %d sessions: expected 0", len(got))
+		}
+	})
+
+	t.Run("All", func(t *testing.T) {
+		ctx := userContext(newUser(t))
+		token := tokenFor(t)
+
+		commit(t, store, ctx, token, "encoded_data", time.Minute)
+
+		all, err := store.AllCtx(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hash, err := sessionstore.Sum(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(all[hash], []byte("encoded_data")) {
+			t.Fatalf("got %s: expected %s", all[hash], "encoded_data")
+		}
+		if _, ok := all[token]; ok {
+			t.Fatal("plain token is stored")
+		}
+	})
+}
+
+func userContext(userID uint64) context.Context {
+	return context.WithValue(context.Background(), middleware.KeyID, userID)
+}
+
+// tokenFor returns a new random token.
+func tokenFor(t *testing.T) string {
+	t.Helper()
+
+	return rand.Text()
+}
+
+func commit(t *testing.T, store sessionstore.Store, ctx context.Context, token, data string, ttl time.Duration) {
+	t.Helper()
+
+	if err := store.CommitCtx(ctx, token, []byte(data), time.Now().Add(ttl)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func userSessions(t *testing.T, store sessionstore.Store, userID uint64, currentToken string) []sessionstore.Session {
+	t.Helper()
+
+	sessions, err := store.UserSessionsCtx(context.Background(), userID, currentToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sessions
+}